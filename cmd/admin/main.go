@@ -0,0 +1,144 @@
+// admin 运维命令行工具，用于重建评分聚合、二级索引，导入中文标题等离线任务
+//
+// 用法: go run ./cmd/admin <命令> [参数...]
+package main
+
+import (
+	"context"
+	"fmt"
+	"gohbase/config"
+	"gohbase/utils"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// command 子命令
+type command struct {
+	usage string
+	run   func(ctx context.Context, args []string) error
+}
+
+// commands 所有可用的子命令
+var commands = map[string]command{
+	"rebuild-stats": {
+		usage: "rebuild-stats [movieId...]  根据原始评分重建评分聚合（stats 列族）及按天统计（timeline 列族），不指定电影时重建全部",
+		run:   rebuildStats,
+	},
+	"rebuild-index": {
+		usage: "rebuild-index [movieId...]  根据电影数据重建类型、标签、年份、评分区间和标题的二级索引（movieindex 表），不指定电影时重建全部",
+		run:   rebuildIndex,
+	},
+	"rebuild-user-ratings": {
+		usage: "rebuild-user-ratings        根据电影的原始评分重建按用户存储的评分（userratings 表）",
+		run:   rebuildUserRatings,
+	},
+	"import-aliases": {
+		usage: "import-aliases <file.csv>   从 movieId,中文标题 格式的CSV导入中文标题（alias:zh 列）",
+		run:   importAliases,
+	},
+}
+
+func main() {
+	logrus.SetFormatter(&logrus.TextFormatter{
+		FullTimestamp: true,
+	})
+
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "未知命令: %s\n", os.Args[1])
+		printUsage()
+		os.Exit(2)
+	}
+
+	cfg := config.GetConfig()
+	if err := utils.InitStore(cfg); err != nil {
+		logrus.Fatalf("初始化数据存储失败: %v", err)
+	}
+
+	start := time.Now()
+	if err := cmd.run(context.Background(), os.Args[2:]); err != nil {
+		logrus.Fatalf("执行 %s 失败: %v", os.Args[1], err)
+	}
+	logrus.Infof("%s 执行完成，耗时 %s", os.Args[1], time.Since(start).Round(time.Millisecond))
+}
+
+// printUsage 打印用法说明
+func printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "用法: go run ./cmd/admin <命令> [参数...]")
+	fmt.Fprintln(os.Stderr, "命令:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s\n", commands[name].usage)
+	}
+}
+
+// rebuildStats 重建评分聚合
+func rebuildStats(ctx context.Context, args []string) error {
+	rebuilt, err := utils.RebuildRatingAggregates(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("已重建 %d 部电影的评分聚合", rebuilt)
+	return nil
+}
+
+// rebuildIndex 重建二级索引
+func rebuildIndex(ctx context.Context, args []string) error {
+	rebuilt, err := utils.RebuildMovieIndex(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("已重建 %d 部电影的二级索引", rebuilt)
+	return nil
+}
+
+// rebuildUserRatings 重建用户评分表
+func rebuildUserRatings(ctx context.Context, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("rebuild-user-ratings 不接受参数")
+	}
+
+	users, ratings, err := utils.RebuildUserRatings(ctx)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("已重建 %d 个用户的 %d 条评分", users, ratings)
+	return nil
+}
+
+// importAliases 导入中文标题
+func importAliases(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("需要指定CSV文件")
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	imported, err := utils.ImportAliases(ctx, file)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("已导入 %d 个中文标题，服务的搜索索引将在下次后台重建时生效", imported)
+	return nil
+}
@@ -2,12 +2,24 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config 应用配置
 type Config struct {
-	HBase  HBaseConfig
-	Server ServerConfig
+	HBase     HBaseConfig
+	Server    ServerConfig
+	Store     StoreConfig
+	Log       LogConfig
+	Tracing   TracingConfig
+	Cache     CacheConfig
+	Search    SearchConfig
+	Charts    ChartsConfig
+	Similar   SimilarConfig
+	Recommend RecommendConfig
+	Tags      TagsConfig
 }
 
 // HBaseConfig HBase数据库配置
@@ -19,9 +31,80 @@ type HBaseConfig struct {
 	ThriftPort string
 }
 
+// 数据存储后端
+const (
+	StoreBackendHBase  = "hbase"
+	StoreBackendMemory = "memory"
+)
+
+// StoreConfig 数据存储配置
+type StoreConfig struct {
+	Backend      string // 存储后端：hbase 或 memory
+	FixturesPath string // memory 后端启动时加载的数据文件
+}
+
+// LogConfig 日志配置
+type LogConfig struct {
+	BufferSize int // 内存中保留的最近日志条数，供 /api/system/logs 查询
+}
+
+// CacheConfig 内存缓存配置
+type CacheConfig struct {
+	DefaultTTL      time.Duration // 默认过期时间
+	CleanupInterval time.Duration // 过期项清理间隔
+	MaxEntries      int           // 最大缓存项数量，0 表示不限制
+	MaxBytes        int64         // 最大占用字节数（估算值），0 表示不限制
+	// PrefixTTLs 按键前缀设置的过期时间，格式为 "前缀=时长,前缀=时长"
+	PrefixTTLs  map[string]time.Duration
+	StaleWindow time.Duration // 过期后仍返回旧值并在后台刷新的时长，0 表示不启用
+}
+
+// SearchConfig 搜索配置
+type SearchConfig struct {
+	RefreshInterval time.Duration // 倒排索引后台重建间隔，0 表示只在启动时构建
+}
+
+// ChartsConfig 加权评分与排行榜配置
+type ChartsConfig struct {
+	MinVotes        int64         // 加权评分公式中的最少评分人数 m，评分人数远少于 m 的电影会向全库平均分收缩
+	RefreshInterval time.Duration // 排行后台重算间隔，0 表示只在启动时计算
+}
+
+// SimilarConfig 相似电影配置
+type SimilarConfig struct {
+	Neighbors       int           // 每部电影预先计算并保留的相似电影数量
+	RatingBoost     float64       // 按平均分接近程度加权的强度，0 表示只看类型和标签
+	RefreshInterval time.Duration // 后台重算间隔，0 表示只在启动时计算
+}
+
+// RecommendConfig 协同过滤推荐配置
+type RecommendConfig struct {
+	Neighbors     int           // 每部电影保留的相似电影数量
+	ModelPath     string        // 模型文件路径，重启时优先加载，为空时不持久化
+	TrainInterval time.Duration // 后台重新训练间隔，0 表示不重新训练
+}
+
+// TagsConfig 用户标签配置
+type TagsConfig struct {
+	MaxLength     int      // 规范化后标签的最大长度（字符数）
+	Blocklist     []string // 屏蔽词
+	BlocklistFile string   // 屏蔽词文件，每行一个，# 开头的行为注释，与 Blocklist 合并
+}
+
+// TracingConfig 链路追踪配置
+type TracingConfig struct {
+	Exporter    string  // 导出方式：none、stdout、file 或 otlp
+	Endpoint    string  // OTLP/HTTP 接收端地址，如 localhost:4318
+	Insecure    bool    // OTLP 是否使用明文HTTP
+	FilePath    string  // file 导出方式写入的文件
+	ServiceName string  // 上报的服务名
+	SampleRatio float64 // 采样比例，0~1
+}
+
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	Port string
+	Port       string
+	AdminToken string // 管理接口的访问令牌，为空时管理接口不可用
 }
 
 // GetConfig 获取配置
@@ -35,7 +118,54 @@ func GetConfig() *Config {
 			ThriftPort: getEnv("HBASE_THRIFTPORT", "9090"),
 		},
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "5000"),
+			Port:       getEnv("SERVER_PORT", "5000"),
+			AdminToken: getEnv("ADMIN_TOKEN", ""),
+		},
+		Store: StoreConfig{
+			Backend:      getEnv("STORE_BACKEND", StoreBackendHBase),
+			FixturesPath: getEnv("STORE_FIXTURES", "data/fixtures.json"),
+		},
+		Log: LogConfig{
+			BufferSize: getEnvInt("LOG_BUFFER_SIZE", 1000),
+		},
+		Tracing: TracingConfig{
+			Exporter:    getEnv("TRACING_EXPORTER", "none"),
+			Endpoint:    getEnv("TRACING_OTLP_ENDPOINT", "localhost:4318"),
+			Insecure:    getEnvBool("TRACING_OTLP_INSECURE", true),
+			FilePath:    getEnv("TRACING_FILE", "traces.json"),
+			ServiceName: getEnv("TRACING_SERVICE_NAME", "teddyscore"),
+			SampleRatio: getEnvFloat("TRACING_SAMPLE_RATIO", 1.0),
+		},
+		Cache: CacheConfig{
+			DefaultTTL:      getEnvDuration("CACHE_DEFAULT_TTL", 5*time.Minute),
+			CleanupInterval: getEnvDuration("CACHE_CLEANUP_INTERVAL", 10*time.Minute),
+			MaxEntries:      getEnvInt("CACHE_MAX_ENTRIES", 10000),
+			MaxBytes:        int64(getEnvInt("CACHE_MAX_BYTES", 64<<20)),
+			PrefixTTLs: getEnvDurationMap("CACHE_TTL_POLICIES",
+				"movie_detail=1h,search=2m,total_movies_count=24h"),
+			StaleWindow: getEnvDuration("CACHE_STALE_WINDOW", 5*time.Minute),
+		},
+		Search: SearchConfig{
+			RefreshInterval: getEnvDuration("SEARCH_REFRESH_INTERVAL", 10*time.Minute),
+		},
+		Charts: ChartsConfig{
+			MinVotes:        int64(getEnvInt("CHARTS_MIN_VOTES", 10)),
+			RefreshInterval: getEnvDuration("CHARTS_REFRESH_INTERVAL", 10*time.Minute),
+		},
+		Similar: SimilarConfig{
+			Neighbors:       getEnvInt("SIMILAR_NEIGHBORS", 50),
+			RatingBoost:     getEnvFloat("SIMILAR_RATING_BOOST", 0.1),
+			RefreshInterval: getEnvDuration("SIMILAR_REFRESH_INTERVAL", 30*time.Minute),
+		},
+		Recommend: RecommendConfig{
+			Neighbors:     getEnvInt("RECOMMEND_NEIGHBORS", 50),
+			ModelPath:     getEnv("RECOMMEND_MODEL_PATH", "data/recommend_model.gob"),
+			TrainInterval: getEnvDuration("RECOMMEND_TRAIN_INTERVAL", time.Hour),
+		},
+		Tags: TagsConfig{
+			MaxLength:     getEnvInt("TAG_MAX_LENGTH", 50),
+			Blocklist:     getEnvList("TAG_BLOCKLIST"),
+			BlocklistFile: getEnv("TAG_BLOCKLIST_FILE", ""),
 		},
 	}
 }
@@ -48,3 +178,67 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// getEnvInt 获取整数类型的环境变量，不存在或格式错误时返回默认值
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvBool 获取布尔类型的环境变量，不存在或格式错误时返回默认值
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvFloat 获取浮点类型的环境变量，不存在或格式错误时返回默认值
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvDuration 获取时长类型的环境变量（如 5m、1h），不存在或格式错误时返回默认值
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvList 获取逗号分隔的列表类型的环境变量，空项被忽略，不存在时返回 nil
+func getEnvList(key string) []string {
+	var result []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// getEnvDurationMap 获取 "键=时长,键=时长" 格式的环境变量，不存在时解析默认值，格式错误的项被忽略
+func getEnvDurationMap(key, defaultValue string) map[string]time.Duration {
+	result := make(map[string]time.Duration)
+	for _, pair := range strings.Split(getEnv(key, defaultValue), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		duration, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+		result[strings.TrimSpace(name)] = duration
+	}
+	return result
+}
@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"gohbase/models"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// moderateTagRequest 标签审核请求体，Tag 为审核时看到的标签
+type moderateTagRequest struct {
+	Tag    string `json:"tag"`
+	Action string `json:"action"`
+}
+
+// RequireAdmin 校验管理接口的访问令牌（Authorization: Bearer <token>），token 为空时管理接口不可用
+func RequireAdmin(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"status":  "error",
+				"message": "管理接口未启用，需配置 ADMIN_TOKEN",
+			})
+			return
+		}
+
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"status":  "error",
+				"message": "管理员令牌无效",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetTagQueue 获取待审核标签队列，按提交时间升序
+func (ac *AdminController) GetTagQueue(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+
+	queue, err := models.GetTagQueue(c.Request.Context(), limit)
+	if err != nil {
+		logrus.Errorf("获取待审核标签失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "获取待审核标签失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"queue":  queue,
+	})
+}
+
+// ModerateTag 审核待审核队列中的标签：approve 通过，remove 删除
+func (ac *AdminController) ModerateTag(c *gin.Context) {
+	movieID, userID := c.Param("id"), c.Param("userId")
+	if movieID == "" || userID == "" {
+		respondBadRequest(c, "电影ID和用户ID不能为空")
+		return
+	}
+
+	var request moderateTagRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.Tag == "" {
+		respondBadRequest(c, "请求格式错误，需提供 tag 和 action")
+		return
+	}
+
+	if err := models.ModerateTag(c.Request.Context(), movieID, userID, request.Tag, request.Action); err != nil {
+		respondModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "标签已审核",
+	})
+}
+
+// respondModerationError 将标签审核错误转换为HTTP响应
+func respondModerationError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	message := "审核标签失败"
+
+	switch {
+	case errors.Is(err, models.ErrInvalidTagAction):
+		status, message = http.StatusBadRequest, err.Error()
+	case errors.Is(err, models.ErrPendingTagNotFound):
+		status, message = http.StatusNotFound, err.Error()
+	case errors.Is(err, models.ErrPendingTagChanged):
+		status, message = http.StatusConflict, err.Error()
+	default:
+		logrus.Errorf("审核标签失败: %v", err)
+	}
+
+	c.JSON(status, gin.H{
+		"status":  "error",
+		"message": message,
+	})
+}
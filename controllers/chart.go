@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"gohbase/models"
+	"gohbase/utils/charts"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// GetTopChart 获取加权评分排行榜，可按类型和年代筛选
+func (mc *MovieController) GetTopChart(c *gin.Context) {
+	genre := strings.TrimSpace(c.Query("genre"))
+
+	decade, err := parseDecade(c.Query("decade"))
+	if err != nil {
+		respondBadRequest(c, "decade 须为年代起始年份，如 1990 或 1990s")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	chart, err := models.GetTopChart(c.Request.Context(), genre, decade, limit)
+	if errors.Is(err, models.ErrChartsNotReady) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		logrus.Errorf("获取排行榜失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "获取排行榜失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"chart":  chart,
+	})
+}
+
+// GetTrendingChart 获取近期评分热度榜，按统计窗口内的评分速度相对基线期的增长排序
+func (mc *MovieController) GetTrendingChart(c *gin.Context) {
+	window, err := parseWindowDays(c.DefaultQuery("window", "7d"))
+	if err != nil {
+		respondBadRequest(c, fmt.Sprintf("window 须为 1d~%dd 的整天数，如 7d 或 168h", charts.MaxTrendingWindow))
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	chart, err := models.GetTrendingChart(c.Request.Context(), window, limit)
+	if errors.Is(err, models.ErrChartsNotReady) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		logrus.Errorf("获取热度榜失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "获取热度榜失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"chart":  chart,
+	})
+}
+
+// parseWindowDays 解析统计窗口，支持 7d 形式的天数或整天数的时长（如 168h）
+func parseWindowDays(value string) (int, error) {
+	var days int
+	if strings.HasSuffix(value, "d") {
+		n, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return 0, err
+		}
+		days = n
+	} else {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, err
+		}
+		if duration%(24*time.Hour) != 0 {
+			return 0, errors.New("window is not a whole number of days")
+		}
+		days = int(duration / (24 * time.Hour))
+	}
+
+	if days < 1 || days > charts.MaxTrendingWindow {
+		return 0, errors.New("window out of range")
+	}
+	return days, nil
+}
+
+// parseDecade 解析 1990 或 1990s 形式的年代，参数为空时返回0
+func parseDecade(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	decade, err := strconv.Atoi(strings.TrimSuffix(value, "s"))
+	if err != nil || decade <= 0 || decade%10 != 0 {
+		return 0, errors.New("invalid decade")
+	}
+	return decade, nil
+}
@@ -1,4 +1,20 @@
 package controllers
 
+import "github.com/gin-gonic/gin"
+
 // MovieController 电影控制器
 type MovieController struct{}
+
+// UserController 用户控制器
+type UserController struct{}
+
+// AdminController 管理接口控制器
+type AdminController struct{}
+
+// currentUserID 获取当前请求的用户ID，优先读取 X-User-ID 请求头，其次读取 user_id 查询参数
+func currentUserID(c *gin.Context) string {
+	if userID := c.GetHeader("X-User-ID"); userID != "" {
+		return userID
+	}
+	return c.Query("user_id")
+}
@@ -1,8 +1,8 @@
 package controllers
 
 import (
+	"errors"
 	"gohbase/models"
-	"gohbase/utils"
 	"net/http"
 	"strconv"
 
@@ -12,27 +12,29 @@ import (
 
 // GetMovies 获取电影列表
 func (mc *MovieController) GetMovies(c *gin.Context) {
-	// 获取分页参数
-	pageStr := c.DefaultQuery("page", "1")
-	perPageStr := c.DefaultQuery("per_page", "12")
-
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		page = 1
+	pageRequest, err := parsePageRequest(c, 12, 50)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
 	}
 
-	perPage, err := strconv.Atoi(perPageStr)
-	if err != nil || perPage < 1 {
-		perPage = 12
+	query, err := parseMovieQuery(c)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
 	}
 
-	// 限制每页最大数量为50
-	if perPage > 50 {
-		perPage = 50
+	// 获取电影列表，带筛选、排序或分面条件时在搜索索引上查询
+	var movies *models.MovieList
+	if query.IsEmpty() {
+		movies, err = models.GetMoviesList(c.Request.Context(), pageRequest)
+	} else {
+		movies, err = models.FilterMovies(c.Request.Context(), query, pageRequest)
+	}
+	if errors.Is(err, models.ErrInvalidCursor) {
+		respondBadRequest(c, err.Error())
+		return
 	}
-
-	// 获取电影列表
-	movies, err := models.GetMoviesList(page, perPage)
 	if err != nil {
 		logrus.Errorf("获取电影列表失败: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -57,7 +59,7 @@ func (mc *MovieController) GetMovie(c *gin.Context) {
 	}
 
 	// 获取电影详情
-	movie, err := models.GetMovieByID(movieID)
+	movie, err := models.GetMovieByID(c.Request.Context(), movieID)
 	if err != nil {
 		logrus.Errorf("获取电影详情失败: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -79,7 +81,53 @@ func (mc *MovieController) GetMovie(c *gin.Context) {
 	c.JSON(http.StatusOK, movie)
 }
 
-// GetMovieRatings 获取电影的所有评分
+// GetSimilarMovies 获取与指定电影类型和标签最相似的电影
+func (mc *MovieController) GetSimilarMovies(c *gin.Context) {
+	movieID := c.Param("id")
+	if movieID == "" {
+		respondBadRequest(c, "电影ID不能为空")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	if limit > 50 {
+		limit = 50
+	}
+
+	similar, err := models.GetSimilarMovies(c.Request.Context(), movieID, limit)
+	if errors.Is(err, models.ErrMovieNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, models.ErrSimilarNotReady) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		logrus.Errorf("获取相似电影失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "获取相似电影失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"similar": similar,
+	})
+}
+
+// GetMovieRatings 分页获取电影评分，支持按时间或评分排序及按评分时间筛选
 func (mc *MovieController) GetMovieRatings(c *gin.Context) {
 	// 获取电影ID
 	movieID := c.Param("id")
@@ -91,8 +139,23 @@ func (mc *MovieController) GetMovieRatings(c *gin.Context) {
 		return
 	}
 
+	query, err := parseRatingQuery(c)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	pageRequest, err := parsePageRequest(c, 20, 100)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
 	// 获取电影评分
-	ratings, err := utils.GetMovieRatings(c.Request.Context(), movieID)
+	ratings, err := models.ListMovieRatings(c.Request.Context(), movieID, query, pageRequest)
+	if errors.Is(err, models.ErrInvalidCursor) {
+		respondBadRequest(c, err.Error())
+		return
+	}
 	if err != nil {
 		logrus.Errorf("获取电影评分失败: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -102,25 +165,23 @@ func (mc *MovieController) GetMovieRatings(c *gin.Context) {
 		return
 	}
 
-	// 如果评分不存在
-	if ratings == nil {
-		c.JSON(http.StatusOK, gin.H{
-			"status":    "success",
-			"ratings":   []interface{}{},
-			"count":     0,
-			"avgRating": 0.0,
-			"minRating": 0.0,
-			"maxRating": 0.0,
-		})
-		return
+	response := gin.H{
+		"status":     "success",
+		"ratings":    ratings.Ratings,
+		"count":      ratings.Count,
+		"avgRating":  ratings.AvgRating,
+		"minRating":  ratings.MinRating,
+		"maxRating":  ratings.MaxRating,
+		"total":      ratings.Total,
+		"page":       ratings.Page,
+		"perPage":    ratings.PerPage,
+		"totalPages": ratings.TotalPages,
 	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"status":    "success",
-		"ratings":   ratings["ratings"],
-		"count":     ratings["count"],
-		"avgRating": ratings["avgRating"],
-		"minRating": ratings["minRating"],
-		"maxRating": ratings["maxRating"],
-	})
+	if ratings.NextCursor != "" {
+		response["next_cursor"] = ratings.NextCursor
+	}
+	if ratings.PrevCursor != "" {
+		response["prev_cursor"] = ratings.PrevCursor
+	}
+	c.JSON(http.StatusOK, response)
 }
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"fmt"
+	"gohbase/models"
+	"gohbase/utils/search"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseMovieQuery 解析电影列表的筛选与排序参数
+// genre 可重复出现或逗号分隔，tag 只能重复出现（标签本身可含逗号），genre_mode 为 any（默认）或 all；sort 为 title、year、avgRating 或 ratingCount，order 为 asc 或 desc
+func parseMovieQuery(c *gin.Context) (models.MovieQuery, error) {
+	var query models.MovieQuery
+	var err error
+
+	query.Genres = splitQueryList(c, "genre")
+	query.Tags = queryList(c, "tag")
+
+	switch mode := c.DefaultQuery("genre_mode", "any"); mode {
+	case "any":
+	case "all":
+		query.MatchAllGenres = true
+	default:
+		return query, fmt.Errorf("genre_mode 只能为 any 或 all")
+	}
+
+	if query.YearFrom, err = intParam(c, "year_from"); err != nil {
+		return query, err
+	}
+	if query.YearTo, err = intParam(c, "year_to"); err != nil {
+		return query, err
+	}
+	if query.MinRating, err = floatParam(c, "min_rating"); err != nil {
+		return query, err
+	}
+	if query.MaxRating, err = floatParam(c, "max_rating"); err != nil {
+		return query, err
+	}
+	if query.YearFrom > 0 && query.YearTo > 0 && query.YearFrom > query.YearTo {
+		return query, fmt.Errorf("year_from 不能大于 year_to")
+	}
+	if query.MinRating > 0 && query.MaxRating > 0 && query.MinRating > query.MaxRating {
+		return query, fmt.Errorf("min_rating 不能大于 max_rating")
+	}
+	minVotes, err := intParam(c, "min_votes")
+	if err != nil {
+		return query, err
+	}
+	query.MinVotes = int64(minVotes)
+
+	if query.Sort = c.Query("sort"); query.Sort != "" && !search.IsSortField(query.Sort) {
+		return query, fmt.Errorf("sort 只能为 title、year、avgRating 或 ratingCount")
+	}
+	switch order := c.Query("order"); order {
+	case "":
+		// 评分类字段默认从高到低，其余从低到高
+		query.Descending = query.Sort == search.SortAvgRating || query.Sort == search.SortRatingCount
+	case "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		return query, fmt.Errorf("order 只能为 asc 或 desc")
+	}
+
+	if query.Facets, err = parseFacets(c); err != nil {
+		return query, err
+	}
+
+	return query, nil
+}
+
+// parseFacets 解析 facets 参数（逗号分隔，可选 genre、decade、rating），重复的字段只统计一次
+func parseFacets(c *gin.Context) ([]string, error) {
+	var facets []string
+	seen := make(map[string]bool)
+	for _, field := range splitQueryList(c, "facets") {
+		if !search.IsFacetField(field) {
+			return nil, fmt.Errorf("facets 只能包含 genre、decade 或 rating")
+		}
+		if !seen[field] {
+			seen[field] = true
+			facets = append(facets, field)
+		}
+	}
+	return facets, nil
+}
+
+// queryList 读取可重复的查询参数，忽略空值
+func queryList(c *gin.Context, name string) []string {
+	var values []string
+	for _, value := range c.QueryArray(name) {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// splitQueryList 读取可重复的查询参数，每个值再按逗号拆分，只用于取值不含逗号的参数（类型、分面字段）
+func splitQueryList(c *gin.Context, name string) []string {
+	var values []string
+	for _, value := range queryList(c, name) {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
+}
+
+// intParam 读取非负整数参数，未提供时返回0
+func intParam(c *gin.Context, name string) (int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("%s 必须为非负整数", name)
+	}
+	return number, nil
+}
+
+// floatParam 读取 0~5 之间的评分参数，未提供时返回0
+func floatParam(c *gin.Context, name string) (float64, error) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, nil
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 || number > 5 {
+		return 0, fmt.Errorf("%s 必须为 0~5 之间的数字", name)
+	}
+	return number, nil
+}
@@ -24,7 +24,7 @@ func (mc *MovieController) GetRandomMovies(c *gin.Context) {
 	}
 
 	// 获取随机电影
-	movies, err := models.GetRandomMovies(count)
+	movies, err := models.GetRandomMovies(c.Request.Context(), count)
 	if err != nil {
 		logrus.Errorf("获取随机电影失败: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -59,7 +59,7 @@ func (mc *MovieController) RandomMoviesPost(c *gin.Context) {
 	}
 
 	// 获取随机电影
-	movies, err := models.GetRandomMovies(request.Count)
+	movies, err := models.GetRandomMovies(c.Request.Context(), request.Count)
 	if err != nil {
 		logrus.Errorf("获取随机电影失败: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
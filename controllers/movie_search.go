@@ -1,9 +1,9 @@
 package controllers
 
 import (
+	"errors"
 	"gohbase/models"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -21,27 +21,24 @@ func (mc *MovieController) SearchMovies(c *gin.Context) {
 		return
 	}
 
-	// 获取分页参数
-	pageStr := c.DefaultQuery("page", "1")
-	perPageStr := c.DefaultQuery("per_page", "12")
-
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		page = 1
-	}
-
-	perPage, err := strconv.Atoi(perPageStr)
-	if err != nil || perPage < 1 {
-		perPage = 12
+	pageRequest, err := parsePageRequest(c, 12, 50)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
 	}
 
-	// 限制每页最大数量为50
-	if perPage > 50 {
-		perPage = 50
+	facets, err := parseFacets(c)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
 	}
 
 	// 搜索电影
-	result, err := models.SearchMovies(query, page, perPage)
+	result, err := models.SearchMovies(c.Request.Context(), query, pageRequest, facets)
+	if errors.Is(err, models.ErrInvalidCursor) {
+		respondBadRequest(c, err.Error())
+		return
+	}
 	if err != nil {
 		logrus.Errorf("搜索电影失败: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
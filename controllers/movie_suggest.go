@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"errors"
+	"gohbase/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SuggestMovies 标题自动补全
+func (mc *MovieController) SuggestMovies(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		respondBadRequest(c, "补全关键词不能为空")
+		return
+	}
+
+	// 获取数量参数，最多20条
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	if limit > 20 {
+		limit = 20
+	}
+
+	suggestions, err := models.SuggestMovies(query, limit)
+	if errors.Is(err, models.ErrSearchIndexNotReady) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":      "success",
+		"suggestions": suggestions,
+	})
+}
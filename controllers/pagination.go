@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"gohbase/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parsePageRequest 解析分页参数：page 页码（默认1）、per_page 每页数量（默认 defaultPerPage，最多 maxPerPage）、
+// cursor 上次响应中的 next_cursor 或 prev_cursor，提供 cursor 时忽略 page
+func parsePageRequest(c *gin.Context, defaultPerPage, maxPerPage int) (models.PageRequest, error) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	perPage, err := strconv.Atoi(c.Query("per_page"))
+	if err != nil || perPage < 1 {
+		perPage = defaultPerPage
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+
+	request := models.PageRequest{Page: page, PerPage: perPage}
+	if token := c.Query("cursor"); token != "" {
+		cursor, err := models.DecodeCursor(token)
+		if err != nil {
+			return request, err
+		}
+		request.Cursor = cursor
+	}
+
+	return request, nil
+}
@@ -0,0 +1,232 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gohbase/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ratingRequest 评分请求体
+type ratingRequest struct {
+	Rating float64 `json:"rating"`
+}
+
+// CreateRating 新增当前用户对电影的评分
+func (mc *MovieController) CreateRating(c *gin.Context) {
+	mc.writeRating(c, http.StatusCreated, models.CreateRating)
+}
+
+// UpdateRating 修改当前用户对电影的评分
+func (mc *MovieController) UpdateRating(c *gin.Context) {
+	mc.writeRating(c, http.StatusOK, models.UpdateRating)
+}
+
+// DeleteRating 删除当前用户对电影的评分
+func (mc *MovieController) DeleteRating(c *gin.Context) {
+	movieID, userID, ok := ratingTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := models.DeleteRating(c.Request.Context(), movieID, userID); err != nil {
+		respondRatingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "评分已删除",
+	})
+}
+
+// GetRatingStats 获取电影的评分分布：半星直方图、中位数、标准差、百分位及首末评分时间
+func (mc *MovieController) GetRatingStats(c *gin.Context) {
+	movieID := c.Param("id")
+	if movieID == "" {
+		respondBadRequest(c, "电影ID不能为空")
+		return
+	}
+
+	distribution, err := models.GetRatingDistribution(c.Request.Context(), movieID)
+	if errors.Is(err, models.ErrMovieNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		logrus.Errorf("获取电影评分分布失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "获取电影评分分布失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"stats":  distribution,
+	})
+}
+
+// GetRatingTimeline 获取电影按天、周、月或年汇总的评分数量与平均分
+func (mc *MovieController) GetRatingTimeline(c *gin.Context) {
+	movieID := c.Param("id")
+	if movieID == "" {
+		respondBadRequest(c, "电影ID不能为空")
+		return
+	}
+
+	timeline, err := models.GetRatingTimeline(c.Request.Context(), movieID, c.DefaultQuery("bucket", models.TimelineBucketMonth))
+	if errors.Is(err, models.ErrInvalidTimelineBucket) {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	if errors.Is(err, models.ErrMovieNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		logrus.Errorf("获取电影评分时间线失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "获取电影评分时间线失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "success",
+		"timeline": timeline,
+	})
+}
+
+// writeRating 处理新增和修改评分的公共逻辑
+func (mc *MovieController) writeRating(c *gin.Context, successStatus int,
+	write func(ctx context.Context, movieID, userID string, rating float64) (*models.Rating, error)) {
+	movieID, userID, ok := ratingTarget(c)
+	if !ok {
+		return
+	}
+
+	var request ratingRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "请求格式错误",
+		})
+		return
+	}
+
+	rating, err := write(c.Request.Context(), movieID, userID, request.Rating)
+	if err != nil {
+		respondRatingError(c, err)
+		return
+	}
+
+	c.JSON(successStatus, gin.H{
+		"status": "success",
+		"rating": rating,
+	})
+}
+
+// ratingTarget 获取评分或标签操作的电影ID和当前用户ID，参数缺失时直接返回400
+func ratingTarget(c *gin.Context) (string, string, bool) {
+	movieID := c.Param("id")
+	if movieID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "电影ID不能为空",
+		})
+		return "", "", false
+	}
+
+	userID := currentUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "用户ID不能为空",
+		})
+		return "", "", false
+	}
+
+	return movieID, userID, true
+}
+
+// respondRatingError 将评分写入错误转换为HTTP响应
+func respondRatingError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	message := "评分操作失败"
+
+	switch {
+	case errors.Is(err, models.ErrInvalidRating):
+		status, message = http.StatusBadRequest, err.Error()
+	case errors.Is(err, models.ErrMovieNotFound), errors.Is(err, models.ErrRatingNotFound):
+		status, message = http.StatusNotFound, err.Error()
+	case errors.Is(err, models.ErrRatingExists), errors.Is(err, models.ErrRatingConflict):
+		status, message = http.StatusConflict, err.Error()
+	default:
+		logrus.Errorf("评分操作失败: %v", err)
+	}
+
+	c.JSON(status, gin.H{
+		"status":  "error",
+		"message": message,
+	})
+}
+
+// parseRatingQuery 解析评分列表参数：sort 为 timestamp（默认）或 rating，order 为 desc（默认）或 asc，
+// since / until 为评分时间范围（含边界），可以是Unix秒或RFC3339格式时间
+func parseRatingQuery(c *gin.Context) (models.RatingQuery, error) {
+	query := models.RatingQuery{
+		Sort:       c.DefaultQuery("sort", models.RatingSortTime),
+		Descending: true,
+	}
+	if query.Sort != models.RatingSortTime && query.Sort != models.RatingSortRating {
+		return query, fmt.Errorf("sort 只能为 timestamp 或 rating")
+	}
+
+	switch c.DefaultQuery("order", "desc") {
+	case "desc":
+	case "asc":
+		query.Descending = false
+	default:
+		return query, fmt.Errorf("order 只能为 asc 或 desc")
+	}
+
+	var err error
+	if query.Since, err = parseTimestampParam(c, "since"); err != nil {
+		return query, err
+	}
+	if query.Until, err = parseTimestampParam(c, "until"); err != nil {
+		return query, err
+	}
+	return query, nil
+}
+
+// parseTimestampParam 解析Unix秒或RFC3339格式的时间参数，参数为空时返回0
+func parseTimestampParam(c *gin.Context, name string) (int64, error) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil && seconds >= 0 {
+		return seconds, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, fmt.Errorf("%s 须为Unix秒或RFC3339格式时间", name)
+	}
+	return t.Unix(), nil
+}
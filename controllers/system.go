@@ -1,16 +1,18 @@
 package controllers
 
 import (
-	"fmt"
 	"gohbase/utils"
+	"gohbase/utils/logbuffer"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 )
 
 // GetSystemLogs 获取系统日志
+// 支持参数：lines 返回条数、level 最低级别、from/to 时间范围（RFC3339）、q 子串匹配、since 上次返回的日志ID
 func (mc *MovieController) GetSystemLogs(c *gin.Context) {
 	// 获取行数参数
 	linesStr := c.DefaultQuery("lines", "20")
@@ -24,40 +26,50 @@ func (mc *MovieController) GetSystemLogs(c *gin.Context) {
 		lines = 100
 	}
 
-	// 获取系统日志
-	logs := []map[string]interface{}{}
+	query := logbuffer.Query{
+		Contains: c.Query("q"),
+		Limit:    lines,
+	}
 
-	// 添加程序运行日志
-	startTime := time.Now().Add(-10 * time.Minute)
-	for i := 0; i < lines; i++ {
-		logTime := startTime.Add(time.Duration(i) * 10 * time.Second)
-		logs = append(logs, map[string]interface{}{
-			"timestamp": logTime.Format(time.RFC3339),
-			"level":     "INFO",
-			"message":   fmt.Sprintf("系统正常运行中，已处理 %d 个请求", i*10+5),
-		})
+	if levelStr := c.Query("level"); levelStr != "" {
+		level, err := logrus.ParseLevel(levelStr)
+		if err != nil {
+			respondBadRequest(c, "无效的日志级别")
+			return
+		}
+		query.MinLevel = level
+		query.HasLevel = true
 	}
 
-	// 添加数据库操作日志
-	logs = append(logs, map[string]interface{}{
-		"timestamp": time.Now().Add(-3 * time.Minute).Format(time.RFC3339),
-		"level":     "INFO",
-		"message":   "HBase 查询执行成功，扫描了 5000 行数据",
-	})
-	logs = append(logs, map[string]interface{}{
-		"timestamp": time.Now().Add(-2 * time.Minute).Format(time.RFC3339),
-		"level":     "INFO",
-		"message":   "完成电影数据缓存更新，共缓存 1500 条记录",
-	})
-	logs = append(logs, map[string]interface{}{
-		"timestamp": time.Now().Add(-1 * time.Minute).Format(time.RFC3339),
-		"level":     "INFO",
-		"message":   "用户评分数据同步完成，更新了 350 条评分",
-	})
+	if query.From, err = parseTimeParam(c, "from"); err != nil {
+		respondBadRequest(c, "from 参数须为RFC3339格式时间")
+		return
+	}
+	if query.To, err = parseTimeParam(c, "to"); err != nil {
+		respondBadRequest(c, "to 参数须为RFC3339格式时间")
+		return
+	}
+
+	if sinceStr := c.Query("since"); sinceStr != "" {
+		query.SinceID, err = strconv.ParseUint(sinceStr, 10, 64)
+		if err != nil {
+			respondBadRequest(c, "since 参数须为日志ID")
+			return
+		}
+	}
+
+	logs := utils.LogBuffer.Query(query)
+
+	// 下次轮询使用的游标：有新日志时为最后一条的ID，否则保持不变
+	cursor := query.SinceID
+	if len(logs) > 0 {
+		cursor = logs[len(logs)-1].ID
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"status": "success",
 		"logs":   logs,
+		"cursor": cursor,
 	})
 }
 
@@ -72,3 +84,20 @@ func (mc *MovieController) GetCacheStats(c *gin.Context) {
 		},
 	})
 }
+
+// parseTimeParam 解析RFC3339格式的时间查询参数，参数为空时返回零值
+func parseTimeParam(c *gin.Context, name string) (time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// respondBadRequest 返回400错误
+func respondBadRequest(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"status":  "error",
+		"message": message,
+	})
+}
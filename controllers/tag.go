@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"errors"
+	"gohbase/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// tagRequest 标签请求体
+type tagRequest struct {
+	Tag string `json:"tag"`
+}
+
+// AddTag 当前用户给电影打标签，标签立即生效并进入待审核队列
+func (mc *MovieController) AddTag(c *gin.Context) {
+	movieID, userID, ok := ratingTarget(c)
+	if !ok {
+		return
+	}
+
+	var request tagRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondBadRequest(c, "请求格式错误")
+		return
+	}
+
+	tag, err := models.AddTag(c.Request.Context(), movieID, userID, request.Tag)
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status": "success",
+		"tag":    tag,
+	})
+}
+
+// RemoveTag 删除当前用户给电影打的标签
+func (mc *MovieController) RemoveTag(c *gin.Context) {
+	movieID, userID, ok := ratingTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := models.RemoveTag(c.Request.Context(), movieID, userID, c.Param("tag")); err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "标签已删除",
+	})
+}
+
+// respondTagError 将标签写入错误转换为HTTP响应
+func respondTagError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	message := "标签操作失败"
+
+	switch {
+	case errors.Is(err, models.ErrTagEmpty), errors.Is(err, models.ErrTagTooLong):
+		status, message = http.StatusBadRequest, err.Error()
+	case errors.Is(err, models.ErrTagBlocked):
+		status, message = http.StatusUnprocessableEntity, err.Error()
+	case errors.Is(err, models.ErrMovieNotFound), errors.Is(err, models.ErrTagNotFound):
+		status, message = http.StatusNotFound, err.Error()
+	case errors.Is(err, models.ErrTagExists):
+		status, message = http.StatusConflict, err.Error()
+	default:
+		logrus.Errorf("标签操作失败: %v", err)
+	}
+
+	c.JSON(status, gin.H{
+		"status":  "error",
+		"message": message,
+	})
+}
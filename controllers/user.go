@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"errors"
+	"gohbase/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// GetUserProfile 获取用户资料：评分数量与平均分、分页的评分历史、最喜欢的类型及打过的标签
+func (uc *UserController) GetUserProfile(c *gin.Context) {
+	userID := c.Param("id")
+	if userID == "" {
+		respondBadRequest(c, "用户ID不能为空")
+		return
+	}
+
+	pageRequest, err := parsePageRequest(c, 20, 100)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	profile, err := models.GetUserProfile(c.Request.Context(), userID, pageRequest)
+	if errors.Is(err, models.ErrInvalidCursor) {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	if errors.Is(err, models.ErrUserNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		logrus.Errorf("获取用户资料失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "获取用户资料失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"profile": profile,
+	})
+}
+
+// GetRecommendations 为用户推荐电影，优先使用协同过滤模型，冷启动时退回加权评分排行
+func (uc *UserController) GetRecommendations(c *gin.Context) {
+	userID := c.Param("id")
+	if userID == "" {
+		respondBadRequest(c, "用户ID不能为空")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	if limit > 50 {
+		limit = 50
+	}
+
+	excludeRated, err := strconv.ParseBool(c.DefaultQuery("exclude_rated", "true"))
+	if err != nil {
+		respondBadRequest(c, "exclude_rated 须为 true 或 false")
+		return
+	}
+
+	recommendations, err := models.GetRecommendations(c.Request.Context(), userID, limit, excludeRated)
+	if errors.Is(err, models.ErrChartsNotReady) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		logrus.Errorf("获取用户推荐失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": "获取用户推荐失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":          "success",
+		"recommendations": recommendations,
+	})
+}
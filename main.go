@@ -6,6 +6,9 @@ import (
 	"gohbase/config"
 	"gohbase/routes"
 	"gohbase/utils"
+	"gohbase/utils/cache"
+	"gohbase/utils/metrics"
+	"gohbase/utils/tracing"
 	"net/http"
 	"os"
 	"os/signal"
@@ -27,18 +30,54 @@ func init() {
 func main() {
 	cfg := config.GetConfig()
 
+	// 安装日志缓冲区，供 /api/system/logs 查询最近的日志
+	utils.InitLogBuffer(cfg.Log.BufferSize)
+	logrus.AddHook(utils.LogBuffer)
+
 	logrus.Infof("配置信息: HBase主机=%s, ZooKeeper地址=%s, ZooKeeper端口=%s",
 		cfg.HBase.Host, cfg.HBase.ZkQuorum, cfg.HBase.ZkPort)
 
-	utils.InitCache(5*time.Minute, 10*time.Minute)
-	logrus.Info("缓存系统初始化成功")
+	shutdownTracing, err := tracing.Init(context.Background(), &cfg.Tracing)
+	if err != nil {
+		logrus.Fatalf("初始化链路追踪失败: %v", err)
+	}
+	logrus.Infof("链路追踪初始化成功 [导出方式: %s]", cfg.Tracing.Exporter)
+
+	utils.InitCache(cache.Options{
+		DefaultExpiration: cfg.Cache.DefaultTTL,
+		CleanupInterval:   cfg.Cache.CleanupInterval,
+		MaxEntries:        cfg.Cache.MaxEntries,
+		MaxBytes:          cfg.Cache.MaxBytes,
+		PrefixTTLs:        cfg.Cache.PrefixTTLs,
+		StaleWindow:       cfg.Cache.StaleWindow,
+	})
+	if err := metrics.RegisterCache(utils.Cache); err != nil {
+		logrus.Fatalf("注册缓存指标失败: %v", err)
+	}
+	logrus.Infof("缓存系统初始化成功 [最大条数: %d, 最大字节数: %d]", cfg.Cache.MaxEntries, cfg.Cache.MaxBytes)
 
-	err := utils.InitHBase(&cfg.HBase)
+	err = utils.InitStore(cfg)
 	if err != nil {
-		logrus.Fatalf("初始化HBase失败: %v", err)
+		logrus.Fatalf("初始化数据存储失败: %v", err)
+	}
+
+	if err := utils.InitTagPolicy(cfg.Tags.MaxLength, cfg.Tags.Blocklist, cfg.Tags.BlocklistFile); err != nil {
+		logrus.Fatalf("初始化标签规则失败: %v", err)
 	}
 
-	router := routes.SetupRouter()
+	if err := utils.InitSearchEngine(context.Background(), cfg.Search.RefreshInterval); err != nil {
+		logrus.Errorf("构建搜索索引失败，搜索将降级为全表扫描直到后台重建成功: %v", err)
+	}
+
+	if err := utils.InitCharts(context.Background(), cfg.Charts.MinVotes, cfg.Charts.RefreshInterval); err != nil {
+		logrus.Errorf("计算加权评分排行失败，排行榜在后台重算成功前不可用: %v", err)
+	}
+
+	utils.InitSimilar(cfg.Similar.Neighbors, cfg.Similar.RatingBoost, cfg.Similar.RefreshInterval)
+
+	utils.InitRecommender(cfg.Recommend.Neighbors, cfg.Recommend.ModelPath, cfg.Recommend.TrainInterval)
+
+	router := routes.SetupRouter(cfg.Server.AdminToken)
 
 	srv := &http.Server{
 		Addr:    fmt.Sprintf(":%s", cfg.Server.Port),
@@ -63,6 +102,11 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		logrus.Fatalf("服务器强制关闭: %v", err)
 	}
+	utils.StopEngines()
+
+	if err := shutdownTracing(ctx); err != nil {
+		logrus.Errorf("关闭链路追踪失败: %v", err)
+	}
 
 	logrus.Info("服务器已退出")
 }
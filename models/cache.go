@@ -0,0 +1,15 @@
+package models
+
+import (
+	"context"
+	"gohbase/utils"
+)
+
+// loadCached 读取缓存，未命中时由同一键的一个请求执行 load，其余并发请求等待共享结果
+// load 使用脱离取消信号的上下文，避免发起加载的请求提前断开导致所有等待方一起失败
+func loadCached(ctx context.Context, key string, load func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	loadCtx := context.WithoutCancel(ctx)
+	return utils.Cache.GetOrLoad(key, func() (interface{}, error) {
+		return load(loadCtx)
+	})
+}
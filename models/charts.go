@@ -0,0 +1,145 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gohbase/utils"
+	"gohbase/utils/charts"
+	"gohbase/utils/search"
+	"gohbase/utils/store"
+	"math"
+	"strings"
+	"time"
+)
+
+// ErrChartsNotReady 加权评分排行尚未计算完成
+var ErrChartsNotReady = errors.New("排行榜尚未计算完成，请稍后重试")
+
+// Chart 加权评分排行榜
+type Chart struct {
+	Genre      string    `json:"genre,omitempty"`
+	Decade     int       `json:"decade,omitempty"`
+	MinVotes   int64     `json:"minVotes"`   // 加权公式中的最少评分人数 m
+	GlobalMean float64   `json:"globalMean"` // 全库平均分 C
+	BuiltAt    time.Time `json:"builtAt"`
+	Movies     []Movie   `json:"movies"`
+}
+
+// GetTopChart 获取加权评分最高的电影（带缓存），排行由后台任务定期重算，缓存随每次重算失效
+func GetTopChart(ctx context.Context, genre string, decade, limit int) (*Chart, error) {
+	if utils.ChartEngine == nil {
+		return nil, ErrChartsNotReady
+	}
+	snapshot := utils.ChartEngine.Snapshot()
+	if snapshot == nil {
+		return nil, ErrChartsNotReady
+	}
+
+	cacheKey := fmt.Sprintf("chart_top:%d:%s:%d:%d", snapshot.BuiltAt.UnixNano(), strings.ToLower(genre), decade, limit)
+	cachedData, err := loadCached(ctx, cacheKey, func(ctx context.Context) (interface{}, error) {
+		entries := snapshot.Top(genre, decade, limit)
+		docs := make([]*search.Document, 0, len(entries))
+		for _, entry := range entries {
+			docs = append(docs, entry.Document)
+		}
+
+		movies, err := moviesFromDocuments(ctx, docs)
+		if err != nil {
+			return nil, err
+		}
+		for i, entry := range entries {
+			movies[i].WeightedRating = roundRating(entry.WeightedRating)
+		}
+
+		return &Chart{
+			Genre:      genre,
+			Decade:     decade,
+			MinVotes:   snapshot.MinVotes,
+			GlobalMean: roundRating(snapshot.Mean),
+			BuiltAt:    snapshot.BuiltAt,
+			Movies:     movies,
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cachedData.(*Chart), nil
+}
+
+// TrendingMovie 热度榜中的电影，RecentCount 为统计窗口内的评分数量，BaselineCount 为基线期的评分数量
+type TrendingMovie struct {
+	Movie
+	RecentCount   int64   `json:"recentCount"`
+	BaselineCount int64   `json:"baselineCount"`
+	TrendingScore float64 `json:"trendingScore"`
+}
+
+// TrendingChart 近期评分热度榜
+type TrendingChart struct {
+	WindowDays   int             `json:"windowDays"`
+	BaselineDays int             `json:"baselineDays"`
+	BuiltAt      time.Time       `json:"builtAt"`
+	Movies       []TrendingMovie `json:"movies"`
+}
+
+// GetTrendingChart 获取最近 windowDays 天评分速度相对基线期增长最多的电影（带缓存）
+// 基于排行快照中 timeline 列族的按天统计，随排行后台重算更新
+func GetTrendingChart(ctx context.Context, windowDays, limit int) (*TrendingChart, error) {
+	if utils.ChartEngine == nil {
+		return nil, ErrChartsNotReady
+	}
+	snapshot := utils.ChartEngine.Snapshot()
+	if snapshot == nil {
+		return nil, ErrChartsNotReady
+	}
+
+	cacheKey := fmt.Sprintf("chart_trending:%d:%d:%d", snapshot.BuiltAt.UnixNano(), windowDays, limit)
+	cachedData, err := loadCached(ctx, cacheKey, func(ctx context.Context) (interface{}, error) {
+		entries := snapshot.Trending(windowDays, limit)
+		docs := make([]*search.Document, 0, len(entries))
+		for _, entry := range entries {
+			docs = append(docs, entry.Document)
+		}
+
+		movies, err := moviesFromDocuments(ctx, docs)
+		if err != nil {
+			return nil, err
+		}
+		trending := make([]TrendingMovie, len(entries))
+		for i, entry := range entries {
+			trending[i] = TrendingMovie{
+				Movie:         movies[i],
+				RecentCount:   entry.Recent,
+				BaselineCount: entry.Baseline,
+				TrendingScore: roundRating(entry.Score),
+			}
+		}
+
+		return &TrendingChart{
+			WindowDays:   windowDays,
+			BaselineDays: charts.BaselineDays(windowDays),
+			BuiltAt:      snapshot.BuiltAt,
+			Movies:       trending,
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cachedData.(*TrendingChart), nil
+}
+
+// weightedRating 根据评分聚合计算加权评分，聚合为空时返回0
+func weightedRating(aggregate *store.RatingAggregate) float64 {
+	if aggregate == nil {
+		return 0
+	}
+	return roundRating(utils.WeightedRating(aggregate.Count, aggregate.Avg()))
+}
+
+// roundRating 评分保留三位小数
+func roundRating(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+)
+
+// ErrInvalidCursor 游标无法解析，或不属于当前列表
+var ErrInvalidCursor = errors.New("无效的翻页游标")
+
+// Cursor 翻页游标，以不透明字符串的形式返回给客户端
+// Key 为翻页起点记录的键（电影为 movieId，评分为 userId），Value 为该记录排序字段的值，
+// Backward 为 true 时取起点之前的一页；Scope 标识游标所属的列表及排序方式，防止在不同列表间混用
+type Cursor struct {
+	Scope    string `json:"s"`
+	Key      string `json:"k"`
+	Value    string `json:"v,omitempty"`
+	Backward bool   `json:"b,omitempty"`
+}
+
+// Encode 将游标编码为URL安全的字符串
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor 解析客户端传回的游标
+func DecodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Scope == "" || cursor.Key == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// PageRequest 分页参数，Cursor 不为空时按游标翻页并忽略 Page
+type PageRequest struct {
+	Page    int
+	PerPage int
+	Cursor  *Cursor
+}
+
+// checkScope 确认游标属于指定列表
+func (r PageRequest) checkScope(scope string) error {
+	if r.Cursor != nil && r.Cursor.Scope != scope {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+// window 在长度为 total 的有序列表中确定当前页的范围 [start, end)
+// compare(i) 返回第 i 项与游标记录的先后关系，小于0表示排在游标记录之前；游标记录本身已不在列表中时同样适用
+func (r PageRequest) window(total int, compare func(i int) int) (start, end int) {
+	switch {
+	case r.Cursor == nil:
+		start = min((r.Page-1)*r.PerPage, total)
+		end = min(start+r.PerPage, total)
+	case r.Cursor.Backward:
+		end = sort.Search(total, func(i int) bool { return compare(i) >= 0 })
+		start = max(end-r.PerPage, 0)
+	default:
+		start = sort.Search(total, func(i int) bool { return compare(i) > 0 })
+		end = min(start+r.PerPage, total)
+	}
+	return start, end
+}
+
+// pageCursors 为当前页 [start, end) 生成前后翻页游标，没有更多数据的方向返回空字符串
+// cursorAt(i) 返回指向第 i 项的游标
+func pageCursors(start, end, total int, cursorAt func(i int) Cursor) (next, prev string) {
+	if start >= end {
+		return "", ""
+	}
+	if end < total {
+		next = cursorAt(end - 1).Encode()
+	}
+	if start > 0 {
+		cursor := cursorAt(start)
+		cursor.Backward = true
+		prev = cursor.Encode()
+	}
+	return next, prev
+}
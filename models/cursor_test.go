@@ -0,0 +1,136 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []Cursor{
+		{Scope: "movies", Key: "1"},
+		{Scope: "ratings:rating:desc", Key: "42", Value: "4.5", Backward: true},
+		{Scope: "movies:title:asc", Key: "10", Value: "Toy Story (1995) & \"friends\"/玩具"},
+	}
+	for _, cursor := range tests {
+		token := cursor.Encode()
+		for _, r := range token {
+			if r == '+' || r == '/' || r == '=' {
+				t.Errorf("Encode(%+v) = %q is not URL safe", cursor, token)
+				break
+			}
+		}
+		decoded, err := DecodeCursor(token)
+		if err != nil {
+			t.Fatalf("DecodeCursor(%q) error: %v", token, err)
+		}
+		if !reflect.DeepEqual(*decoded, cursor) {
+			t.Errorf("DecodeCursor(Encode(%+v)) = %+v", cursor, *decoded)
+		}
+	}
+}
+
+func TestDecodeInvalidCursor(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"不是 base64", "!!!"},
+		{"不是 JSON", encode("movies")},
+		{"缺少作用域", encode(`{"k":"1"}`)},
+		{"缺少键", encode(`{"s":"movies"}`)},
+		{"空字符串", ""},
+	}
+	for _, tt := range tests {
+		if _, err := DecodeCursor(tt.token); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: DecodeCursor(%q) error = %v, want ErrInvalidCursor", tt.name, tt.token, err)
+		}
+	}
+}
+
+func TestPageRequestCheckScope(t *testing.T) {
+	tests := []struct {
+		cursor *Cursor
+		want   error
+	}{
+		{nil, nil},
+		{&Cursor{Scope: "movies", Key: "1"}, nil},
+		{&Cursor{Scope: "ratings:rating:asc", Key: "1"}, ErrInvalidCursor},
+	}
+	for _, tt := range tests {
+		if err := (PageRequest{Cursor: tt.cursor}).checkScope("movies"); !errors.Is(err, tt.want) {
+			t.Errorf("checkScope(%+v) = %v, want %v", tt.cursor, err, tt.want)
+		}
+	}
+}
+
+func TestPageRequestWindow(t *testing.T) {
+	// 列表为 10, 20, ..., 100，游标值与列表项比较
+	values := []int{10, 20, 30, 40, 50, 60, 70, 80, 90, 100}
+	tests := []struct {
+		name       string
+		request    PageRequest
+		anchor     int
+		start, end int
+	}{
+		{"第一页", PageRequest{Page: 1, PerPage: 3}, 0, 0, 3},
+		{"最后一页不满", PageRequest{Page: 4, PerPage: 3}, 0, 9, 10},
+		{"超出末页", PageRequest{Page: 5, PerPage: 3}, 0, 10, 10},
+		{"向后翻页", PageRequest{PerPage: 3, Cursor: &Cursor{}}, 30, 3, 6},
+		{"游标记录已删除", PageRequest{PerPage: 3, Cursor: &Cursor{}}, 35, 3, 6},
+		{"向后翻到末尾", PageRequest{PerPage: 3, Cursor: &Cursor{}}, 90, 9, 10},
+		{"向前翻页", PageRequest{PerPage: 3, Cursor: &Cursor{Backward: true}}, 70, 3, 6},
+		{"向前翻到开头", PageRequest{PerPage: 3, Cursor: &Cursor{Backward: true}}, 30, 0, 2},
+	}
+	for _, tt := range tests {
+		start, end := tt.request.window(len(values), func(i int) int {
+			return values[i] - tt.anchor
+		})
+		if start != tt.start || end != tt.end {
+			t.Errorf("%s: window = [%d, %d), want [%d, %d)", tt.name, start, end, tt.start, tt.end)
+		}
+	}
+}
+
+func TestPageCursors(t *testing.T) {
+	cursorAt := func(i int) Cursor {
+		return Cursor{Scope: "test", Key: string(rune('a' + i))}
+	}
+	tests := []struct {
+		name             string
+		start, end, tot  int
+		wantNext, wantPr string // 期望游标指向的键，空表示没有
+	}{
+		{"第一页", 0, 3, 10, "c", ""},
+		{"中间页", 3, 6, 10, "f", "d"},
+		{"最后一页", 9, 10, 10, "", "j"},
+		{"空页", 10, 10, 10, "", ""},
+	}
+	for _, tt := range tests {
+		next, prev := pageCursors(tt.start, tt.end, tt.tot, cursorAt)
+		if key := cursorKey(t, next, false); key != tt.wantNext {
+			t.Errorf("%s: next cursor key = %q, want %q", tt.name, key, tt.wantNext)
+		}
+		if key := cursorKey(t, prev, true); key != tt.wantPr {
+			t.Errorf("%s: prev cursor key = %q, want %q", tt.name, key, tt.wantPr)
+		}
+	}
+}
+
+// cursorKey 解析游标并返回其键，游标为空时返回空字符串，同时校验翻页方向
+func cursorKey(t *testing.T, token string, backward bool) string {
+	t.Helper()
+	if token == "" {
+		return ""
+	}
+	cursor, err := DecodeCursor(token)
+	if err != nil {
+		t.Fatalf("DecodeCursor(%q) error: %v", token, err)
+	}
+	if cursor.Backward != backward {
+		t.Errorf("cursor %+v Backward = %v, want %v", cursor, cursor.Backward, backward)
+	}
+	return cursor.Key
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"gohbase/utils"
+	"gohbase/utils/cache"
+	"gohbase/utils/memstore"
+
+	"github.com/sirupsen/logrus"
+)
+
+// testFixtures 测试数据：四部电影，1_9、2_8 为旧版评分行，5 为只剩统计列的残留行
+const testFixtures = `{
+  "moviedata": {
+    "1":   {"movie": {"title": "Toy Story (1995)", "genres": "Animation|Comedy"},
+            "rating": {"rating:1": "4.0", "timestamp:1": "100", "rating:2": "3.5", "timestamp:2": "200"}},
+    "1_9": {"rating": {"rating": "2.0", "timestamp": "50"}},
+    "10":  {"movie": {"title": "GoldenEye (1995)", "genres": "Action"},
+            "rating": {"rating:1": "3.0", "timestamp:1": "300"}},
+    "2":   {"movie": {"title": "Jumanji (1995)", "genres": "Adventure"}},
+    "2_8": {"rating": {"rating": "4.5", "timestamp": "60"}},
+    "3":   {"movie": {"title": "Grumpier Old Men (1995)", "genres": "Comedy|Romance"},
+            "rating": {"rating:3": "4", "timestamp:3": "400"}},
+    "5":   {"stats": {"min": "1.0"}}
+  }
+}`
+
+func TestMain(m *testing.M) {
+	logrus.SetLevel(logrus.FatalLevel)
+	os.Exit(m.Run())
+}
+
+// useTestStore 使用加载了测试数据的内存存储和新的缓存，并重建二级索引
+func useTestStore(t *testing.T) context.Context {
+	t.Helper()
+
+	s := memstore.New()
+	if err := s.Load(strings.NewReader(testFixtures)); err != nil {
+		t.Fatalf("加载测试数据失败: %v", err)
+	}
+	utils.Store = s
+	utils.InitCache(cache.Options{})
+
+	ctx := context.Background()
+	if _, err := utils.RebuildMovieIndex(ctx, nil); err != nil {
+		t.Fatalf("重建索引失败: %v", err)
+	}
+	return ctx
+}
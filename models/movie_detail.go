@@ -4,22 +4,30 @@ import (
 	"context"
 	"fmt"
 	"gohbase/utils"
-	"strconv"
-	"strings"
 )
 
 // GetMovieByID 根据ID获取电影（带缓存）
-func GetMovieByID(movieID string) (*MovieDetail, error) {
+func GetMovieByID(ctx context.Context, movieID string) (*MovieDetail, error) {
 	// 构建缓存键
 	cacheKey := fmt.Sprintf("movie_detail:%s", movieID)
 
-	// 检查缓存
-	if cachedData, found := utils.Cache.Get(cacheKey); found {
-		return cachedData.(*MovieDetail), nil
+	cachedData, err := loadCached(ctx, cacheKey, func(ctx context.Context) (interface{}, error) {
+		detail, err := loadMovieDetail(ctx, movieID)
+		if detail == nil {
+			// 电影不存在时返回无类型的 nil，不写入缓存
+			return nil, err
+		}
+		return detail, nil
+	})
+	if err != nil || cachedData == nil {
+		return nil, err
 	}
 
-	ctx := context.Background()
+	return cachedData.(*MovieDetail), nil
+}
 
+// loadMovieDetail 从存储读取并组装电影详情
+func loadMovieDetail(ctx context.Context, movieID string) (*MovieDetail, error) {
 	// 从HBase获取电影数据
 	data, err := utils.GetMovie(ctx, movieID)
 	if err != nil {
@@ -44,28 +52,23 @@ func GetMovieByID(movieID string) (*MovieDetail, error) {
 
 	if title, ok := movieData["title"].(string); ok {
 		movie.Title = title
-		// 尝试从标题中提取年份
-		if matches := strings.Split(title, " ("); len(matches) > 1 {
-			yearStr := strings.TrimSuffix(matches[len(matches)-1], ")")
-			if year, err := strconv.Atoi(yearStr); err == nil {
-				movie.Year = year
-			}
-		}
+		movie.Year = parseTitleYear(title)
+	}
+	if localizedTitle, ok := movieData["localizedTitle"].(string); ok {
+		movie.LocalizedTitle = localizedTitle
 	}
 
 	if genres, ok := movieData["genres"].([]string); ok {
 		movie.Genres = genres
 	}
 
-	// 使用 utils.GetMovieRatings 获取评分数据，与 /api/ratings/movie/:id 保持一致
-	ratingData, err := utils.GetMovieRatings(ctx, movieID)
-	if err == nil && ratingData != nil {
-		if avgRating, ok := ratingData["avgRating"].(float64); ok {
-			movie.AvgRating = avgRating
-		}
-	} else {
-		movie.AvgRating = 0.0
+	// 从评分聚合读取平均分和评分数量，只需一次Get
+	aggregate, err := utils.GetRatingAggregate(ctx, movieID)
+	if err != nil {
+		return nil, err
 	}
+	movie.AvgRating = aggregate.Avg()
+	movie.WeightedRating = weightedRating(aggregate)
 
 	// 设置链接
 	if links, ok := movieData["links"].(map[string]interface{}); ok {
@@ -94,22 +97,11 @@ func GetMovieByID(movieID string) (*MovieDetail, error) {
 
 	detail.Movie = movie
 
-	// 获取评分计数，但不填充评分数组
-	var ratingCount int
-	if ratingData != nil {
-		if count, ok := ratingData["count"].(int); ok {
-			ratingCount = count
-		}
-	}
-
 	// 构建统计数据
 	detail.Stats = map[string]float64{
-		"ratingCount": float64(ratingCount),
+		"ratingCount": float64(aggregate.Count),
 		"tagCount":    float64(len(movie.Tags)),
 	}
 
-	// 将结果存入缓存
-	utils.Cache.Set(cacheKey, detail)
-
 	return detail, nil
 }
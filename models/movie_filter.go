@@ -0,0 +1,265 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"gohbase/utils"
+	"gohbase/utils/search"
+	"gohbase/utils/store"
+	"strconv"
+)
+
+// maxIndexYear 只设置 year_from 时年份索引扫描的上限
+const maxIndexYear = 9999
+
+// MovieQuery 电影列表的筛选与排序条件
+type MovieQuery struct {
+	search.Filter
+	Sort       string // 排序字段，为空时按 movieId 排序
+	Descending bool
+	Facets     []string // 需要统计的分面字段
+}
+
+// IsEmpty 判断是否没有任何筛选、排序和分面条件
+func (q MovieQuery) IsEmpty() bool {
+	return q.Filter.IsEmpty() && q.Sort == "" && len(q.Facets) == 0
+}
+
+// FilterMovies 在搜索索引的电影快照上筛选、排序并分页，只为当前页读取电影的完整数据
+// 评分人数和平均分来自快照，与搜索索引同步刷新；快照尚未构建时改为通过二级索引筛选，没有可用索引的条件时遍历电影表
+func FilterMovies(ctx context.Context, query MovieQuery, request PageRequest) (*MovieList, error) {
+	scope := query.cursorScope()
+	if err := request.checkScope(scope); err != nil {
+		return nil, err
+	}
+	var anchor *search.Document
+	if request.Cursor != nil {
+		var err error
+		if anchor, err = cursorDocument(request.Cursor, query.Sort); err != nil {
+			return nil, err
+		}
+	}
+
+	var docs []*search.Document
+	if index := currentSearchIndex(); index != nil {
+		docs = index.Filter(query.Filter)
+	} else {
+		var err error
+		if docs, err = filterIndexedMovies(ctx, query.Filter); err != nil {
+			return nil, err
+		}
+	}
+	if query.Sort != "" {
+		search.SortDocuments(docs, query.Sort, query.Descending)
+	}
+
+	totalMatches := len(docs)
+	totalPages := (totalMatches + request.PerPage - 1) / request.PerPage
+
+	startIdx, endIdx := request.window(totalMatches, func(i int) int {
+		return search.CompareDocuments(docs[i], anchor, query.Sort, query.Descending)
+	})
+
+	movies, err := moviesFromDocuments(ctx, docs[startIdx:endIdx])
+	if err != nil {
+		return nil, err
+	}
+
+	list := &MovieList{
+		Movies:      movies,
+		TotalMovies: totalMatches,
+		PerPage:     request.PerPage,
+		TotalPages:  totalPages,
+		Facets:      search.CountFacets(docs, query.Facets),
+	}
+	if request.Cursor == nil {
+		list.Page = request.Page
+	}
+	list.NextCursor, list.PrevCursor = pageCursors(startIdx, endIdx, totalMatches, func(i int) Cursor {
+		return documentCursor(scope, docs[i], query.Sort)
+	})
+
+	return list, nil
+}
+
+// filterIndexedMovies 通过二级索引的类型、标签、年份和评分区间扫描取得候选电影，再读取候选电影的数据精确筛选，结果按 movieId 排序
+// 没有可通过索引扫描的条件（如只有排序、min_votes、max_rating 或分面）时改为遍历全部电影筛选
+func filterIndexedMovies(ctx context.Context, filter search.Filter) ([]*search.Document, error) {
+	var candidates [][]string
+
+	if len(filter.Genres) > 0 {
+		genreMatches := make([][]string, 0, len(filter.Genres))
+		for _, genre := range filter.Genres {
+			ids, err := utils.GetMoviesByGenre(ctx, genre, -1)
+			if err != nil {
+				return nil, err
+			}
+			genreMatches = append(genreMatches, ids)
+		}
+		if filter.MatchAllGenres {
+			candidates = append(candidates, store.IntersectIDs(genreMatches))
+		} else {
+			candidates = append(candidates, store.UnionIDs(genreMatches...))
+		}
+	}
+
+	for _, tag := range filter.Tags {
+		ids, err := utils.GetMoviesByTag(ctx, tag, -1)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, ids)
+	}
+
+	// 索引中只有能识别年份的电影，与 year_to 的筛选规则一致
+	if filter.YearFrom > 0 || filter.YearTo > 0 {
+		yearTo := filter.YearTo
+		if yearTo == 0 {
+			yearTo = maxIndexYear
+		}
+		ids, err := utils.GetMoviesByYearRange(ctx, filter.YearFrom, yearTo, -1)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, store.UnionIDs(ids))
+	}
+
+	// 评分区间索引中只有已有评分的电影，只设置 max_rating 时没有评分的电影也满足条件，不能用索引筛选
+	if filter.MinRating > 0 {
+		maxRating := filter.MaxRating
+		if maxRating == 0 {
+			maxRating = 5
+		}
+		ids, err := utils.GetMoviesByRatingRange(ctx, filter.MinRating, maxRating, -1)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, store.UnionIDs(ids))
+	}
+
+	if len(candidates) == 0 {
+		return scanFilteredMovies(ctx, filter)
+	}
+
+	movieIDs := store.IntersectIDs(candidates)
+	data, err := utils.GetMoviesMultipleWithFamilies(ctx, movieIDs, store.IndexFamilies)
+	if err != nil {
+		return nil, err
+	}
+
+	// 索引条目按平均分取整到0.01，需按电影数据再精确匹配一次
+	docs := make([]*search.Document, 0, len(movieIDs))
+	for _, movieID := range movieIDs {
+		row, ok := data[movieID]
+		if !ok {
+			continue
+		}
+		doc := utils.NewSearchDocument(store.Row{Key: movieID, Data: row})
+		if filter.Match(&doc) {
+			docs = append(docs, &doc)
+		}
+	}
+	search.SortDocuments(docs, "", false)
+
+	return docs, nil
+}
+
+// scanFilteredMovies 遍历电影表中计算索引所需的列族筛选全部电影，跳过非电影行，结果按 movieId 排序
+func scanFilteredMovies(ctx context.Context, filter search.Filter) ([]*search.Document, error) {
+	var docs []*search.Document
+	err := utils.ForEachMovie(ctx, store.IndexFamilies, func(row store.Row) error {
+		if !store.IsMovieRow(row) {
+			return nil
+		}
+		doc := utils.NewSearchDocument(row)
+		if filter.Match(&doc) {
+			docs = append(docs, &doc)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	search.SortDocuments(docs, "", false)
+
+	return docs, nil
+}
+
+// cursorScope 筛选结果游标的作用域，排序方式不同的游标不能混用；筛选条件变化时游标仍按排序位置继续
+func (q MovieQuery) cursorScope() string {
+	order := "asc"
+	if q.Descending {
+		order = "desc"
+	}
+	return fmt.Sprintf("movies:%s:%s", q.Sort, order)
+}
+
+// documentCursor 指向文档的游标，同时记录排序字段的值，文档排序值变化或被过滤掉后仍能定位
+func documentCursor(scope string, doc *search.Document, field string) Cursor {
+	cursor := Cursor{Scope: scope, Key: doc.MovieID}
+	switch field {
+	case search.SortTitle:
+		cursor.Value = doc.Title
+	case search.SortYear:
+		cursor.Value = strconv.Itoa(doc.Year)
+	case search.SortAvgRating:
+		cursor.Value = strconv.FormatFloat(doc.AvgRating, 'g', -1, 64)
+	case search.SortRatingCount:
+		cursor.Value = strconv.FormatInt(doc.RatingCount, 10)
+	}
+	return cursor
+}
+
+// cursorDocument 将游标还原为只含排序字段的文档，用于和结果中的文档比较先后
+func cursorDocument(cursor *Cursor, field string) (*search.Document, error) {
+	doc := &search.Document{MovieID: cursor.Key}
+	var err error
+	switch field {
+	case search.SortTitle:
+		doc.Title = cursor.Value
+	case search.SortYear:
+		doc.Year, err = strconv.Atoi(cursor.Value)
+	case search.SortAvgRating:
+		doc.AvgRating, err = strconv.ParseFloat(cursor.Value, 64)
+	case search.SortRatingCount:
+		doc.RatingCount, err = strconv.ParseInt(cursor.Value, 10, 64)
+	}
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return doc, nil
+}
+
+// moviesFromDocuments 批量读取文档对应的电影数据并保持文档顺序，平均分使用文档中的值以与筛选条件一致
+func moviesFromDocuments(ctx context.Context, docs []*search.Document) ([]Movie, error) {
+	movieIDs := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		movieIDs = append(movieIDs, doc.MovieID)
+	}
+
+	data, err := utils.GetMoviesMultiple(ctx, movieIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	movies := []Movie{}
+	for _, doc := range docs {
+		movie := Movie{
+			MovieID:        doc.MovieID,
+			Title:          doc.Title,
+			LocalizedTitle: doc.LocalizedTitle,
+			Genres:         doc.Genres,
+			Year:           doc.Year,
+			Tags:           doc.Tags,
+		}
+		if row, ok := data[doc.MovieID]; ok {
+			movie = movieFromRow(doc.MovieID, row)
+		}
+		movie.AvgRating = doc.AvgRating
+		movie.WeightedRating = roundRating(utils.WeightedRating(doc.RatingCount, doc.AvgRating))
+
+		movies = append(movies, movie)
+	}
+
+	return movies, nil
+}
@@ -4,33 +4,33 @@ import (
 	"context"
 	"fmt"
 	"gohbase/utils"
-	"strconv"
-	"strings"
+	"gohbase/utils/store"
+	"slices"
 )
 
 // GetTotalMoviesCount 获取电影总数
 func GetTotalMoviesCount(ctx context.Context) (int, error) {
-	// 使用缓存优化性能
-	cacheKey := "total_movies_count"
-	if cachedCount, found := utils.Cache.Get(cacheKey); found {
-		return cachedCount.(int), nil
-	}
-
-	// 使用 ScanMoviesWithPagination，它会返回总数，而不直接使用客户端
-	_, totalCount, err := utils.ScanMoviesWithPagination(ctx, 1, 1)
+	// 使用缓存优化性能，缓存失效时只有一个请求执行全表统计
+	totalCount, err := loadCached(ctx, "total_movies_count", func(ctx context.Context) (interface{}, error) {
+		// 通过存储后端统计总数，而不直接使用客户端
+		return utils.CountMovies(ctx)
+	})
 	if err != nil {
 		return 0, err // 出错时返回0和错误，而不是硬编码值
 	}
 
-	// 将结果存入缓存
-	utils.Cache.Set(cacheKey, totalCount)
-
-	return totalCount, nil
+	return totalCount.(int), nil
 }
 
-// GetMoviesList 获取电影列表
-func GetMoviesList(page, perPage int) (*MovieList, error) {
-	ctx := context.Background()
+// movieListScope 电影列表游标的作用域，列表按行键字典序排列
+const movieListScope = "movies"
+
+// GetMoviesList 获取电影列表，按行键字典序分页，不依赖 movieId 连续
+// 按页码翻页时从缓存的上一页末行行键处继续扫描；按游标翻页时直接从游标记录的行键处继续扫描
+func GetMoviesList(ctx context.Context, request PageRequest) (*MovieList, error) {
+	if err := request.checkScope(movieListScope); err != nil {
+		return nil, err
+	}
 
 	// 获取总电影数
 	totalMovies, err := GetTotalMoviesCount(ctx)
@@ -38,109 +38,168 @@ func GetMoviesList(page, perPage int) (*MovieList, error) {
 		return nil, fmt.Errorf("获取电影总数失败: %w", err) // 如果获取失败，返回错误而不是使用默认值
 	}
 
-	// 计算分页参数
-	startIdx := (page-1)*perPage + 1 // 从1开始
-	endIdx := startIdx + perPage
+	perPage := request.PerPage
+	var results []store.Row
+	var hasPrev, hasNext bool
 
-	// 扫描电影范围
-	startRow := fmt.Sprintf("%d", startIdx)
-	endRow := fmt.Sprintf("%d", endIdx)
+	if request.Cursor != nil && request.Cursor.Backward {
+		// 倒序扫描游标之前的行，多取一行判断是否还有上一页
+		results, err = utils.ScanMoviesBefore(ctx, request.Cursor.Key, int64(perPage+1))
+		if err != nil {
+			return nil, err
+		}
+		hasPrev = len(results) > perPage
+		if hasPrev {
+			results = results[:perPage]
+		}
+		slices.Reverse(results)
+		hasNext = true
+	} else {
+		afterRow := ""
+		pastEnd := false
+		if request.Cursor != nil {
+			afterRow = request.Cursor.Key
+		} else if request.Page > 1 {
+			boundaries, err := pageBoundaries(ctx, perPage)
+			if err != nil {
+				return nil, err
+			}
+			if pastEnd = request.Page-2 >= len(boundaries); !pastEnd {
+				afterRow = boundaries[request.Page-2]
+			}
+		}
 
-	// 直接使用 ScanMovies 而非先获取客户端再扫描
-	results, err := utils.ScanMovies(ctx, startRow, endRow, int64(perPage))
-	if err != nil {
-		return nil, err
+		if !pastEnd {
+			// 多取一行判断是否还有下一页
+			results, err = utils.ScanMovies(ctx, rowAfter(afterRow), "", int64(perPage+1))
+			if err != nil {
+				return nil, err
+			}
+		}
+		hasPrev = afterRow != ""
+		hasNext = len(results) > perPage
+		if hasNext {
+			results = results[:perPage]
+		}
 	}
 
 	// 解析电影列表
 	movies := []Movie{}
 
 	for _, result := range results {
-		// 获取行键（即movieId）
-		var movieID string
-		for _, cell := range result.Cells {
-			movieID = string(cell.Row)
-			break
-		}
-
+		// 行键即movieId
+		movieID := result.Key
 		if movieID == "" {
 			continue
 		}
 
-		// 手动构建结果映射
-		resultMap := make(map[string]map[string][]byte)
-		for _, cell := range result.Cells {
-			family := string(cell.Family)
-			qualifier := string(cell.Qualifier)
+		movies = append(movies, movieFromRow(movieID, result.Data))
+	}
+
+	// 构建响应
+	totalPages := (totalMovies + perPage - 1) / perPage // 计算总页数
+	list := &MovieList{
+		Movies:      movies,
+		TotalMovies: totalMovies,
+		PerPage:     perPage,
+		TotalPages:  totalPages,
+	}
+	if request.Cursor == nil {
+		list.Page = request.Page
+	}
+	if len(results) > 0 {
+		if hasNext {
+			list.NextCursor = Cursor{Scope: movieListScope, Key: results[len(results)-1].Key}.Encode()
+		}
+		if hasPrev {
+			list.PrevCursor = Cursor{Scope: movieListScope, Key: results[0].Key, Backward: true}.Encode()
+		}
+	}
+
+	return list, nil
+}
 
-			if _, ok := resultMap[family]; !ok {
-				resultMap[family] = make(map[string][]byte)
+// pageBoundaries 返回每页 perPage 部电影时各页末行的行键，第 i 项为第 i+1 页的末行，只读取 movie 列族
+// 遍历一次全部行键后缓存，按页码翻页时不必每次从第一行扫描到当前页；缓存过期前新增的电影不影响已有的分页边界
+func pageBoundaries(ctx context.Context, perPage int) ([]string, error) {
+	boundaries, err := loadCached(ctx, fmt.Sprintf("movie_list_pages:%d", perPage), func(ctx context.Context) (interface{}, error) {
+		keys := []string{}
+		count := 0
+		err := utils.ForEachMovie(ctx, []string{"movie"}, func(row store.Row) error {
+			if !store.IsMovieRow(row) {
+				return nil
 			}
+			if count++; count%perPage == 0 {
+				keys = append(keys, row.Key)
+			}
+			return nil
+		})
+		return keys, err
+	})
+	if err != nil {
+		return nil, err
+	}
 
-			resultMap[family][qualifier] = cell.Value
-		}
+	return boundaries.([]string), nil
+}
 
-		movieData := utils.ParseMovieData(movieID, resultMap)
+// rowAfter 返回紧跟在 rowKey 之后的最小行键，用于从某行之后开始扫描
+func rowAfter(rowKey string) string {
+	if rowKey == "" {
+		return ""
+	}
+	return rowKey + "\x00"
+}
 
-		movie := Movie{
-			MovieID: movieID,
-		}
+// movieFromRow 根据电影表中的一行数据构建列表中的电影
+func movieFromRow(movieID string, data map[string]map[string][]byte) Movie {
+	movieData := utils.ParseMovieData(movieID, data)
 
-		if title, ok := movieData["title"].(string); ok {
-			movie.Title = title
-			// 尝试从标题中提取年份
-			if matches := strings.Split(title, " ("); len(matches) > 1 {
-				yearStr := strings.TrimSuffix(matches[len(matches)-1], ")")
-				if year, err := strconv.Atoi(yearStr); err == nil {
-					movie.Year = year
-				}
-			}
-		}
+	movie := Movie{
+		MovieID: movieID,
+	}
 
-		if genres, ok := movieData["genres"].([]string); ok {
-			movie.Genres = genres
-		}
+	if title, ok := movieData["title"].(string); ok {
+		movie.Title = title
+		movie.Year = parseTitleYear(title)
+	}
+	if localizedTitle, ok := movieData["localizedTitle"].(string); ok {
+		movie.LocalizedTitle = localizedTitle
+	}
 
-		if avgRating, ok := movieData["avgRating"].(float64); ok {
-			movie.AvgRating = avgRating
-		}
+	if genres, ok := movieData["genres"].([]string); ok {
+		movie.Genres = genres
+	}
 
-		// 添加链接数据
-		if links, ok := movieData["links"].(map[string]interface{}); ok {
-			linkObj := Links{}
+	if avgRating, ok := movieData["avgRating"].(float64); ok {
+		movie.AvgRating = avgRating
+	}
+	movie.WeightedRating = weightedRating(store.ParseRatingAggregate(data[store.StatsFamily]))
 
-			if imdbId, ok := links["imdbId"].(string); ok {
-				linkObj.ImdbID = imdbId
-			}
-			if imdbUrl, ok := links["imdbUrl"].(string); ok {
-				linkObj.ImdbURL = imdbUrl
-			}
-			if tmdbId, ok := links["tmdbId"].(string); ok {
-				linkObj.TmdbID = tmdbId
-			}
-			if tmdbUrl, ok := links["tmdbUrl"].(string); ok {
-				linkObj.TmdbURL = tmdbUrl
-			}
+	// 添加链接数据
+	if links, ok := movieData["links"].(map[string]interface{}); ok {
+		linkObj := Links{}
 
-			movie.Links = linkObj
+		if imdbId, ok := links["imdbId"].(string); ok {
+			linkObj.ImdbID = imdbId
 		}
-
-		// 添加标签数据
-		if uniqueTags, ok := movieData["uniqueTags"].([]string); ok {
-			movie.Tags = uniqueTags
+		if imdbUrl, ok := links["imdbUrl"].(string); ok {
+			linkObj.ImdbURL = imdbUrl
+		}
+		if tmdbId, ok := links["tmdbId"].(string); ok {
+			linkObj.TmdbID = tmdbId
+		}
+		if tmdbUrl, ok := links["tmdbUrl"].(string); ok {
+			linkObj.TmdbURL = tmdbUrl
 		}
 
-		movies = append(movies, movie)
+		movie.Links = linkObj
 	}
 
-	// 构建响应
-	totalPages := (totalMovies + perPage - 1) / perPage // 计算总页数
+	// 添加标签数据
+	if uniqueTags, ok := movieData["uniqueTags"].([]string); ok {
+		movie.Tags = uniqueTags
+	}
 
-	return &MovieList{
-		Movies:      movies,
-		TotalMovies: totalMovies,
-		Page:        page,
-		PerPage:     perPage,
-		TotalPages:  totalPages,
-	}, nil
+	return movie
 }
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+// movieIDs 返回电影列表中的 movieId
+func movieIDs(list *MovieList) []string {
+	ids := []string{}
+	for _, movie := range list.Movies {
+		ids = append(ids, movie.MovieID)
+	}
+	return ids
+}
+
+func TestGetMoviesListPages(t *testing.T) {
+	ctx := useTestStore(t)
+
+	// 旧版评分行和残留行不出现在列表中，也不计入总数
+	tests := []struct {
+		page int
+		want []string
+	}{
+		{1, []string{"1", "10"}},
+		{2, []string{"2", "3"}},
+		{3, []string{}},
+	}
+	for _, tt := range tests {
+		list, err := GetMoviesList(ctx, PageRequest{Page: tt.page, PerPage: 2})
+		if err != nil {
+			t.Fatalf("GetMoviesList(page %d) error: %v", tt.page, err)
+		}
+		if got := movieIDs(list); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("page %d = %v, want %v", tt.page, got, tt.want)
+		}
+		if list.TotalMovies != 4 || list.TotalPages != 2 {
+			t.Errorf("page %d total = %d/%d pages, want 4/2", tt.page, list.TotalMovies, list.TotalPages)
+		}
+	}
+}
+
+func TestGetMoviesListCursor(t *testing.T) {
+	ctx := useTestStore(t)
+
+	// 向后翻页遍历全部电影
+	var forward []string
+	var pages []*MovieList
+	request := PageRequest{Page: 1, PerPage: 3}
+	for {
+		list, err := GetMoviesList(ctx, request)
+		if err != nil {
+			t.Fatalf("GetMoviesList error: %v", err)
+		}
+		pages = append(pages, list)
+		forward = append(forward, movieIDs(list)...)
+		if list.NextCursor == "" {
+			break
+		}
+		if request.Cursor, err = DecodeCursor(list.NextCursor); err != nil {
+			t.Fatalf("DecodeCursor error: %v", err)
+		}
+	}
+	if want := []string{"1", "10", "2", "3"}; !reflect.DeepEqual(forward, want) {
+		t.Fatalf("forward = %v, want %v", forward, want)
+	}
+
+	// 从最后一页向前翻页回到第一页
+	last := pages[len(pages)-1]
+	cursor, err := DecodeCursor(last.PrevCursor)
+	if err != nil {
+		t.Fatalf("DecodeCursor(prev) error: %v", err)
+	}
+	list, err := GetMoviesList(ctx, PageRequest{PerPage: 3, Cursor: cursor})
+	if err != nil {
+		t.Fatalf("GetMoviesList(prev) error: %v", err)
+	}
+	if got := movieIDs(list); !reflect.DeepEqual(got, []string{"1", "10", "2"}) {
+		t.Errorf("previous page = %v, want [1 10 2]", got)
+	}
+	if list.PrevCursor != "" || list.NextCursor == "" {
+		t.Errorf("first page cursors = next %q prev %q, want only next", list.NextCursor, list.PrevCursor)
+	}
+
+	// 其他列表的游标不能用于电影列表
+	_, err = GetMoviesList(ctx, PageRequest{PerPage: 3, Cursor: &Cursor{Scope: "ratings:rating:asc", Key: "1"}})
+	if err != ErrInvalidCursor {
+		t.Errorf("foreign cursor error = %v, want ErrInvalidCursor", err)
+	}
+}
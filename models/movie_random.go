@@ -4,15 +4,12 @@ import (
 	"context"
 	"fmt"
 	"gohbase/utils"
-	"strconv"
-	"strings"
+	"gohbase/utils/store"
 	"time"
 )
 
 // GetRandomMovies 获取随机电影（带缓存）
-func GetRandomMovies(count int) ([]Movie, error) {
-	ctx := context.Background()
-
+func GetRandomMovies(ctx context.Context, count int) ([]Movie, error) {
 	// 获取总电影数
 	totalMovies, err := GetTotalMoviesCount(ctx)
 	if err != nil {
@@ -24,11 +21,18 @@ func GetRandomMovies(count int) ([]Movie, error) {
 	currentHour := time.Now().Hour()
 	cacheKey := fmt.Sprintf("random_movies:%d:%d", count, currentHour)
 
-	// 检查缓存中是否有随机电影数据
-	if cachedMovies, found := utils.Cache.Get(cacheKey); found {
-		return cachedMovies.([]Movie), nil
+	cachedMovies, err := loadCached(ctx, cacheKey, func(ctx context.Context) (interface{}, error) {
+		return loadRandomMovies(ctx, totalMovies, count), nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	return cachedMovies.([]Movie), nil
+}
+
+// loadRandomMovies 随机挑选电影并读取其信息，读取失败或不存在的电影会被跳过
+func loadRandomMovies(ctx context.Context, totalMovies, count int) []Movie {
 	// 生成随机ID列表
 	randomIDs := generateRandomIDs(totalMovies, count)
 	movies := []Movie{}
@@ -53,13 +57,10 @@ func GetRandomMovies(count int) ([]Movie, error) {
 
 		if title, ok := movieData["title"].(string); ok {
 			movie.Title = title
-			// 尝试从标题中提取年份
-			if matches := strings.Split(title, " ("); len(matches) > 1 {
-				yearStr := strings.TrimSuffix(matches[len(matches)-1], ")")
-				if year, err := strconv.Atoi(yearStr); err == nil {
-					movie.Year = year
-				}
-			}
+			movie.Year = parseTitleYear(title)
+		}
+		if localizedTitle, ok := movieData["localizedTitle"].(string); ok {
+			movie.LocalizedTitle = localizedTitle
 		}
 
 		if genres, ok := movieData["genres"].([]string); ok {
@@ -69,6 +70,7 @@ func GetRandomMovies(count int) ([]Movie, error) {
 		if avgRating, ok := movieData["avgRating"].(float64); ok {
 			movie.AvgRating = avgRating
 		}
+		movie.WeightedRating = weightedRating(store.ParseRatingAggregate(data[store.StatsFamily]))
 
 		// 添加标签
 		if tags, ok := movieData["uniqueTags"].([]string); ok {
@@ -78,10 +80,7 @@ func GetRandomMovies(count int) ([]Movie, error) {
 		movies = append(movies, movie)
 	}
 
-	// 将结果存入缓存
-	utils.Cache.Set(cacheKey, movies)
-
-	return movies, nil
+	return movies
 }
 
 // generateRandomIDs 生成不重复的随机ID列表
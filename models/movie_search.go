@@ -4,198 +4,174 @@ import (
 	"context"
 	"fmt"
 	"gohbase/utils"
+	"gohbase/utils/search"
+	"gohbase/utils/store"
+	"math"
 	"strconv"
 	"strings"
+)
 
-	"github.com/tsuna/gohbase/hrpc"
+// 搜索结果游标的作用域：索引搜索按得分排序，降级扫描按行键排序
+const (
+	searchScope     = "search"
+	searchScanScope = "search:scan"
 )
 
-// SearchMovies 搜索电影（带缓存）
-func SearchMovies(query string, page, perPage int) (*MovieList, error) {
+// SearchMovies 搜索电影（带缓存），facets 为需要统计的分面字段
+func SearchMovies(ctx context.Context, query string, request PageRequest, facets []string) (*MovieList, error) {
 	// 构建缓存键
-	cacheKey := fmt.Sprintf("search:%s:%d:%d", query, page, perPage)
-
-	// 检查缓存
-	if cachedResults, found := utils.Cache.Get(cacheKey); found {
-		return cachedResults.(*MovieList), nil
+	cursorToken := ""
+	if request.Cursor != nil {
+		cursorToken = request.Cursor.Encode()
 	}
+	cacheKey := fmt.Sprintf("search:%s:%d:%d:%s:%s", query, request.Page, request.PerPage, strings.Join(facets, ","), cursorToken)
 
-	ctx := context.Background()
-
-	// 创建全表扫描
-	scan, err := hrpc.NewScanStr(ctx, "moviedata")
+	cachedResults, err := loadCached(ctx, cacheKey, func(ctx context.Context) (interface{}, error) {
+		if index := currentSearchIndex(); index != nil {
+			return searchMoviesByIndex(ctx, index, query, request, facets)
+		}
+		// 索引尚未构建成功时降级为二级索引的前缀扫描
+		return scanSearchMovies(ctx, query, request, facets)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	scanner := utils.GetClient().Scan(scan)
-	matchedMovies := []Movie{}
-
-	// 将查询转为小写以进行不区分大小写的匹配
-	queryLower := strings.ToLower(query)
+	return cachedResults.(*MovieList), nil
+}
 
-	for {
-		res, err := scanner.Next()
-		if err != nil {
-			break // 到达结尾
+// searchMoviesByIndex 通过倒排索引搜索，结果按相关度排序并分页
+func searchMoviesByIndex(ctx context.Context, index *search.Index, query string, request PageRequest, facets []string) (*MovieList, error) {
+	if err := request.checkScope(searchScope); err != nil {
+		return nil, err
+	}
+	var anchorScore float64
+	if request.Cursor != nil {
+		var err error
+		if anchorScore, err = strconv.ParseFloat(request.Cursor.Value, 64); err != nil {
+			return nil, ErrInvalidCursor
 		}
+	}
 
-		// 获取行键（即movieId）
-		var movieID string
-		for _, cell := range res.Cells {
-			movieID = string(cell.Row)
-			break
-		}
+	hits := index.Search(query)
 
-		if movieID == "" {
-			continue
-		}
+	docs := make([]*search.Document, 0, len(hits))
+	for _, hit := range hits {
+		docs = append(docs, hit.Document)
+	}
 
-		// 手动构建结果映射
-		resultMap := make(map[string]map[string][]byte)
-		for _, cell := range res.Cells {
-			family := string(cell.Family)
-			qualifier := string(cell.Qualifier)
+	totalMatches := len(hits)
+	totalPages := (totalMatches + request.PerPage - 1) / request.PerPage
 
-			if _, ok := resultMap[family]; !ok {
-				resultMap[family] = make(map[string][]byte)
+	// 结果按得分降序、movieId 升序排列
+	startIdx, endIdx := request.window(totalMatches, func(i int) int {
+		if hits[i].Score != anchorScore {
+			if hits[i].Score > anchorScore {
+				return -1
 			}
-
-			resultMap[family][qualifier] = cell.Value
+			return 1
 		}
+		return strings.Compare(hits[i].Document.MovieID, request.Cursor.Key)
+	})
 
-		movieData := utils.ParseMovieData(movieID, resultMap)
-
-		// 检查标题是否匹配
-		if title, ok := movieData["title"].(string); ok {
-			if strings.Contains(strings.ToLower(title), queryLower) {
-				movie := Movie{
-					MovieID: movieID,
-					Title:   title,
-				}
-
-				// 尝试从标题中提取年份
-				if matches := strings.Split(title, " ("); len(matches) > 1 {
-					yearStr := strings.TrimSuffix(matches[len(matches)-1], ")")
-					if year, err := strconv.Atoi(yearStr); err == nil {
-						movie.Year = year
-					}
-				}
-
-				if genres, ok := movieData["genres"].([]string); ok {
-					movie.Genres = genres
-				}
-
-				// 使用 utils.GetMovieRatings 获取评分数据，与 GetMovieByID 保持一致
-				ratingData, err := utils.GetMovieRatings(ctx, movieID)
-				if err == nil && ratingData != nil {
-					if avgRating, ok := ratingData["avgRating"].(float64); ok {
-						movie.AvgRating = avgRating
-					}
-				} else {
-					// 如果获取评分失败，尝试使用 movieData 中的评分，最后默认为 0
-					if avgRating, ok := movieData["avgRating"].(float64); ok {
-						movie.AvgRating = avgRating
-					} else {
-						movie.AvgRating = 0.0
-					}
-				}
-
-				// 添加标签
-				if tags, ok := movieData["uniqueTags"].([]string); ok {
-					movie.Tags = tags
-				}
-
-				matchedMovies = append(matchedMovies, movie)
-				continue
-			}
-		}
+	movies := []Movie{}
+	for _, hit := range hits[startIdx:endIdx] {
+		movies = append(movies, movieFromHit(ctx, hit))
+	}
 
-		// 检查类型是否匹配
-		if genres, ok := movieData["genres"].([]string); ok {
-			for _, genre := range genres {
-				if strings.Contains(strings.ToLower(genre), queryLower) {
-					movie := Movie{
-						MovieID: movieID,
-					}
-
-					if title, ok := movieData["title"].(string); ok {
-						movie.Title = title
-						// 尝试从标题中提取年份
-						if matches := strings.Split(title, " ("); len(matches) > 1 {
-							yearStr := strings.TrimSuffix(matches[len(matches)-1], ")")
-							if year, err := strconv.Atoi(yearStr); err == nil {
-								movie.Year = year
-							}
-						}
-					}
-
-					movie.Genres = genres
-
-					// 使用 utils.GetMovieRatings 获取评分数据，与 GetMovieByID 保持一致
-					ratingData, err := utils.GetMovieRatings(ctx, movieID)
-					if err == nil && ratingData != nil {
-						if avgRating, ok := ratingData["avgRating"].(float64); ok {
-							movie.AvgRating = avgRating
-						}
-					} else {
-						// 如果获取评分失败，尝试使用 movieData 中的评分，最后默认为 0
-						if avgRating, ok := movieData["avgRating"].(float64); ok {
-							movie.AvgRating = avgRating
-						} else {
-							movie.AvgRating = 0.0
-						}
-					}
-
-					// 添加标签
-					if tags, ok := movieData["uniqueTags"].([]string); ok {
-						movie.Tags = tags
-					}
-
-					matchedMovies = append(matchedMovies, movie)
-					break
-				}
-			}
+	list := &MovieList{
+		Movies:      movies,
+		TotalMovies: totalMatches,
+		PerPage:     request.PerPage,
+		TotalPages:  totalPages,
+		Facets:      search.CountFacets(docs, facets),
+	}
+	if request.Cursor == nil {
+		list.Page = request.Page
+	}
+	list.NextCursor, list.PrevCursor = pageCursors(startIdx, endIdx, totalMatches, func(i int) Cursor {
+		return Cursor{
+			Scope: searchScope,
+			Key:   hits[i].Document.MovieID,
+			Value: strconv.FormatFloat(hits[i].Score, 'g', -1, 64),
 		}
+	})
+
+	return list, nil
+}
+
+// movieFromHit 将搜索结果转换为电影，平均分实时读取评分聚合，避免索引重建前显示旧值
+func movieFromHit(ctx context.Context, hit search.Hit) Movie {
+	doc := hit.Document
+	movie := Movie{
+		MovieID:        doc.MovieID,
+		Title:          doc.Title,
+		LocalizedTitle: doc.LocalizedTitle,
+		Genres:         doc.Genres,
+		Year:           doc.Year,
+		AvgRating:      doc.AvgRating,
+		Tags:           doc.Tags,
+		Score:          math.Round(hit.Score*1000) / 1000,
+	}
+
+	if aggregate, err := utils.GetRatingAggregate(ctx, doc.MovieID); err == nil {
+		movie.AvgRating = aggregate.Avg()
+		movie.WeightedRating = weightedRating(aggregate)
 	}
 
-	// 计算分页
-	totalMatches := len(matchedMovies)
-	totalPages := (totalMatches + perPage - 1) / perPage
+	return movie
+}
 
-	startIdx := (page - 1) * perPage
-	endIdx := startIdx + perPage
-	if endIdx > totalMatches {
-		endIdx = totalMatches
+// scanSearchMovies 通过二级索引按标题单词前缀或类型前缀搜索并分页，结果按 movieId 排序
+// 只读取命中电影计算索引所需的列族，平均分和评分人数取自评分聚合，当前页再批量读取电影的完整数据
+func scanSearchMovies(ctx context.Context, query string, request PageRequest, facets []string) (*MovieList, error) {
+	if err := request.checkScope(searchScanScope); err != nil {
+		return nil, err
+	}
+
+	movieIDs, err := utils.SearchMovies(ctx, query, -1)
+	if err != nil {
+		return nil, err
+	}
+	data, err := utils.GetMoviesMultipleWithFamilies(ctx, movieIDs, store.IndexFamilies)
+	if err != nil {
+		return nil, err
 	}
 
-	// 如果没有匹配项
-	if startIdx >= totalMatches {
-		result := &MovieList{
-			Movies:      []Movie{},
-			TotalMovies: totalMatches,
-			Page:        page,
-			PerPage:     perPage,
-			TotalPages:  totalPages,
+	docs := make([]*search.Document, 0, len(movieIDs))
+	for _, movieID := range movieIDs {
+		if row, ok := data[movieID]; ok {
+			doc := utils.NewSearchDocument(store.Row{Key: movieID, Data: row})
+			docs = append(docs, &doc)
 		}
+	}
+	search.SortDocuments(docs, "", false)
 
-		// 缓存搜索结果
-		utils.Cache.Set(cacheKey, result)
+	totalMatches := len(docs)
+	totalPages := (totalMatches + request.PerPage - 1) / request.PerPage
 
-		return result, nil
+	startIdx, endIdx := request.window(totalMatches, func(i int) int {
+		return strings.Compare(docs[i].MovieID, request.Cursor.Key)
+	})
+
+	movies, err := moviesFromDocuments(ctx, docs[startIdx:endIdx])
+	if err != nil {
+		return nil, err
 	}
 
-	// 构建结果
-	result := &MovieList{
-		Movies:      matchedMovies[startIdx:endIdx],
+	list := &MovieList{
+		Movies:      movies,
 		TotalMovies: totalMatches,
-		Page:        page,
-		PerPage:     perPage,
+		PerPage:     request.PerPage,
 		TotalPages:  totalPages,
+		Facets:      search.CountFacets(docs, facets),
 	}
+	if request.Cursor == nil {
+		list.Page = request.Page
+	}
+	list.NextCursor, list.PrevCursor = pageCursors(startIdx, endIdx, totalMatches, func(i int) Cursor {
+		return Cursor{Scope: searchScanScope, Key: docs[i].MovieID}
+	})
 
-	// 缓存搜索结果
-	utils.Cache.Set(cacheKey, result)
-
-	return result, nil
+	return list, nil
 }
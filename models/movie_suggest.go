@@ -0,0 +1,38 @@
+package models
+
+import (
+	"errors"
+	"gohbase/utils"
+	"gohbase/utils/search"
+)
+
+// ErrSearchIndexNotReady 搜索索引尚未构建完成
+var ErrSearchIndexNotReady = errors.New("搜索索引尚未就绪")
+
+// currentSearchIndex 返回当前的搜索索引快照，尚未构建时返回 nil
+func currentSearchIndex() *search.Index {
+	if utils.SearchEngine == nil {
+		return nil
+	}
+	return utils.SearchEngine.Index()
+}
+
+// SuggestMovies 根据输入返回标题补全建议，直接查询内存中的倒排索引，不经过缓存和存储
+func SuggestMovies(query string, limit int) ([]Suggestion, error) {
+	index := currentSearchIndex()
+	if index == nil {
+		return nil, ErrSearchIndexNotReady
+	}
+
+	suggestions := []Suggestion{}
+	for _, doc := range index.Suggest(query, limit) {
+		suggestions = append(suggestions, Suggestion{
+			MovieID:        doc.MovieID,
+			Title:          doc.Title,
+			LocalizedTitle: doc.LocalizedTitle,
+			Year:           parseTitleYear(doc.Title),
+		})
+	}
+
+	return suggestions, nil
+}
@@ -0,0 +1,189 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"gohbase/utils"
+	"gohbase/utils/store"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 评分列表的排序字段
+const (
+	RatingSortTime   = "timestamp"
+	RatingSortRating = "rating"
+)
+
+// RatingQuery 评分列表的筛选与排序条件
+type RatingQuery struct {
+	Sort       string // RatingSortTime 或 RatingSortRating
+	Descending bool
+	Since      int64 // 评分时间下限（Unix秒，含），0表示不限
+	Until      int64 // 评分时间上限（Unix秒，含），0表示不限
+}
+
+// cursorScope 评分列表游标的作用域，排序方式不同的游标不能混用
+func (q RatingQuery) cursorScope() string {
+	order := "asc"
+	if q.Descending {
+		order = "desc"
+	}
+	return fmt.Sprintf("ratings:%s:%s", q.Sort, order)
+}
+
+// match 判断评分时间是否在筛选范围内
+func (q RatingQuery) match(rating store.UserRating) bool {
+	if q.Since > 0 && rating.Timestamp < q.Since {
+		return false
+	}
+	if q.Until > 0 && rating.Timestamp > q.Until {
+		return false
+	}
+	return true
+}
+
+// compare 比较两条评分在列表中的先后，排序字段相同时按用户ID升序
+func (q RatingQuery) compare(a, b store.UserRating) int {
+	var cmp int
+	switch q.Sort {
+	case RatingSortRating:
+		cmp = compareFloat(a.Rating, b.Rating)
+	default:
+		cmp = compareFloat(float64(a.Timestamp), float64(b.Timestamp))
+	}
+
+	if cmp != 0 {
+		if q.Descending {
+			return -cmp
+		}
+		return cmp
+	}
+	return strings.Compare(a.UserID, b.UserID)
+}
+
+// ratingCursor 指向评分的游标，记录排序字段的值
+func (q RatingQuery) ratingCursor(rating store.UserRating) Cursor {
+	cursor := Cursor{Scope: q.cursorScope(), Key: rating.UserID}
+	switch q.Sort {
+	case RatingSortRating:
+		cursor.Value = strconv.FormatFloat(rating.Rating, 'f', 1, 64)
+	default:
+		cursor.Value = strconv.FormatInt(rating.Timestamp, 10)
+	}
+	return cursor
+}
+
+// cursorRating 将游标还原为只含排序字段的评分
+func (q RatingQuery) cursorRating(cursor *Cursor) (store.UserRating, error) {
+	rating := store.UserRating{UserID: cursor.Key}
+	var err error
+	switch q.Sort {
+	case RatingSortRating:
+		rating.Rating, err = strconv.ParseFloat(cursor.Value, 64)
+	default:
+		rating.Timestamp, err = strconv.ParseInt(cursor.Value, 10, 64)
+	}
+	if err != nil {
+		return rating, ErrInvalidCursor
+	}
+	return rating, nil
+}
+
+// ListMovieRatings 分页返回电影的评分，先按评分时间筛选再排序，筛选排序后的结果会缓存，翻页时不再读取全部评分
+// 数量及平均、最低、最高分取自评分聚合，与电影详情一致；Total 为满足时间筛选的评分数量
+// 评分聚合与评分列表都计入旧版评分行（重建与评分写入均如此），未筛选时 Total 与 Count 一致
+func ListMovieRatings(ctx context.Context, movieID string, query RatingQuery, request PageRequest) (*RatingList, error) {
+	if err := request.checkScope(query.cursorScope()); err != nil {
+		return nil, err
+	}
+	var anchor store.UserRating
+	if request.Cursor != nil {
+		var err error
+		if anchor, err = query.cursorRating(request.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	matched, err := query.sortedRatings(ctx, movieID)
+	if err != nil {
+		return nil, err
+	}
+	aggregate, err := utils.GetRatingAggregate(ctx, movieID)
+	if err != nil {
+		return nil, err
+	}
+	if aggregate == nil {
+		aggregate = &store.RatingAggregate{}
+	}
+
+	total := len(matched)
+	startIdx, endIdx := request.window(total, func(i int) int {
+		return query.compare(matched[i], anchor)
+	})
+
+	list := &RatingList{
+		Ratings:    []Rating{},
+		Count:      int(aggregate.Count),
+		AvgRating:  aggregate.Avg(),
+		MinRating:  aggregate.Min,
+		MaxRating:  aggregate.Max,
+		Total:      total,
+		PerPage:    request.PerPage,
+		TotalPages: (total + request.PerPage - 1) / request.PerPage,
+	}
+	if request.Cursor == nil {
+		list.Page = request.Page
+	}
+	for _, rating := range matched[startIdx:endIdx] {
+		list.Ratings = append(list.Ratings, Rating{
+			UserID:    rating.UserID,
+			Rating:    rating.Rating,
+			Timestamp: rating.Timestamp,
+		})
+	}
+	list.NextCursor, list.PrevCursor = pageCursors(startIdx, endIdx, total, func(i int) Cursor {
+		return query.ratingCursor(matched[i])
+	})
+
+	return list, nil
+}
+
+// sortedRatings 返回电影满足时间筛选的全部评分并按排序条件排列，结果按电影和查询条件缓存，评分写入时清除
+func (q RatingQuery) sortedRatings(ctx context.Context, movieID string) ([]store.UserRating, error) {
+	key := fmt.Sprintf("rating_list:%s:%s:%d:%d", movieID, q.cursorScope(), q.Since, q.Until)
+	matched, err := loadCached(ctx, key, func(ctx context.Context) (interface{}, error) {
+		ratings, err := utils.GetMovieRatings(ctx, movieID)
+		if err != nil {
+			return nil, err
+		}
+
+		matched := make([]store.UserRating, 0, len(ratings))
+		for _, rating := range ratings {
+			if q.match(rating) {
+				matched = append(matched, rating)
+			}
+		}
+		sort.SliceStable(matched, func(i, j int) bool {
+			return q.compare(matched[i], matched[j]) < 0
+		})
+		return matched, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return matched.([]store.UserRating), nil
+}
+
+// compareFloat 比较两个数值，返回 -1、0 或 1
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
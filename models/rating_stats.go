@@ -0,0 +1,112 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"gohbase/utils"
+	"gohbase/utils/store"
+	"math"
+	"sort"
+)
+
+// ratingPercentiles 评分分布中返回的百分位
+var ratingPercentiles = []int{10, 25, 50, 75, 90}
+
+// GetRatingDistribution 获取电影的评分分布统计（带缓存），电影不存在时返回 ErrMovieNotFound
+func GetRatingDistribution(ctx context.Context, movieID string) (*RatingDistribution, error) {
+	cacheKey := fmt.Sprintf("rating_stats:%s", movieID)
+
+	cachedData, err := loadCached(ctx, cacheKey, func(ctx context.Context) (interface{}, error) {
+		return loadRatingDistribution(ctx, movieID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cachedData.(*RatingDistribution), nil
+}
+
+// loadRatingDistribution 读取电影的全部评分并计算分布
+func loadRatingDistribution(ctx context.Context, movieID string) (*RatingDistribution, error) {
+	data, err := utils.GetMovieWithFamilies(ctx, movieID, []string{"movie"})
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, ErrMovieNotFound
+	}
+
+	ratings, err := utils.GetMovieRatings(ctx, movieID)
+	if err != nil {
+		return nil, err
+	}
+
+	return computeRatingDistribution(movieID, ratings), nil
+}
+
+// computeRatingDistribution 计算评分直方图、中位数、标准差（总体）、百分位及首末评分时间
+// 百分位按线性插值计算，没有评分时各统计值为0
+func computeRatingDistribution(movieID string, ratings []store.UserRating) *RatingDistribution {
+	distribution := &RatingDistribution{
+		MovieID:     movieID,
+		Histogram:   make([]HistogramBucket, store.HistogramBuckets),
+		Percentiles: make(map[string]float64, len(ratingPercentiles)),
+	}
+	for i := range distribution.Histogram {
+		distribution.Histogram[i].Rating = store.HistogramValue(i)
+	}
+
+	values := make([]float64, 0, len(ratings))
+	aggregate := &store.RatingAggregate{}
+	for _, rating := range ratings {
+		values = append(values, rating.Rating)
+		aggregate.Add(rating.Rating)
+		if index, ok := store.HistogramIndex(rating.Rating); ok {
+			distribution.Histogram[index].Count++
+		}
+
+		if rating.Timestamp > 0 {
+			if distribution.FirstRatedAt == 0 || rating.Timestamp < distribution.FirstRatedAt {
+				distribution.FirstRatedAt = rating.Timestamp
+			}
+			if rating.Timestamp > distribution.LastRatedAt {
+				distribution.LastRatedAt = rating.Timestamp
+			}
+		}
+	}
+
+	distribution.Count = len(values)
+	for _, p := range ratingPercentiles {
+		distribution.Percentiles[fmt.Sprintf("p%d", p)] = 0
+	}
+	if len(values) == 0 {
+		return distribution
+	}
+
+	avg := aggregate.Avg()
+	var squares float64
+	for _, value := range values {
+		squares += (value - avg) * (value - avg)
+	}
+
+	sort.Float64s(values)
+	distribution.AvgRating = avg
+	distribution.MinRating = values[0]
+	distribution.MaxRating = values[len(values)-1]
+	distribution.Median = percentile(values, 50)
+	distribution.StdDev = math.Round(math.Sqrt(squares/float64(len(values)))*1000) / 1000
+	for _, p := range ratingPercentiles {
+		distribution.Percentiles[fmt.Sprintf("p%d", p)] = percentile(values, p)
+	}
+
+	return distribution
+}
+
+// percentile 计算有序数据的第 p 百分位，位于两个值之间时线性插值
+func percentile(sorted []float64, p int) float64 {
+	position := float64(p) / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	value := sorted[lower] + (sorted[upper]-sorted[lower])*(position-float64(lower))
+	return math.Round(value*1000) / 1000
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gohbase/utils"
+	"gohbase/utils/store"
+	"time"
+)
+
+// 评分时间线的时间段粒度
+const (
+	TimelineBucketDay   = "day"
+	TimelineBucketWeek  = "week"
+	TimelineBucketMonth = "month"
+	TimelineBucketYear  = "year"
+)
+
+// ErrInvalidTimelineBucket 不支持的时间段粒度
+var ErrInvalidTimelineBucket = errors.New("bucket 须为 day、week、month 或 year")
+
+// GetRatingTimeline 获取电影按时间段汇总的评分数量与平均分（带缓存），基于 timeline 列族的按天统计，不读取原始评分
+// 电影不存在时返回 ErrMovieNotFound
+func GetRatingTimeline(ctx context.Context, movieID, bucket string) (*RatingTimeline, error) {
+	if !IsTimelineBucket(bucket) {
+		return nil, ErrInvalidTimelineBucket
+	}
+
+	cacheKey := fmt.Sprintf("rating_timeline:%s:%s", movieID, bucket)
+	cachedData, err := loadCached(ctx, cacheKey, func(ctx context.Context) (interface{}, error) {
+		data, err := utils.GetMovieWithFamilies(ctx, movieID, []string{"movie"})
+		if err != nil {
+			return nil, err
+		}
+		if data == nil {
+			return nil, ErrMovieNotFound
+		}
+
+		days, err := utils.GetRatingTimeline(ctx, movieID)
+		if err != nil {
+			return nil, err
+		}
+
+		return &RatingTimeline{
+			MovieID: movieID,
+			Bucket:  bucket,
+			Buckets: bucketTimeline(days, bucket),
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cachedData.(*RatingTimeline), nil
+}
+
+// IsTimelineBucket 判断是否为支持的时间段粒度
+func IsTimelineBucket(bucket string) bool {
+	switch bucket {
+	case TimelineBucketDay, TimelineBucketWeek, TimelineBucketMonth, TimelineBucketYear:
+		return true
+	}
+	return false
+}
+
+// bucketTimeline 将按日期升序的按天统计合并为时间段，结果同样按时间升序
+func bucketTimeline(days []store.DayStat, bucket string) []TimelineBucket {
+	buckets := []TimelineBucket{}
+	sums := []float64{}
+	for _, day := range days {
+		start := bucketStart(day.Day, bucket)
+		if n := len(buckets); n == 0 || !buckets[n-1].Start.Equal(start) {
+			buckets = append(buckets, TimelineBucket{Start: start})
+			sums = append(sums, 0)
+		}
+		buckets[len(buckets)-1].Count += day.Count
+		sums[len(sums)-1] += day.Sum
+	}
+
+	for i := range buckets {
+		buckets[i].AvgRating = roundRating(sums[i] / float64(buckets[i].Count))
+	}
+	return buckets
+}
+
+// bucketStart 返回日期所在时间段的起点，周以周一为起点
+func bucketStart(day time.Time, bucket string) time.Time {
+	switch bucket {
+	case TimelineBucketWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case TimelineBucketMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case TimelineBucketYear:
+		return time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day
+}
@@ -0,0 +1,208 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gohbase/utils"
+	"gohbase/utils/store"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 评分写入相关错误
+var (
+	ErrInvalidRating  = errors.New("评分必须在0.5到5.0之间，且以0.5为间隔")
+	ErrMovieNotFound  = errors.New("电影不存在")
+	ErrRatingExists   = errors.New("用户已对该电影评分")
+	ErrRatingNotFound = errors.New("用户尚未对该电影评分")
+	ErrRatingConflict = errors.New("评分正被同时修改，请稍后重试")
+)
+
+// maxRatingWriteAttempts 同一用户并发写入导致条件写入失败时的最大尝试次数
+const maxRatingWriteAttempts = 3
+
+// ValidateRating 校验评分是否符合0.5~5.0的半星制
+func ValidateRating(rating float64) error {
+	if rating < 0.5 || rating > 5.0 || math.Mod(rating*2, 1) != 0 {
+		return ErrInvalidRating
+	}
+	return nil
+}
+
+// CreateRating 新增用户评分，用户已评分时返回 ErrRatingExists
+func CreateRating(ctx context.Context, movieID, userID string, rating float64) (*Rating, error) {
+	if err := ValidateRating(rating); err != nil {
+		return nil, err
+	}
+
+	return writeRating(ctx, movieID, userID, rating, func(existing store.UserRating) error {
+		if existing.Rating > 0 {
+			return ErrRatingExists
+		}
+		return nil
+	})
+}
+
+// UpdateRating 修改用户评分，用户未评分时返回 ErrRatingNotFound
+func UpdateRating(ctx context.Context, movieID, userID string, rating float64) (*Rating, error) {
+	if err := ValidateRating(rating); err != nil {
+		return nil, err
+	}
+
+	return writeRating(ctx, movieID, userID, rating, func(existing store.UserRating) error {
+		if existing.Rating == 0 {
+			return ErrRatingNotFound
+		}
+		return nil
+	})
+}
+
+// DeleteRating 删除用户评分，用户未评分时返回 ErrRatingNotFound
+// 仅当评分未被并发修改时删除，冲突时重新读取并重试，多次冲突后返回 ErrRatingConflict
+func DeleteRating(ctx context.Context, movieID, userID string) error {
+	for attempt := 0; attempt < maxRatingWriteAttempts; attempt++ {
+		existing, raw, err := getExistingRating(ctx, movieID, userID)
+		if err != nil {
+			return err
+		}
+		if existing.Rating == 0 {
+			return ErrRatingNotFound
+		}
+
+		deleted, err := utils.DeleteRating(ctx, movieID, userID, raw)
+		if err != nil {
+			return fmt.Errorf("删除评分失败: %w", err)
+		}
+		if !deleted {
+			continue
+		}
+
+		// 旧版评分行在列中没有评分时生效，删除后不能再出现
+		if err := utils.DeleteRatingRow(ctx, movieID, userID); err != nil {
+			logrus.Errorf("删除用户 %s 对电影 %s 的旧版评分行失败，请执行 rebuild-stats 重建: %v", userID, movieID, err)
+		}
+
+		if err := utils.DeleteUserRating(ctx, userID, movieID); err != nil {
+			logrus.Errorf("删除用户评分表中用户 %s 对电影 %s 的评分失败，请执行 rebuild-user-ratings 重建: %v", userID, movieID, err)
+		}
+
+		updateRatingAggregate(ctx, movieID, existing, store.UserRating{UserID: userID})
+		InvalidateMovieCache(movieID)
+		InvalidateUserCache(userID)
+		logrus.Infof("用户 %s 删除了电影 %s 的评分", userID, movieID)
+
+		return nil
+	}
+
+	return ErrRatingConflict
+}
+
+// InvalidateMovieCache 清除包含指定电影评分信息的缓存
+func InvalidateMovieCache(movieID string) {
+	utils.Cache.Delete(fmt.Sprintf("movie_detail:%s", movieID))
+	utils.Cache.Delete(fmt.Sprintf("rating_stats:%s", movieID))
+	utils.Cache.DeletePrefix(fmt.Sprintf("rating_list:%s:", movieID))
+	utils.Cache.DeletePrefix(fmt.Sprintf("rating_timeline:%s:", movieID))
+	utils.Cache.DeletePrefix("search:")
+	utils.Cache.DeletePrefix("random_movies:")
+}
+
+// InvalidateUserCache 清除包含指定用户评分信息的缓存
+func InvalidateUserCache(userID string) {
+	utils.Cache.Delete(fmt.Sprintf("user_profile:%s", userID))
+	utils.Cache.DeletePrefix(fmt.Sprintf("recommend:%s:", userID))
+}
+
+// getExistingRating 确认电影存在并返回用户当前的评分及评分时间（未评分时 Rating 为0），
+// 以及 rating:{userId} 列中实际存储的值，作为条件写入的期望值
+// 列中没有评分时读取旧版评分行 {movieId}_{userId}，与评分列表和评分聚合一样计入旧版评分
+func getExistingRating(ctx context.Context, movieID, userID string) (store.UserRating, []byte, error) {
+	existing := store.UserRating{UserID: userID}
+
+	data, err := utils.GetMovieWithFamilies(ctx, movieID, []string{"movie", "rating"})
+	if err != nil {
+		return existing, nil, err
+	}
+	if data == nil || data["movie"] == nil {
+		return existing, nil, ErrMovieNotFound
+	}
+
+	// 已删除的评分为空值，视为未评分
+	raw := data["rating"]["rating:"+userID]
+	if len(raw) == 0 {
+		legacyKey := store.RatingRowPrefix(movieID) + userID
+		legacy, err := utils.GetMovieWithFamilies(ctx, legacyKey, []string{"rating"})
+		if err != nil {
+			return existing, nil, err
+		}
+		if rating, ok := store.ParseRatingRow(movieID, store.Row{Key: legacyKey, Data: legacy}); ok {
+			existing.Rating, existing.Timestamp = rating.Rating, rating.Timestamp
+		}
+		return existing, raw, nil
+	}
+	if existing.Rating, err = strconv.ParseFloat(string(raw), 64); err != nil {
+		return existing, nil, fmt.Errorf("解析用户 %s 对电影 %s 的评分失败: %w", userID, movieID, err)
+	}
+	existing.Timestamp, _ = strconv.ParseInt(string(data["rating"]["timestamp:"+userID]), 10, 64)
+	return existing, raw, nil
+}
+
+// writeRating 读取用户当前的评分并由 check 校验后写入新评分，仅当评分未被并发修改时写入，冲突时重新读取并重试
+// 写入成功后才同步用户评分表、按新旧评分的差值更新评分聚合并清除相关缓存，多次冲突后返回 ErrRatingConflict
+func writeRating(ctx context.Context, movieID, userID string, rating float64, check func(existing store.UserRating) error) (*Rating, error) {
+	for attempt := 0; attempt < maxRatingWriteAttempts; attempt++ {
+		existing, raw, err := getExistingRating(ctx, movieID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if err := check(existing); err != nil {
+			return nil, err
+		}
+
+		timestamp := time.Now().Unix()
+		written, err := utils.PutRating(ctx, movieID, userID, raw, rating, timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("写入评分失败: %w", err)
+		}
+		if !written {
+			continue
+		}
+
+		// 电影行中的评分为权威数据，用户评分表写入失败时只记录日志，可通过重建命令修复
+		if err := utils.PutUserRating(ctx, userID, movieID, rating, timestamp); err != nil {
+			logrus.Errorf("写入用户评分表中用户 %s 对电影 %s 的评分失败，请执行 rebuild-user-ratings 重建: %v", userID, movieID, err)
+		}
+
+		updateRatingAggregate(ctx, movieID, existing, store.UserRating{UserID: userID, Rating: rating, Timestamp: timestamp})
+		InvalidateMovieCache(movieID)
+		InvalidateUserCache(userID)
+		logrus.Infof("用户 %s 对电影 %s 评分 %.1f", userID, movieID, rating)
+
+		return &Rating{
+			UserID:    userID,
+			Rating:    rating,
+			Timestamp: timestamp,
+		}, nil
+	}
+
+	return nil, ErrRatingConflict
+}
+
+// updateRatingAggregate 更新评分聚合、按天统计及评分区间索引，old/updated 中 Rating 为0表示不存在
+// 失败时只记录日志，可通过重建命令修复
+func updateRatingAggregate(ctx context.Context, movieID string, old, updated store.UserRating) {
+	if err := utils.UpdateRatingTimeline(ctx, movieID, old, updated); err != nil {
+		logrus.Errorf("更新电影 %s 的按天评分统计失败，请执行 rebuild-stats 重建: %v", movieID, err)
+	}
+	if err := utils.UpdateRatingAggregate(ctx, movieID, old.Rating, updated.Rating); err != nil {
+		logrus.Errorf("更新电影 %s 的评分聚合失败，请执行 rebuild-stats 重建: %v", movieID, err)
+		return
+	}
+	if err := utils.UpdateMovieIndex(ctx, movieID); err != nil {
+		logrus.Errorf("更新电影 %s 的二级索引失败，请执行 rebuild-index 重建: %v", movieID, err)
+	}
+}
@@ -0,0 +1,195 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"gohbase/utils"
+)
+
+func TestValidateRating(t *testing.T) {
+	tests := []struct {
+		rating float64
+		want   error
+	}{
+		{0.5, nil},
+		{3, nil},
+		{4.5, nil},
+		{5, nil},
+		{0, ErrInvalidRating},
+		{5.5, ErrInvalidRating},
+		{3.3, ErrInvalidRating},
+		{-1, ErrInvalidRating},
+	}
+	for _, tt := range tests {
+		if err := ValidateRating(tt.rating); !errors.Is(err, tt.want) {
+			t.Errorf("ValidateRating(%v) = %v, want %v", tt.rating, err, tt.want)
+		}
+	}
+}
+
+// movieRatings 返回电影的评分列表（userId -> 评分），并检查列表数量与评分聚合一致
+func movieRatings(t *testing.T, movieID string) map[string]float64 {
+	t.Helper()
+	list, err := ListMovieRatings(t.Context(), movieID, RatingQuery{Sort: RatingSortTime}, PageRequest{Page: 1, PerPage: 50})
+	if err != nil {
+		t.Fatalf("ListMovieRatings(%s) error: %v", movieID, err)
+	}
+	if list.Total != list.Count {
+		t.Errorf("movie %s Total = %d, Count = %d, want equal", movieID, list.Total, list.Count)
+	}
+
+	ratings := make(map[string]float64, len(list.Ratings))
+	for _, rating := range list.Ratings {
+		ratings[rating.UserID] = rating.Rating
+	}
+	return ratings
+}
+
+func TestRatingWritePath(t *testing.T) {
+	useTestStore(t)
+	ctx := t.Context()
+
+	steps := []struct {
+		name    string
+		write   func() error
+		wantErr error
+		movie   string
+		want    map[string]float64
+	}{
+		{
+			name:  "初始数据计入旧版评分行",
+			write: func() error { return nil },
+			movie: "1",
+			want:  map[string]float64{"1": 4, "2": 3.5, "9": 2},
+		},
+		{
+			name:  "新增评分",
+			write: func() error { _, err := CreateRating(ctx, "1", "3", 5); return err },
+			movie: "1",
+			want:  map[string]float64{"1": 4, "2": 3.5, "3": 5, "9": 2},
+		},
+		{
+			name:    "重复新增",
+			write:   func() error { _, err := CreateRating(ctx, "1", "3", 4); return err },
+			wantErr: ErrRatingExists,
+			movie:   "1",
+			want:    map[string]float64{"1": 4, "2": 3.5, "3": 5, "9": 2},
+		},
+		{
+			name:    "旧版评分行视为已评分",
+			write:   func() error { _, err := CreateRating(ctx, "1", "9", 4); return err },
+			wantErr: ErrRatingExists,
+			movie:   "1",
+			want:    map[string]float64{"1": 4, "2": 3.5, "3": 5, "9": 2},
+		},
+		{
+			name:  "修改旧版评分",
+			write: func() error { _, err := UpdateRating(ctx, "1", "9", 3); return err },
+			movie: "1",
+			want:  map[string]float64{"1": 4, "2": 3.5, "3": 5, "9": 3},
+		},
+		{
+			name:  "修改评分",
+			write: func() error { _, err := UpdateRating(ctx, "1", "1", 1.5); return err },
+			movie: "1",
+			want:  map[string]float64{"1": 1.5, "2": 3.5, "3": 5, "9": 3},
+		},
+		{
+			name:  "存储为整数的评分可修改",
+			write: func() error { _, err := UpdateRating(ctx, "3", "3", 2); return err },
+			movie: "3",
+			want:  map[string]float64{"3": 2},
+		},
+		{
+			name:    "修改未评分的电影",
+			write:   func() error { _, err := UpdateRating(ctx, "10", "2", 3); return err },
+			wantErr: ErrRatingNotFound,
+			movie:   "10",
+			want:    map[string]float64{"1": 3},
+		},
+		{
+			name:  "删除只有旧版评分行的评分",
+			write: func() error { return DeleteRating(ctx, "2", "8") },
+			movie: "2",
+			want:  map[string]float64{},
+		},
+		{
+			name:    "重复删除",
+			write:   func() error { return DeleteRating(ctx, "2", "8") },
+			wantErr: ErrRatingNotFound,
+			movie:   "2",
+			want:    map[string]float64{},
+		},
+		{
+			name:  "删除评分",
+			write: func() error { return DeleteRating(ctx, "1", "2") },
+			movie: "1",
+			want:  map[string]float64{"1": 1.5, "3": 5, "9": 3},
+		},
+		{
+			name:    "电影不存在",
+			write:   func() error { _, err := CreateRating(ctx, "404", "1", 3); return err },
+			wantErr: ErrMovieNotFound,
+			movie:   "1",
+			want:    map[string]float64{"1": 1.5, "3": 5, "9": 3},
+		},
+		{
+			name:    "旧版评分行不是电影",
+			write:   func() error { _, err := CreateRating(ctx, "1_9", "1", 3); return err },
+			wantErr: ErrMovieNotFound,
+			movie:   "1",
+			want:    map[string]float64{"1": 1.5, "3": 5, "9": 3},
+		},
+		{
+			name:    "无效评分",
+			write:   func() error { _, err := CreateRating(ctx, "10", "5", 4.2); return err },
+			wantErr: ErrInvalidRating,
+			movie:   "10",
+			want:    map[string]float64{"1": 3},
+		},
+	}
+
+	for _, step := range steps {
+		// 每一步之前先读取一次评分列表，确认写入后缓存被清除
+		movieRatings(t, step.movie)
+
+		if err := step.write(); !errors.Is(err, step.wantErr) {
+			t.Fatalf("%s: error = %v, want %v", step.name, err, step.wantErr)
+		}
+		if got := movieRatings(t, step.movie); !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%s: ratings = %v, want %v", step.name, got, step.want)
+		}
+	}
+
+	// 评分聚合与评分列表一致
+	aggregate, err := utils.GetRatingAggregate(ctx, "1")
+	if err != nil {
+		t.Fatalf("GetRatingAggregate error: %v", err)
+	}
+	if aggregate.Count != 3 || aggregate.Sum != 9.5 || aggregate.Min != 1.5 || aggregate.Max != 5 {
+		t.Errorf("aggregate = count %d sum %v min %v max %v, want 3 9.5 1.5 5",
+			aggregate.Count, aggregate.Sum, aggregate.Min, aggregate.Max)
+	}
+}
+
+func TestRatingWriteInvalidatesMovieDetail(t *testing.T) {
+	ctx := useTestStore(t)
+
+	before, err := GetMovieByID(ctx, "10")
+	if err != nil || before == nil {
+		t.Fatalf("GetMovieByID error: %v", err)
+	}
+	if _, err := CreateRating(ctx, "10", "7", 5); err != nil {
+		t.Fatalf("CreateRating error: %v", err)
+	}
+
+	after, err := GetMovieByID(ctx, "10")
+	if err != nil {
+		t.Fatalf("GetMovieByID error: %v", err)
+	}
+	if after.Movie.AvgRating != 4 {
+		t.Errorf("AvgRating after rating = %v, want 4 (cached detail not invalidated?)", after.Movie.AvgRating)
+	}
+}
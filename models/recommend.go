@@ -0,0 +1,138 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"gohbase/utils"
+	"gohbase/utils/recommend"
+	"gohbase/utils/search"
+	"time"
+)
+
+// 推荐来源
+const (
+	RecommendSourceCollaborative = "collaborative" // 基于物品的协同过滤
+	RecommendSourceTopRated      = "top_rated"     // 冷启动时退回加权评分排行
+)
+
+// RecommendedMovie 推荐的电影，PredictedRating 为协同过滤预测的评分，退回排行时省略
+type RecommendedMovie struct {
+	Movie
+	PredictedRating float64 `json:"predictedRating,omitempty"`
+}
+
+// Recommendations 为用户推荐的电影，BuiltAt 为所用模型或排行的计算时间
+type Recommendations struct {
+	UserID  string             `json:"userId"`
+	Source  string             `json:"source"`
+	BuiltAt time.Time          `json:"builtAt"`
+	Movies  []RecommendedMovie `json:"movies"`
+}
+
+// GetRecommendations 为用户推荐 limit 部电影（带缓存），excludeRated 为 true 时排除用户已评分的电影
+// 优先使用后台训练的协同过滤模型，结合用户评分表中用户当前的评分预测；模型未就绪、用户没有评分（冷启动）或没有可推荐的电影时退回加权评分排行
+// 缓存随模型重新训练及用户评分变化失效
+func GetRecommendations(ctx context.Context, userID string, limit int, excludeRated bool) (*Recommendations, error) {
+	model := utils.RecommendModel()
+	if model != nil {
+		cacheKey := fmt.Sprintf("recommend:%s:%d:%d:%t", userID, model.BuiltAt.UnixNano(), limit, excludeRated)
+		cachedData, err := loadCached(ctx, cacheKey, func(ctx context.Context) (interface{}, error) {
+			ratings, err := loadUserRatingMap(ctx, userID)
+			if err != nil {
+				return nil, err
+			}
+			return collaborativeRecommendations(ctx, model, userID, ratings, limit, excludeRated)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if recommendations := cachedData.(*Recommendations); len(recommendations.Movies) > 0 {
+			return recommendations, nil
+		}
+	}
+
+	var rated map[string]float64
+	if excludeRated {
+		var err error
+		if rated, err = loadUserRatingMap(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	return topRatedRecommendations(ctx, userID, rated, limit)
+}
+
+// loadUserRatingMap 从用户评分表读取用户当前的评分，键为 movieId
+func loadUserRatingMap(ctx context.Context, userID string) (map[string]float64, error) {
+	userRatings, err := utils.GetUserRatings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ratings := make(map[string]float64, len(userRatings))
+	for _, rating := range userRatings {
+		ratings[rating.MovieID] = rating.Rating
+	}
+	return ratings, nil
+}
+
+// collaborativeRecommendations 根据用户的评分 ratings 使用协同过滤模型生成推荐，没有可推荐的电影时返回空列表
+func collaborativeRecommendations(ctx context.Context, model *recommend.Model, userID string, ratings map[string]float64, limit int, excludeRated bool) (*Recommendations, error) {
+	recommendations := &Recommendations{
+		UserID:  userID,
+		Source:  RecommendSourceCollaborative,
+		BuiltAt: model.BuiltAt,
+		Movies:  []RecommendedMovie{},
+	}
+
+	results := model.Recommend(ratings, limit, excludeRated)
+	if len(results) == 0 {
+		return recommendations, nil
+	}
+
+	// 电影信息和评分取自搜索索引，已不在索引中的电影被跳过
+	docs := make([]*search.Document, 0, len(results))
+	predicted := make([]float64, 0, len(results))
+	for _, result := range results {
+		if doc := utils.IndexedDocument(result.MovieID); doc != nil {
+			docs = append(docs, doc)
+			predicted = append(predicted, result.Predicted)
+		}
+	}
+
+	movies, err := moviesFromDocuments(ctx, docs)
+	if err != nil {
+		return nil, err
+	}
+	for i, movie := range movies {
+		recommendations.Movies = append(recommendations.Movies, RecommendedMovie{
+			Movie:           movie,
+			PredictedRating: roundRating(predicted[i]),
+		})
+	}
+	return recommendations, nil
+}
+
+// topRatedRecommendations 退回加权评分排行，排除 rated 中的电影（键为 movieId，可以为 nil）
+func topRatedRecommendations(ctx context.Context, userID string, rated map[string]float64, limit int) (*Recommendations, error) {
+	chart, err := GetTopChart(ctx, "", 0, limit+len(rated))
+	if err != nil {
+		return nil, err
+	}
+
+	recommendations := &Recommendations{
+		UserID:  userID,
+		Source:  RecommendSourceTopRated,
+		BuiltAt: chart.BuiltAt,
+		Movies:  []RecommendedMovie{},
+	}
+	for _, movie := range chart.Movies {
+		if len(recommendations.Movies) >= limit {
+			break
+		}
+		if _, ok := rated[movie.MovieID]; ok {
+			continue
+		}
+		recommendations.Movies = append(recommendations.Movies, RecommendedMovie{Movie: movie})
+	}
+	return recommendations, nil
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gohbase/utils"
+	"gohbase/utils/search"
+	"time"
+)
+
+// ErrSimilarNotReady 相似电影尚未计算完成
+var ErrSimilarNotReady = errors.New("相似电影尚未计算完成，请稍后重试")
+
+// SimilarMovie 相似电影，Similarity 为基于类型和标签的相似度（0~1，按评分接近程度加权后可能略大于1）
+type SimilarMovie struct {
+	Movie
+	Similarity float64 `json:"similarity"`
+}
+
+// SimilarMovies 与某部电影相似的电影
+type SimilarMovies struct {
+	MovieID string         `json:"movieId"`
+	BuiltAt time.Time      `json:"builtAt"`
+	Movies  []SimilarMovie `json:"movies"`
+}
+
+// GetSimilarMovies 获取与 movieID 最相似的 limit 部电影（带缓存），相似电影由后台任务预先计算，缓存随每次重算失效
+// 电影不存在时返回 ErrMovieNotFound；电影在上次计算之后才加入时返回空列表
+func GetSimilarMovies(ctx context.Context, movieID string, limit int) (*SimilarMovies, error) {
+	if utils.SimilarEngine == nil {
+		return nil, ErrSimilarNotReady
+	}
+	snapshot := utils.SimilarEngine.Snapshot()
+	if snapshot == nil {
+		return nil, ErrSimilarNotReady
+	}
+
+	cacheKey := fmt.Sprintf("similar:%d:%s:%d", snapshot.BuiltAt.UnixNano(), movieID, limit)
+	cachedData, err := loadCached(ctx, cacheKey, func(ctx context.Context) (interface{}, error) {
+		neighbors, ok := snapshot.Similar(movieID, limit)
+		if !ok {
+			data, err := utils.GetMovieWithFamilies(ctx, movieID, []string{"movie"})
+			if err != nil {
+				return nil, err
+			}
+			if data == nil {
+				return nil, ErrMovieNotFound
+			}
+		}
+
+		docs := make([]*search.Document, 0, len(neighbors))
+		for _, neighbor := range neighbors {
+			docs = append(docs, neighbor.Document)
+		}
+
+		movies, err := moviesFromDocuments(ctx, docs)
+		if err != nil {
+			return nil, err
+		}
+		similar := make([]SimilarMovie, len(neighbors))
+		for i, neighbor := range neighbors {
+			similar[i] = SimilarMovie{
+				Movie:      movies[i],
+				Similarity: roundRating(neighbor.Score),
+			}
+		}
+
+		return &SimilarMovies{
+			MovieID: movieID,
+			BuiltAt: snapshot.BuiltAt,
+			Movies:  similar,
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cachedData.(*SimilarMovies), nil
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gohbase/utils"
+	"gohbase/utils/store"
+	"gohbase/utils/tags"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 标签审核操作
+const (
+	TagActionApprove = "approve" // 通过：标签保留，移出待审核队列
+	TagActionRemove  = "remove"  // 删除：删除标签，移出待审核队列
+)
+
+// 标签审核相关错误
+var (
+	ErrInvalidTagAction   = errors.New("action 须为 approve 或 remove")
+	ErrPendingTagNotFound = errors.New("该标签不在待审核队列中")
+	ErrPendingTagChanged  = errors.New("用户已修改该标签，请刷新待审核队列后重试")
+)
+
+// GetTagQueue 获取待审核标签队列中最早提交的 limit 个标签，多读一个判断是否还有更多，不使用缓存
+func GetTagQueue(ctx context.Context, limit int) (*TagQueue, error) {
+	pending, err := utils.ListPendingTags(ctx, int64(limit)+1)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := &TagQueue{HasMore: len(pending) > limit, Tags: []PendingTag{}}
+	if len(pending) > limit {
+		pending = pending[:limit]
+	}
+	if len(pending) == 0 {
+		return queue, nil
+	}
+
+	// 批量读取电影标题，便于审核
+	movieIDs := make([]string, 0, len(pending))
+	for _, tag := range pending {
+		movieIDs = append(movieIDs, tag.MovieID)
+	}
+	data, err := utils.GetMoviesMultiple(ctx, store.UnionIDs(movieIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tag := range pending {
+		entry := PendingTag{
+			MovieTag: MovieTag{MovieID: tag.MovieID, UserID: tag.UserID, Tag: tag.Tag, Timestamp: tag.Timestamp},
+		}
+		if row, ok := data[tag.MovieID]; ok {
+			entry.Title = string(row["movie"]["title"])
+		}
+		queue.Tags = append(queue.Tags, entry)
+	}
+	return queue, nil
+}
+
+// ModerateTag 审核用户给电影打的标签，tag 为审核时看到的标签，与队列中的不一致时返回 ErrPendingTagChanged
+// approve 保留标签；remove 删除标签（用户已自行删除或电影已不存在时只移出队列）
+func ModerateTag(ctx context.Context, movieID, userID, tag, action string) error {
+	if action != TagActionApprove && action != TagActionRemove {
+		return ErrInvalidTagAction
+	}
+
+	pending, err := utils.GetPendingTag(ctx, movieID, userID)
+	if err != nil {
+		return err
+	}
+	if pending == nil {
+		return ErrPendingTagNotFound
+	}
+	if tags.Normalize(tag) != pending.Tag {
+		return ErrPendingTagChanged
+	}
+
+	if action == TagActionRemove {
+		existing, err := getExistingTag(ctx, movieID, userID)
+		if err != nil && !errors.Is(err, ErrMovieNotFound) {
+			return err
+		}
+		if existing == pending.Tag {
+			if err := utils.DeleteTag(ctx, movieID, userID); err != nil {
+				return fmt.Errorf("删除标签失败: %w", err)
+			}
+			updateTagIndex(ctx, movieID)
+			InvalidateTagCache(movieID, userID)
+		}
+	}
+
+	if err := utils.DeletePendingTag(ctx, movieID, userID); err != nil {
+		return fmt.Errorf("移出待审核队列失败: %w", err)
+	}
+	logrus.Infof("审核电影 %s 用户 %s 的标签 %q: %s", movieID, userID, pending.Tag, action)
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gohbase/utils"
+	"gohbase/utils/store"
+	"gohbase/utils/tags"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 标签写入相关错误
+var (
+	ErrTagEmpty    = tags.ErrEmpty
+	ErrTagTooLong  = tags.ErrTooLong
+	ErrTagBlocked  = tags.ErrBlocked
+	ErrTagExists   = errors.New("用户已给该电影打过标签，需先删除")
+	ErrTagNotFound = errors.New("用户没有给该电影打这个标签")
+)
+
+// AddTag 为用户给电影打标签，标签经规范化（小写、合并空白）并通过长度与屏蔽词校验后立即生效，同时加入待审核队列
+// 每个用户对每部电影最多一个标签，已有标签时返回 ErrTagExists
+func AddTag(ctx context.Context, movieID, userID, tag string) (*MovieTag, error) {
+	normalized, err := utils.TagPolicy.Check(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := getExistingTag(ctx, movieID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != "" {
+		return nil, ErrTagExists
+	}
+
+	// 先加入待审核队列再写入标签，保证每个生效的新标签都会经过审核
+	// 两步都只在没有旧记录时写入，并发提交的请求中只有一个成功，其余返回 ErrTagExists
+	pending := store.PendingTag{MovieID: movieID, UserID: userID, Tag: normalized, Timestamp: time.Now().Unix()}
+	queued, err := utils.PutPendingTag(ctx, pending)
+	if err != nil {
+		return nil, fmt.Errorf("加入待审核队列失败: %w", err)
+	}
+	if !queued {
+		return nil, ErrTagExists
+	}
+
+	written, err := utils.PutTag(ctx, movieID, userID, normalized)
+	if err != nil || !written {
+		if err := utils.DeletePendingTag(ctx, movieID, userID); err != nil {
+			logrus.Errorf("移除电影 %s 用户 %s 的待审核标签失败: %v", movieID, userID, err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("写入标签失败: %w", err)
+		}
+		return nil, ErrTagExists
+	}
+
+	updateTagIndex(ctx, movieID)
+	InvalidateTagCache(movieID, userID)
+	logrus.Infof("用户 %s 给电影 %s 打了标签 %q，等待审核", userID, movieID, normalized)
+
+	return &MovieTag{
+		MovieID:   movieID,
+		UserID:    userID,
+		Tag:       normalized,
+		Timestamp: pending.Timestamp,
+	}, nil
+}
+
+// RemoveTag 删除用户给电影打的标签，tag 按同样的规则规范化后与用户当前的标签比较，不一致时返回 ErrTagNotFound
+func RemoveTag(ctx context.Context, movieID, userID, tag string) error {
+	existing, err := getExistingTag(ctx, movieID, userID)
+	if err != nil {
+		return err
+	}
+	if existing == "" || tags.Normalize(existing) != tags.Normalize(tag) {
+		return ErrTagNotFound
+	}
+
+	if err := utils.DeleteTag(ctx, movieID, userID); err != nil {
+		return fmt.Errorf("删除标签失败: %w", err)
+	}
+	if err := utils.DeletePendingTag(ctx, movieID, userID); err != nil {
+		logrus.Errorf("移除电影 %s 用户 %s 的待审核标签失败: %v", movieID, userID, err)
+	}
+
+	updateTagIndex(ctx, movieID)
+	InvalidateTagCache(movieID, userID)
+	logrus.Infof("用户 %s 删除了电影 %s 的标签 %q", userID, movieID, existing)
+
+	return nil
+}
+
+// InvalidateTagCache 清除包含指定电影标签的缓存
+func InvalidateTagCache(movieID, userID string) {
+	utils.Cache.Delete(fmt.Sprintf("movie_detail:%s", movieID))
+	utils.Cache.Delete(fmt.Sprintf("user_profile:%s", userID))
+	utils.Cache.DeletePrefix("search:")
+	utils.Cache.DeletePrefix("random_movies:")
+}
+
+// getExistingTag 确认电影存在并返回用户当前的标签，没有标签时返回空字符串
+func getExistingTag(ctx context.Context, movieID, userID string) (string, error) {
+	data, err := utils.GetMovieWithFamilies(ctx, movieID, []string{"movie", store.TagFamily})
+	if err != nil {
+		return "", err
+	}
+	if data == nil {
+		return "", ErrMovieNotFound
+	}
+
+	return strings.TrimSpace(string(data[store.TagFamily][store.TagColumn(userID)])), nil
+}
+
+// updateTagIndex 同步电影的标签索引条目（标签和用户标签），失败时只记录日志，可通过重建命令修复
+func updateTagIndex(ctx context.Context, movieID string) {
+	if err := utils.UpdateMovieIndex(ctx, movieID); err != nil {
+		logrus.Errorf("更新电影 %s 的二级索引失败，请执行 rebuild-index 重建: %v", movieID, err)
+	}
+}
@@ -1,7 +1,10 @@
 package models
 
 import (
+	"gohbase/utils/search"
 	"math/rand"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -10,13 +13,36 @@ var rng = rand.New(rand.NewSource(time.Now().UnixNano()))
 
 // Movie 电影模型
 type Movie struct {
-	MovieID   string   `json:"movieId"`
-	Title     string   `json:"title"`
-	Genres    []string `json:"genres"`
-	Year      int      `json:"year,omitempty"`
-	AvgRating float64  `json:"avgRating"`
-	Links     Links    `json:"links,omitempty"`
-	Tags      []string `json:"tags,omitempty"`
+	MovieID        string   `json:"movieId"`
+	Title          string   `json:"title"`
+	LocalizedTitle string   `json:"localizedTitle,omitempty"` // 中文标题
+	Genres         []string `json:"genres"`
+	Year           int      `json:"year,omitempty"`
+	AvgRating      float64  `json:"avgRating"`
+	Links          Links    `json:"links,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	Score          float64  `json:"score,omitempty"` // 搜索相关度得分，仅出现在搜索结果中
+	// WeightedRating 贝叶斯加权评分，按评分人数向全库平均分收缩，没有评分时省略
+	WeightedRating float64 `json:"weightedRating,omitempty"`
+}
+
+// parseTitleYear 从 "Toy Story (1995)" 格式的标题中提取年份，无法识别时返回0
+func parseTitleYear(title string) int {
+	if matches := strings.Split(title, " ("); len(matches) > 1 {
+		yearStr := strings.TrimSuffix(matches[len(matches)-1], ")")
+		if year, err := strconv.Atoi(yearStr); err == nil {
+			return year
+		}
+	}
+	return 0
+}
+
+// Suggestion 标题补全建议
+type Suggestion struct {
+	MovieID        string `json:"movieId"`
+	Title          string `json:"title"`
+	LocalizedTitle string `json:"localizedTitle,omitempty"`
+	Year           int    `json:"year,omitempty"`
 }
 
 // Links 外部链接
@@ -34,6 +60,61 @@ type MovieList struct {
 	Page        int     `json:"page"`
 	PerPage     int     `json:"perPage"`
 	TotalPages  int     `json:"totalPages"`
+	// Facets 全部匹配结果（而非当前页）在各分面上的计数，仅在请求时返回
+	Facets search.Facets `json:"facets,omitempty"`
+	// NextCursor / PrevCursor 下一页、上一页的游标，没有更多数据时为空
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// RatingList 电影评分列表响应，Count 及平均、最低、最高分统计全部评分，Total 为满足筛选条件的评分数量
+type RatingList struct {
+	Ratings    []Rating `json:"ratings"`
+	Count      int      `json:"count"`
+	AvgRating  float64  `json:"avgRating"`
+	MinRating  float64  `json:"minRating"`
+	MaxRating  float64  `json:"maxRating"`
+	Total      int      `json:"total"`
+	Page       int      `json:"page"`
+	PerPage    int      `json:"perPage"`
+	TotalPages int      `json:"totalPages"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+	PrevCursor string   `json:"prev_cursor,omitempty"`
+}
+
+// HistogramBucket 评分直方图中的一档
+type HistogramBucket struct {
+	Rating float64 `json:"rating"`
+	Count  int     `json:"count"`
+}
+
+// RatingDistribution 电影评分分布统计，时间为Unix秒
+type RatingDistribution struct {
+	MovieID      string             `json:"movieId"`
+	Count        int                `json:"count"`
+	AvgRating    float64            `json:"avgRating"`
+	MinRating    float64            `json:"minRating"`
+	MaxRating    float64            `json:"maxRating"`
+	Median       float64            `json:"median"`
+	StdDev       float64            `json:"stdDev"`
+	Percentiles  map[string]float64 `json:"percentiles"`
+	Histogram    []HistogramBucket  `json:"histogram"`
+	FirstRatedAt int64              `json:"firstRatedAt,omitempty"`
+	LastRatedAt  int64              `json:"lastRatedAt,omitempty"`
+}
+
+// TimelineBucket 评分时间线中的一个时间段，Start 为时间段起点（UTC）
+type TimelineBucket struct {
+	Start     time.Time `json:"start"`
+	Count     int64     `json:"count"`
+	AvgRating float64   `json:"avgRating"`
+}
+
+// RatingTimeline 电影评分数量与平均分随时间的变化，只包含有评分的时间段
+type RatingTimeline struct {
+	MovieID string           `json:"movieId"`
+	Bucket  string           `json:"bucket"`
+	Buckets []TimelineBucket `json:"buckets"`
 }
 
 // MovieDetail 电影详情响应
@@ -46,6 +127,27 @@ type MovieDetail struct {
 
 // Rating 评分
 type Rating struct {
-	UserID string  `json:"userId"`
-	Rating float64 `json:"rating"`
+	UserID    string  `json:"userId"`
+	Rating    float64 `json:"rating"`
+	Timestamp int64   `json:"timestamp,omitempty"`
+}
+
+// MovieTag 用户对电影打的标签
+type MovieTag struct {
+	MovieID   string `json:"movieId"`
+	UserID    string `json:"userId"`
+	Tag       string `json:"tag"`
+	Timestamp int64  `json:"timestamp,omitempty"`
+}
+
+// PendingTag 待审核的标签，Title 为电影标题，电影已不存在时为空
+type PendingTag struct {
+	MovieTag
+	Title string `json:"title,omitempty"`
+}
+
+// TagQueue 待审核标签队列，按提交时间升序，HasMore 表示队列中还有更晚提交的标签
+type TagQueue struct {
+	HasMore bool         `json:"hasMore"`
+	Tags    []PendingTag `json:"tags"`
 }
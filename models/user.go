@@ -0,0 +1,243 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gohbase/utils"
+	"gohbase/utils/store"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrUserNotFound 用户没有任何评分或标签
+var ErrUserNotFound = errors.New("用户不存在")
+
+// favoriteGenresLimit 用户资料中返回的最喜欢类型数量
+const favoriteGenresLimit = 10
+
+// userRatingsScope 用户评分历史游标的作用域，按评分时间倒序
+const userRatingsScope = "user_ratings"
+
+// UserRatingEntry 用户评分历史中的一条
+type UserRatingEntry struct {
+	MovieID        string  `json:"movieId"`
+	Title          string  `json:"title"`
+	LocalizedTitle string  `json:"localizedTitle,omitempty"`
+	Year           int     `json:"year,omitempty"`
+	Rating         float64 `json:"rating"`
+	Timestamp      int64   `json:"timestamp,omitempty"`
+}
+
+// GenrePreference 用户对某个类型的偏好，Score 为该类型电影的评分之和占用户全部评分之和的比例
+type GenrePreference struct {
+	Genre     string  `json:"genre"`
+	Count     int     `json:"count"`
+	AvgRating float64 `json:"avgRating"`
+	Score     float64 `json:"score"`
+}
+
+// UserTag 用户打过的标签及打了该标签的电影
+type UserTag struct {
+	Tag      string   `json:"tag"`
+	Count    int      `json:"count"`
+	MovieIDs []string `json:"movieIds"`
+}
+
+// UserProfile 用户资料：评分统计、最喜欢的类型、打过的标签及分页的评分历史（按评分时间倒序）
+type UserProfile struct {
+	UserID         string            `json:"userId"`
+	RatingCount    int               `json:"ratingCount"`
+	AvgRating      float64           `json:"avgRating"`
+	FavoriteGenres []GenrePreference `json:"favoriteGenres"`
+	Tags           []UserTag         `json:"tags"`
+	Ratings        []UserRatingEntry `json:"ratings"`
+	Page           int               `json:"page"`
+	PerPage        int               `json:"perPage"`
+	TotalPages     int               `json:"totalPages"`
+	NextCursor     string            `json:"next_cursor,omitempty"`
+	PrevCursor     string            `json:"prev_cursor,omitempty"`
+}
+
+// userActivity 用户的全部评分与标签，评分按时间倒序，缓存后用于分页
+type userActivity struct {
+	ratings []UserRatingEntry
+	avg     float64
+	genres  []GenrePreference
+	tags    []UserTag
+}
+
+// GetUserProfile 获取用户资料，评分历史按 request 分页；用户没有任何评分或标签时返回 ErrUserNotFound
+func GetUserProfile(ctx context.Context, userID string, request PageRequest) (*UserProfile, error) {
+	if err := request.checkScope(userRatingsScope); err != nil {
+		return nil, err
+	}
+	var anchor UserRatingEntry
+	if request.Cursor != nil {
+		timestamp, err := strconv.ParseInt(request.Cursor.Value, 10, 64)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		anchor = UserRatingEntry{MovieID: request.Cursor.Key, Timestamp: timestamp}
+	}
+
+	cachedData, err := loadCached(ctx, fmt.Sprintf("user_profile:%s", userID), func(ctx context.Context) (interface{}, error) {
+		return loadUserActivity(ctx, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	activity := cachedData.(*userActivity)
+
+	total := len(activity.ratings)
+	startIdx, endIdx := request.window(total, func(i int) int {
+		return compareUserRatings(activity.ratings[i], anchor)
+	})
+
+	profile := &UserProfile{
+		UserID:         userID,
+		RatingCount:    total,
+		AvgRating:      activity.avg,
+		FavoriteGenres: activity.genres,
+		Tags:           activity.tags,
+		Ratings:        activity.ratings[startIdx:endIdx],
+		PerPage:        request.PerPage,
+		TotalPages:     (total + request.PerPage - 1) / request.PerPage,
+	}
+	if request.Cursor == nil {
+		profile.Page = request.Page
+	}
+	profile.NextCursor, profile.PrevCursor = pageCursors(startIdx, endIdx, total, func(i int) Cursor {
+		entry := activity.ratings[i]
+		return Cursor{Scope: userRatingsScope, Key: entry.MovieID, Value: strconv.FormatInt(entry.Timestamp, 10)}
+	})
+
+	return profile, nil
+}
+
+// compareUserRatings 比较两条评分在历史中的先后：评分时间倒序，相同时按 movieId 升序
+func compareUserRatings(a, b UserRatingEntry) int {
+	if cmp := compareFloat(float64(b.Timestamp), float64(a.Timestamp)); cmp != 0 {
+		return cmp
+	}
+	return strings.Compare(a.MovieID, b.MovieID)
+}
+
+// loadUserActivity 从用户评分表读取用户的评分，通过二级索引找到用户打过标签的电影，再批量读取这些电影计算用户资料
+func loadUserActivity(ctx context.Context, userID string) (*userActivity, error) {
+	userRatings, err := utils.GetUserRatings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	tagged, err := utils.GetUserTaggedMovies(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ratings := make(map[string]store.MovieRating, len(userRatings))
+	rated := make([]string, 0, len(userRatings))
+	for _, rating := range userRatings {
+		ratings[rating.MovieID] = rating
+		rated = append(rated, rating.MovieID)
+	}
+	movieIDs := store.UnionIDs(rated, tagged)
+	if len(movieIDs) == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	data, err := utils.GetMoviesMultiple(ctx, movieIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	activity := &userActivity{ratings: []UserRatingEntry{}}
+	genreSums := make(map[string]float64)
+	genreCounts := make(map[string]int)
+	tags := make(map[string]*UserTag)
+	var total float64
+
+	for _, movieID := range movieIDs {
+		row, ok := data[movieID]
+		if !ok {
+			continue
+		}
+		movieData := utils.ParseMovieData(movieID, row)
+
+		if value, ok := row["tag"]["tag:"+userID]; ok && strings.TrimSpace(string(value)) != "" {
+			tag := string(value)
+			if tags[tag] == nil {
+				tags[tag] = &UserTag{Tag: tag, MovieIDs: []string{}}
+			}
+			tags[tag].Count++
+			tags[tag].MovieIDs = append(tags[tag].MovieIDs, movieID)
+		}
+
+		rating, ok := ratings[movieID]
+		if !ok {
+			continue
+		}
+
+		entry := UserRatingEntry{MovieID: movieID, Rating: rating.Rating, Timestamp: rating.Timestamp}
+		if title, ok := movieData["title"].(string); ok {
+			entry.Title = title
+			entry.Year = parseTitleYear(title)
+		}
+		if localizedTitle, ok := movieData["localizedTitle"].(string); ok {
+			entry.LocalizedTitle = localizedTitle
+		}
+		activity.ratings = append(activity.ratings, entry)
+		total += rating.Rating
+
+		if genres, ok := movieData["genres"].([]string); ok {
+			for _, genre := range genres {
+				if genre == "" || genre == "(no genres listed)" {
+					continue
+				}
+				genreSums[genre] += rating.Rating
+				genreCounts[genre]++
+			}
+		}
+	}
+
+	sort.Slice(activity.ratings, func(i, j int) bool {
+		return compareUserRatings(activity.ratings[i], activity.ratings[j]) < 0
+	})
+	if len(activity.ratings) > 0 {
+		activity.avg = roundRating(total / float64(len(activity.ratings)))
+	}
+
+	activity.genres = []GenrePreference{}
+	for genre, sum := range genreSums {
+		activity.genres = append(activity.genres, GenrePreference{
+			Genre:     genre,
+			Count:     genreCounts[genre],
+			AvgRating: roundRating(sum / float64(genreCounts[genre])),
+			Score:     roundRating(sum / total),
+		})
+	}
+	sort.Slice(activity.genres, func(i, j int) bool {
+		a, b := activity.genres[i], activity.genres[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Genre < b.Genre
+	})
+	if len(activity.genres) > favoriteGenresLimit {
+		activity.genres = activity.genres[:favoriteGenresLimit]
+	}
+
+	activity.tags = []UserTag{}
+	for _, tag := range tags {
+		activity.tags = append(activity.tags, *tag)
+	}
+	sort.Slice(activity.tags, func(i, j int) bool {
+		a, b := activity.tags[i], activity.tags[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Tag < b.Tag
+	})
+
+	return activity, nil
+}
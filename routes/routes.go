@@ -2,14 +2,17 @@ package routes
 
 import (
 	"gohbase/controllers"
+	"gohbase/utils/metrics"
+	"gohbase/utils/tracing"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-// SetupRouter 设置路由
-func SetupRouter() *gin.Engine {
+// SetupRouter 设置路由，adminToken 为管理接口的访问令牌
+func SetupRouter(adminToken string) *gin.Engine {
 	// 创建默认路由
 	router := gin.Default()
 
@@ -17,32 +20,72 @@ func SetupRouter() *gin.Engine {
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Cache-Check", "X-Requested-With"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Cache-Check", "X-Requested-With", "X-User-ID"},
 		ExposeHeaders:    []string{"Content-Length", "X-Cache-Hit"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 
+	// 为每个请求创建追踪 span，并记录请求数量和耗时
+	router.Use(tracing.GinMiddleware())
+	router.Use(metrics.GinMiddleware())
+
+	// Prometheus 指标
+	// GET /metrics - HTTP请求、缓存和HBase调用指标
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
 	// 创建API路由组
 	api := router.Group("/api")
 
 	// 创建控制器实例
 	movieController := &controllers.MovieController{}
+	userController := &controllers.UserController{}
+	adminController := &controllers.AdminController{}
 
 	// 电影相关路由
 	movies := api.Group("/movies")
 	{
 		movies.GET("", movieController.GetMovies)
 		movies.GET("/:id", movieController.GetMovie)
+		movies.GET("/:id/similar", movieController.GetSimilarMovies)
+		movies.POST("/:id/tags", movieController.AddTag)
+		movies.DELETE("/:id/tags/:tag", movieController.RemoveTag)
 		movies.GET("/random", movieController.GetRandomMovies)
 		movies.POST("/random", movieController.RandomMoviesPost)
 		movies.GET("/search", movieController.SearchMovies)
+		movies.GET("/suggest", movieController.SuggestMovies)
 	}
 
 	// 评分相关路由
 	ratings := api.Group("/ratings")
 	{
 		ratings.GET("/movie/:id", movieController.GetMovieRatings)
+		ratings.GET("/movie/:id/stats", movieController.GetRatingStats)
+		ratings.GET("/movie/:id/timeline", movieController.GetRatingTimeline)
+		ratings.POST("/movie/:id", movieController.CreateRating)
+		ratings.PUT("/movie/:id", movieController.UpdateRating)
+		ratings.DELETE("/movie/:id", movieController.DeleteRating)
+	}
+
+	// 排行榜路由
+	charts := api.Group("/charts")
+	{
+		charts.GET("/top", movieController.GetTopChart)
+		charts.GET("/trending", movieController.GetTrendingChart)
+	}
+
+	// 用户相关路由
+	users := api.Group("/users")
+	{
+		users.GET("/:id", userController.GetUserProfile)
+		users.GET("/:id/recommendations", userController.GetRecommendations)
+	}
+
+	// 管理路由，需携带 Authorization: Bearer <ADMIN_TOKEN>
+	admin := api.Group("/admin", controllers.RequireAdmin(adminToken))
+	{
+		admin.GET("/tags/queue", adminController.GetTagQueue)
+		admin.POST("/tags/queue/:id/:userId", adminController.ModerateTag)
 	}
 
 	// 系统日志路由
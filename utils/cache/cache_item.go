@@ -6,8 +6,9 @@ import (
 
 // CacheItem 缓存项结构
 type CacheItem struct {
-	Value      interface{}
-	Expiration int64
+	Value           interface{}
+	Expiration      int64 // 过期时间（纳秒时间戳），0 表示永不过期
+	StaleExpiration int64 // 过期后仍可作为旧值返回的截止时间，不大于 Expiration 时表示不允许返回旧值
 }
 
 // Expired 判断缓存项是否已过期
@@ -17,3 +18,13 @@ func (item CacheItem) Expired() bool {
 	}
 	return time.Now().UnixNano() > item.Expiration
 }
+
+// Stale 判断缓存项是否已过期但仍处于可返回旧值的窗口内
+func (item CacheItem) Stale() bool {
+	return item.Expired() && time.Now().UnixNano() <= item.StaleExpiration
+}
+
+// Dead 判断缓存项是否已超出旧值窗口，可以被清理
+func (item CacheItem) Dead(now int64) bool {
+	return item.Expiration > 0 && now > item.Expiration && now > item.StaleExpiration
+}
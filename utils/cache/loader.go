@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Loader 缓存未命中时加载数据的函数
+type Loader func() (interface{}, error)
+
+// loadCall 某个键正在进行中的加载
+type loadCall struct {
+	wg         sync.WaitGroup
+	value      interface{}
+	err        error
+	generation uint64 // 开始加载时键的代数
+}
+
+// GetOrLoad 获取缓存项，未命中时调用 loader 加载并按键前缀对应的过期时间写入缓存
+// 同一个键同时只有一个 loader 在执行，其余调用方等待并共享其结果，避免缓存失效瞬间的并发击穿
+// 缓存项过期但仍在旧值窗口内时直接返回旧值，并在后台执行一次 loader 刷新
+// loader 返回错误或 nil 时不写入缓存；加载期间该键被 Delete/DeletePrefix/Flush 时，结果只返回给调用方而不写入缓存
+func (c *MemoryCache) GetOrLoad(key string, loader Loader) (interface{}, error) {
+	return c.GetOrLoadWithExpiration(key, 0, loader)
+}
+
+// GetOrLoadWithExpiration 与 GetOrLoad 相同，但使用指定的过期时间写入缓存
+func (c *MemoryCache) GetOrLoadWithExpiration(key string, duration time.Duration, loader Loader) (interface{}, error) {
+	value, found, stale := c.lookup(key, true)
+	if found {
+		if stale {
+			c.refresh(key, duration, loader)
+		}
+		return value, nil
+	}
+
+	call, leader := c.startLoad(key)
+	if !leader {
+		c.hitCountMu.Lock()
+		c.sharedLoadCount++
+		c.hitCountMu.Unlock()
+
+		call.wg.Wait()
+		return call.value, call.err
+	}
+
+	c.finishLoad(key, duration, call, loader)
+	return call.value, call.err
+}
+
+// refresh 在后台重新加载旧值，同一个键已有加载在进行时不重复发起
+func (c *MemoryCache) refresh(key string, duration time.Duration, loader Loader) {
+	call, leader := c.startLoad(key)
+	if !leader {
+		return
+	}
+
+	go func() {
+		c.finishLoad(key, duration, call, loader)
+		if call.err != nil {
+			logrus.Warnf("后台刷新缓存失败 [键: %s]: %v", key, call.err)
+		}
+	}()
+}
+
+// startLoad 登记键的加载，返回进行中的加载以及调用方是否负责执行
+func (c *MemoryCache) startLoad(key string) (*loadCall, bool) {
+	c.loadMu.Lock()
+	defer c.loadMu.Unlock()
+
+	if call, ok := c.loading[key]; ok {
+		return call, false
+	}
+	call := &loadCall{generation: c.beginGeneration(key)}
+	call.wg.Add(1)
+	c.loading[key] = call
+	return call, true
+}
+
+// beginGeneration 开始跟踪键的代数并返回当前代数，在执行 loader 之前调用
+func (c *MemoryCache) beginGeneration(key string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	generation, ok := c.generations[key]
+	if !ok {
+		c.generations[key] = 0
+	}
+	return generation
+}
+
+// storeLoaded 加载期间键未被删除时写入加载结果，并结束对该键代数的跟踪
+func (c *MemoryCache) storeLoaded(key string, duration time.Duration, call *loadCall) {
+	var item CacheItem
+	var size int64
+	store := call.err == nil && call.value != nil
+	if store {
+		item, size = c.newEntry(key, call.value, duration)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if store && c.generations[key] == call.generation {
+		c.setLocked(key, item, size)
+	}
+	delete(c.generations, key)
+}
+
+// finishLoad 执行 loader、写入缓存并唤醒等待方，加载期间键被删除时结果只返回给等待方
+func (c *MemoryCache) finishLoad(key string, duration time.Duration, call *loadCall, loader Loader) {
+	c.runLoader(call, loader)
+	c.storeLoaded(key, duration, call)
+
+	c.loadMu.Lock()
+	delete(c.loading, key)
+	c.loadMu.Unlock()
+	call.wg.Done()
+
+	c.hitCountMu.Lock()
+	c.loadCount++
+	c.hitCountMu.Unlock()
+}
+
+// runLoader 执行 loader，loader 发生 panic 时转为错误，避免等待方永久阻塞
+func (c *MemoryCache) runLoader(call *loadCall, loader Loader) {
+	defer func() {
+		if r := recover(); r != nil {
+			call.value = nil
+			call.err = fmt.Errorf("缓存加载失败: %v", r)
+		}
+	}()
+	call.value, call.err = loader()
+}
@@ -0,0 +1,175 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrLoad(t *testing.T) {
+	errLoad := errors.New("load failed")
+	tests := []struct {
+		name      string
+		value     interface{}
+		err       error
+		wantCache bool
+	}{
+		{"加载成功后写入缓存", "value", nil, true},
+		{"加载失败不写入缓存", nil, errLoad, false},
+		{"结果为 nil 不写入缓存", nil, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewMemoryCacheWithOptions(Options{DefaultExpiration: time.Minute})
+			value, err := c.GetOrLoad("key", func() (interface{}, error) {
+				return tt.value, tt.err
+			})
+			if value != tt.value || !errors.Is(err, tt.err) {
+				t.Errorf("GetOrLoad = %v, %v, want %v, %v", value, err, tt.value, tt.err)
+			}
+			if _, ok := c.Get("key"); ok != tt.wantCache {
+				t.Errorf("cached = %v, want %v", ok, tt.wantCache)
+			}
+		})
+	}
+}
+
+func TestGetOrLoadPanic(t *testing.T) {
+	c := NewMemoryCacheWithOptions(Options{})
+	_, err := c.GetOrLoad("key", func() (interface{}, error) {
+		panic("boom")
+	})
+	if err == nil {
+		t.Fatal("loader panic should be returned as an error")
+	}
+}
+
+func TestGetOrLoadSharesConcurrentLoads(t *testing.T) {
+	c := NewMemoryCacheWithOptions(Options{DefaultExpiration: time.Minute})
+	var calls int32
+	release := make(chan struct{})
+	loader := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "value", nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = c.GetOrLoad("key", loader)
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("loader called %d times, want 1", calls)
+	}
+	for i, result := range results {
+		if result != "value" {
+			t.Errorf("result %d = %v, want value", i, result)
+		}
+	}
+}
+
+func TestGetOrLoadInvalidatedDuringLoad(t *testing.T) {
+	tests := []struct {
+		name       string
+		invalidate func(c *MemoryCache)
+	}{
+		{"Delete", func(c *MemoryCache) { c.Delete("movie:1") }},
+		{"DeletePrefix", func(c *MemoryCache) { c.DeletePrefix("movie:") }},
+		{"Flush", func(c *MemoryCache) { c.Flush() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewMemoryCacheWithOptions(Options{DefaultExpiration: time.Minute})
+			value, err := c.GetOrLoad("movie:1", func() (interface{}, error) {
+				// 加载期间数据被修改并失效缓存，加载到的是旧值
+				tt.invalidate(c)
+				return "old", nil
+			})
+			if value != "old" || err != nil {
+				t.Errorf("GetOrLoad = %v, %v, want old, nil", value, err)
+			}
+			if cached, ok := c.Get("movie:1"); ok {
+				t.Errorf("stale load result %v should not be cached", cached)
+			}
+
+			// 之后的加载正常写入缓存
+			c.GetOrLoad("movie:1", func() (interface{}, error) { return "new", nil })
+			if cached, ok := c.Get("movie:1"); !ok || cached != "new" {
+				t.Errorf("Get = %v, %v, want new, true", cached, ok)
+			}
+		})
+	}
+}
+
+func TestGetOrLoadUnrelatedDeleteKeepsResult(t *testing.T) {
+	c := NewMemoryCacheWithOptions(Options{DefaultExpiration: time.Minute})
+	c.GetOrLoad("movie:1", func() (interface{}, error) {
+		c.Delete("movie:2")
+		c.DeletePrefix("ratings:")
+		return "value", nil
+	})
+	if _, ok := c.Get("movie:1"); !ok {
+		t.Error("invalidating other keys should not discard the load result")
+	}
+}
+
+func TestGetOrLoadStaleWindow(t *testing.T) {
+	c := NewMemoryCacheWithOptions(Options{
+		DefaultExpiration: 20 * time.Millisecond,
+		StaleWindow:       time.Hour,
+	})
+	c.Set("key", "old")
+	time.Sleep(40 * time.Millisecond)
+
+	if _, ok := c.Get("key"); ok {
+		t.Error("Get should not return a stale value")
+	}
+
+	refreshed := make(chan struct{})
+	value, err := c.GetOrLoad("key", func() (interface{}, error) {
+		defer close(refreshed)
+		return "new", nil
+	})
+	if value != "old" || err != nil {
+		t.Errorf("GetOrLoad = %v, %v, want the stale value old", value, err)
+	}
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("stale value was not refreshed in the background")
+	}
+	// 后台刷新在 loader 返回后才写入缓存
+	deadline := time.Now().Add(time.Second)
+	for {
+		if value, ok := c.Get("key"); ok && value == "new" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("refreshed value was not cached")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestGetOrLoadWithoutStaleWindow(t *testing.T) {
+	c := NewMemoryCacheWithOptions(Options{DefaultExpiration: 20 * time.Millisecond})
+	c.Set("key", "old")
+	time.Sleep(40 * time.Millisecond)
+
+	value, _ := c.GetOrLoad("key", func() (interface{}, error) { return "new", nil })
+	if value != "new" {
+		t.Errorf("GetOrLoad = %v, want new when stale window is disabled", value)
+	}
+}
@@ -1,112 +1,326 @@
 package cache
 
 import (
+	"container/list"
 	"strings"
 	"sync"
 	"time"
 )
 
-// MemoryCache 内存缓存实现
+// entryOverhead 每个缓存项除键和值之外的固定开销估算（链表节点、映射槽位等）
+const entryOverhead = 128
+
+// Options 缓存配置
+type Options struct {
+	DefaultExpiration time.Duration // 默认过期时间
+	CleanupInterval   time.Duration // 过期项清理间隔，0 表示不启动后台清理
+	MaxEntries        int           // 最大缓存项数量，0 表示不限制
+	MaxBytes          int64         // 最大占用字节数（估算值），0 表示不限制
+	// PrefixTTLs 按键前缀（第一个冒号之前的部分）设置的过期时间，未配置的前缀使用 DefaultExpiration
+	PrefixTTLs map[string]time.Duration
+	// StaleWindow 缓存项过期后仍保留的时长，期间 GetOrLoad 直接返回旧值并在后台刷新，0 表示不启用
+	StaleWindow time.Duration
+}
+
+// MemoryCache 内存缓存实现，超出容量时按最近最少使用（LRU）淘汰
 type MemoryCache struct {
-	items             map[string]CacheItem
-	mu                sync.RWMutex
+	items             map[string]*list.Element
+	lru               *list.List // 链表头部为最近使用的缓存项
+	usedBytes         int64      // 当前占用字节数（估算值）
+	maxEntries        int
+	maxBytes          int64
+	mu                sync.Mutex
 	defaultExpiration time.Duration
+	prefixTTLs        map[string]time.Duration
+	staleWindow       time.Duration
 	cleanupInterval   time.Duration
-	stopCleanup       chan bool
-	hitCount          int64        // 缓存命中计数
-	missCount         int64        // 缓存未命中计数
-	hitCountMu        sync.RWMutex // 命中计数锁，避免与主缓存锁冲突
+	stopCleanup       chan struct{}
+	stopOnce          sync.Once
+	hitCount          int64                   // 缓存命中计数
+	missCount         int64                   // 缓存未命中计数
+	evictionCount     int64                   // 缓存淘汰计数（包括过期清理和容量淘汰）
+	staleHitCount     int64                   // 返回过期旧值的次数
+	prefixCounters    map[string]*PrefixStats // 按键前缀统计的命中/未命中/淘汰次数
+	hitCountMu        sync.RWMutex            // 命中计数锁，避免与主缓存锁冲突
+	loadCount         int64                   // 通过 GetOrLoad 实际执行的加载次数
+	sharedLoadCount   int64                   // 等待并共享其他调用方加载结果的次数
+	loading           map[string]*loadCall    // 正在进行中的加载
+	loadMu            sync.Mutex
+	// generations 正在加载的键的代数，Delete/DeletePrefix/Flush 时递增，由 mu 保护
+	// 加载完成时代数已变化说明加载期间数据被失效，加载结果可能是旧值，只返回给调用方而不写入缓存
+	generations map[string]uint64
 }
 
-// NewMemoryCache 创建新的内存缓存
+// entry 链表中保存的缓存项
+type entry struct {
+	key  string
+	item CacheItem
+	size int64
+}
+
+// PrefixStats 某一键前缀的缓存统计
+type PrefixStats struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+	Items     int64
+	Bytes     int64
+}
+
+// NewMemoryCache 创建新的内存缓存，不限制容量
 func NewMemoryCache(defaultExpiration, cleanupInterval time.Duration) *MemoryCache {
+	return NewMemoryCacheWithOptions(Options{
+		DefaultExpiration: defaultExpiration,
+		CleanupInterval:   cleanupInterval,
+	})
+}
+
+// NewMemoryCacheWithOptions 根据配置创建内存缓存
+func NewMemoryCacheWithOptions(options Options) *MemoryCache {
 	cache := &MemoryCache{
-		items:             make(map[string]CacheItem),
-		defaultExpiration: defaultExpiration,
-		cleanupInterval:   cleanupInterval,
-		stopCleanup:       make(chan bool),
+		items:             make(map[string]*list.Element),
+		lru:               list.New(),
+		maxEntries:        options.MaxEntries,
+		maxBytes:          options.MaxBytes,
+		defaultExpiration: options.DefaultExpiration,
+		prefixTTLs:        make(map[string]time.Duration, len(options.PrefixTTLs)),
+		staleWindow:       options.StaleWindow,
+		cleanupInterval:   options.CleanupInterval,
+		stopCleanup:       make(chan struct{}),
 		hitCount:          0,
 		missCount:         0,
+		prefixCounters:    make(map[string]*PrefixStats),
+		loading:           make(map[string]*loadCall),
+		generations:       make(map[string]uint64),
+	}
+
+	for prefix, ttl := range options.PrefixTTLs {
+		cache.prefixTTLs[prefix] = ttl
 	}
 
 	// 如果清理间隔大于0，启动后台清理协程
-	if cleanupInterval > 0 {
+	if options.CleanupInterval > 0 {
 		go cache.startCleanupTimer()
 	}
 
 	return cache
 }
 
-// Set 设置缓存项，使用默认过期时间
+// Set 设置缓存项，使用键前缀对应的过期时间，未配置时使用默认过期时间
 func (c *MemoryCache) Set(key string, value interface{}) {
-	c.SetWithExpiration(key, value, c.defaultExpiration)
+	c.SetWithExpiration(key, value, 0)
+}
+
+// TTL 返回键对应的过期时间策略
+func (c *MemoryCache) TTL(key string) time.Duration {
+	if ttl, ok := c.prefixTTLs[KeyPrefix(key)]; ok {
+		return ttl
+	}
+	return c.defaultExpiration
 }
 
 // SetWithExpiration 设置缓存项，指定过期时间
 func (c *MemoryCache) SetWithExpiration(key string, value interface{}, duration time.Duration) {
-	var expiration int64
+	item, size := c.newEntry(key, value, duration)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.setLocked(key, item, size)
+}
+
+// newEntry 计算缓存项的过期时间和估算大小，在加锁前调用，避免遍历大对象时阻塞其他请求
+func (c *MemoryCache) newEntry(key string, value interface{}, duration time.Duration) (CacheItem, int64) {
+	var expiration, staleExpiration int64
 
 	if duration == 0 {
-		// 0 表示使用默认过期时间
-		duration = c.defaultExpiration
+		// 0 表示使用键前缀对应的过期时间
+		duration = c.TTL(key)
 	}
 
 	if duration > 0 {
 		expiration = time.Now().Add(duration).UnixNano()
+		staleExpiration = expiration + int64(c.staleWindow)
 	}
 
-	c.mu.Lock()
-	c.items[key] = CacheItem{
-		Value:      value,
-		Expiration: expiration,
+	item := CacheItem{
+		Value:           value,
+		Expiration:      expiration,
+		StaleExpiration: staleExpiration,
 	}
-	c.mu.Unlock()
+	return item, int64(len(key)) + EstimateSize(value) + entryOverhead
+}
+
+// setLocked 写入缓存项，调用方需持有锁
+func (c *MemoryCache) setLocked(key string, item CacheItem, size int64) {
+	// 单个值超过容量上限时不缓存
+	if c.maxBytes > 0 && size > c.maxBytes {
+		c.removeKey(key)
+		return
+	}
+
+	if element, found := c.items[key]; found {
+		e := element.Value.(*entry)
+		c.usedBytes += size - e.size
+		e.item = item
+		e.size = size
+		c.lru.MoveToFront(element)
+	} else {
+		c.items[key] = c.lru.PushFront(&entry{key: key, item: item, size: size})
+		c.usedBytes += size
+	}
+
+	c.evictOverflow()
 }
 
-// Get 获取缓存项
+// Get 获取缓存项，已过期的缓存项视为未找到
 func (c *MemoryCache) Get(key string) (interface{}, bool) {
-	c.mu.RLock()
-	item, found := c.items[key]
-	c.mu.RUnlock()
+	value, found, _ := c.lookup(key, false)
+	return value, found
+}
+
+// lookup 查找缓存项，allowStale 为 true 时旧值窗口内的过期项也会返回，并通过 stale 标记
+func (c *MemoryCache) lookup(key string, allowStale bool) (value interface{}, found, stale bool) {
+	c.mu.Lock()
+	element, ok := c.items[key]
+	if ok {
+		item := element.Value.(*entry).item
+		switch {
+		case !item.Expired():
+			value, found = item.Value, true
+		case allowStale && item.Stale():
+			value, found, stale = item.Value, true, true
+		}
+		if found {
+			c.lru.MoveToFront(element)
+		}
+	}
+	c.mu.Unlock()
 
 	// 如果未找到或已过期，返回未找到
-	if !found || item.Expired() {
-		c.recordMiss()
-		return nil, false
+	if !found {
+		c.recordMiss(key)
+		return nil, false, false
 	}
 
-	c.recordHit()
-	return item.Value, true
+	c.recordHit(key)
+	if stale {
+		c.hitCountMu.Lock()
+		c.staleHitCount++
+		c.hitCountMu.Unlock()
+	}
+	return value, true, stale
 }
 
 // recordHit 记录缓存命中
-func (c *MemoryCache) recordHit() {
+func (c *MemoryCache) recordHit(key string) {
 	c.hitCountMu.Lock()
 	c.hitCount++
+	c.prefixCounter(KeyPrefix(key)).Hits++
 	c.hitCountMu.Unlock()
 }
 
 // recordMiss 记录缓存未命中
-func (c *MemoryCache) recordMiss() {
+func (c *MemoryCache) recordMiss(key string) {
 	c.hitCountMu.Lock()
 	c.missCount++
+	c.prefixCounter(KeyPrefix(key)).Misses++
 	c.hitCountMu.Unlock()
 }
 
+// recordEviction 记录缓存淘汰
+func (c *MemoryCache) recordEviction(key string) {
+	c.hitCountMu.Lock()
+	c.evictionCount++
+	c.prefixCounter(KeyPrefix(key)).Evictions++
+	c.hitCountMu.Unlock()
+}
+
+// prefixCounter 获取键前缀对应的计数器，调用方需持有 hitCountMu 写锁
+func (c *MemoryCache) prefixCounter(prefix string) *PrefixStats {
+	counter, ok := c.prefixCounters[prefix]
+	if !ok {
+		counter = &PrefixStats{}
+		c.prefixCounters[prefix] = counter
+	}
+	return counter
+}
+
+// KeyPrefix 返回缓存键的前缀（第一个冒号之前的部分），用于分类统计
+func KeyPrefix(key string) string {
+	if index := strings.Index(key, ":"); index >= 0 {
+		return key[:index]
+	}
+	return key
+}
+
 // Delete 删除缓存项
 func (c *MemoryCache) Delete(key string) {
 	c.mu.Lock()
-	delete(c.items, key)
+	c.removeKey(key)
+	if _, ok := c.generations[key]; ok {
+		c.generations[key]++
+	}
 	c.mu.Unlock()
 }
 
+// DeletePrefix 删除所有以指定前缀开头的缓存项，返回删除数量
+func (c *MemoryCache) DeletePrefix(prefix string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	deleted := 0
+	for k, element := range c.items {
+		if strings.HasPrefix(k, prefix) {
+			c.removeElement(element)
+			deleted++
+		}
+	}
+	for k := range c.generations {
+		if strings.HasPrefix(k, prefix) {
+			c.generations[k]++
+		}
+	}
+	return deleted
+}
+
 // Flush 清空所有缓存项
 func (c *MemoryCache) Flush() {
 	c.mu.Lock()
-	c.items = make(map[string]CacheItem)
+	c.items = make(map[string]*list.Element)
+	c.lru.Init()
+	c.usedBytes = 0
+	for k := range c.generations {
+		c.generations[k]++
+	}
 	c.mu.Unlock()
 }
 
+// removeKey 删除指定键，调用方需持有锁
+func (c *MemoryCache) removeKey(key string) {
+	if element, found := c.items[key]; found {
+		c.removeElement(element)
+	}
+}
+
+// removeElement 从映射和链表中移除缓存项，调用方需持有锁
+func (c *MemoryCache) removeElement(element *list.Element) {
+	e := c.lru.Remove(element).(*entry)
+	delete(c.items, e.key)
+	c.usedBytes -= e.size
+}
+
+// evictOverflow 超出数量或字节上限时从链表尾部淘汰最久未使用的缓存项，调用方需持有锁
+func (c *MemoryCache) evictOverflow() {
+	for c.lru.Len() > 0 &&
+		((c.maxEntries > 0 && c.lru.Len() > c.maxEntries) || (c.maxBytes > 0 && c.usedBytes > c.maxBytes)) {
+		element := c.lru.Back()
+		key := element.Value.(*entry).key
+		c.removeElement(element)
+		c.recordEviction(key)
+	}
+}
+
 // startCleanupTimer 启动定时清理
 func (c *MemoryCache) startCleanupTimer() {
 	ticker := time.NewTicker(c.cleanupInterval)
@@ -122,34 +336,70 @@ func (c *MemoryCache) startCleanupTimer() {
 	}
 }
 
-// StopCleanup 停止定时清理
+// StopCleanup 停止定时清理，通过关闭通道通知，未启动后台清理或重复调用时不会阻塞
 func (c *MemoryCache) StopCleanup() {
-	c.stopCleanup <- true
+	c.stopOnce.Do(func() {
+		close(c.stopCleanup)
+	})
 }
 
-// deleteExpired 删除过期项
+// deleteExpired 删除过期且超出旧值窗口的缓存项
 func (c *MemoryCache) deleteExpired() {
 	now := time.Now().UnixNano()
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	for k, v := range c.items {
-		if v.Expiration > 0 && now > v.Expiration {
-			delete(c.items, k)
+	for k, element := range c.items {
+		if element.Value.(*entry).item.Dead(now) {
+			c.removeElement(element)
+			c.recordEviction(k)
 		}
 	}
 }
 
+// PrefixStats 按键前缀返回命中、未命中、淘汰次数及当前缓存项数量和占用字节数
+func (c *MemoryCache) PrefixStats() map[string]PrefixStats {
+	c.mu.Lock()
+	items := make(map[string]int64)
+	bytes := make(map[string]int64)
+	for key, element := range c.items {
+		prefix := KeyPrefix(key)
+		items[prefix]++
+		bytes[prefix] += element.Value.(*entry).size
+	}
+	c.mu.Unlock()
+
+	c.hitCountMu.RLock()
+	defer c.hitCountMu.RUnlock()
+
+	stats := make(map[string]PrefixStats, len(c.prefixCounters))
+	for prefix, counter := range c.prefixCounters {
+		stats[prefix] = *counter
+	}
+	for prefix, count := range items {
+		prefixStats := stats[prefix]
+		prefixStats.Items = count
+		prefixStats.Bytes = bytes[prefix]
+		stats[prefix] = prefixStats
+	}
+
+	return stats
+}
+
 // Stats 获取缓存统计信息
 func (c *MemoryCache) Stats() map[string]interface{} {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
 	// 获取命中率统计
 	c.hitCountMu.RLock()
 	hits := c.hitCount
 	misses := c.missCount
+	evictions := c.evictionCount
+	staleHits := c.staleHitCount
+	loads := c.loadCount
+	sharedLoads := c.sharedLoadCount
 	c.hitCountMu.RUnlock()
 
 	// 计算命中率
@@ -166,30 +416,44 @@ func (c *MemoryCache) Stats() map[string]interface{} {
 	typeStats := make(map[string]int)
 	for key := range c.items {
 		// 根据键前缀分类
-		parts := strings.Split(key, ":")
-		if len(parts) > 0 {
-			prefix := parts[0]
-			typeStats[prefix]++
-		}
+		typeStats[KeyPrefix(key)]++
 	}
 
 	// 统计过期项
 	now := time.Now().UnixNano()
 	expired := 0
-	for _, item := range c.items {
-		if item.Expiration > 0 && now > item.Expiration {
+	for _, element := range c.items {
+		if item := element.Value.(*entry).item; item.Expiration > 0 && now > item.Expiration {
 			expired++
 		}
 	}
 
 	return map[string]interface{}{
-		"total":            total,
-		"expired":          expired,
-		"hit_count":        hits,
-		"miss_count":       misses,
-		"hit_rate":         hitRate,
-		"type_stats":       typeStats,
-		"memory_size":      "未计算", // 计算内存占用较复杂，暂不实现
-		"cleanup_interval": c.cleanupInterval.String(),
+		"total":             total,
+		"expired":           expired,
+		"hit_count":         hits,
+		"miss_count":        misses,
+		"eviction_count":    evictions,
+		"load_count":        loads,
+		"shared_load_count": sharedLoads,
+		"hit_rate":          hitRate,
+		"stale_hit_count":   staleHits,
+		"stale_window":      c.staleWindow.String(),
+		"ttl_policies":      c.ttlPolicies(),
+		"type_stats":        typeStats,
+		"memory_size":       c.usedBytes, // 估算的占用字节数
+		"max_entries":       c.maxEntries,
+		"max_bytes":         c.maxBytes,
+		"cleanup_interval":  c.cleanupInterval.String(),
+	}
+}
+
+// ttlPolicies 以字符串形式返回按前缀配置的过期时间，用于统计展示
+func (c *MemoryCache) ttlPolicies() map[string]string {
+	policies := make(map[string]string, len(c.prefixTTLs)+1)
+	policies["default"] = c.defaultExpiration.String()
+	for prefix, ttl := range c.prefixTTLs {
+		policies[prefix] = ttl.String()
 	}
+	return policies
 }
@@ -0,0 +1,181 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUEviction(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		keys    []string // 依次写入的键
+		touch   string   // 全部写入前访问的键，使其成为最近使用
+		want    []string // 应保留的键
+		evicted []string // 应被淘汰的键
+	}{
+		{
+			name:    "按数量淘汰最久未使用",
+			options: Options{MaxEntries: 2},
+			keys:    []string{"a", "b", "c"},
+			want:    []string{"b", "c"},
+			evicted: []string{"a"},
+		},
+		{
+			name:    "访问过的键不被淘汰",
+			options: Options{MaxEntries: 2},
+			keys:    []string{"a", "b", "c"},
+			touch:   "a",
+			want:    []string{"a", "c"},
+			evicted: []string{"b"},
+		},
+		{
+			name:    "按字节淘汰",
+			options: Options{MaxBytes: 2 * (entryOverhead + 64)},
+			keys:    []string{"a", "b", "c"},
+			want:    []string{"c"},
+			evicted: []string{"a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewMemoryCacheWithOptions(tt.options)
+			for i, key := range tt.keys {
+				// 最后一个键写入前访问 touch，使其成为最近使用
+				if i == len(tt.keys)-1 && tt.touch != "" {
+					c.Get(tt.touch)
+				}
+				c.Set(key, key)
+			}
+			for _, key := range tt.want {
+				if _, ok := c.Get(key); !ok {
+					t.Errorf("key %q should be cached", key)
+				}
+			}
+			for _, key := range tt.evicted {
+				if _, ok := c.Get(key); ok {
+					t.Errorf("key %q should be evicted", key)
+				}
+			}
+		})
+	}
+}
+
+func TestOversizedValueNotCached(t *testing.T) {
+	c := NewMemoryCacheWithOptions(Options{MaxBytes: entryOverhead + 16})
+	c.Set("small", "x")
+	c.Set("small", string(make([]byte, 1024)))
+	if _, ok := c.Get("small"); ok {
+		t.Error("value larger than MaxBytes should not be cached and should replace the old value")
+	}
+}
+
+func TestTTLPolicies(t *testing.T) {
+	c := NewMemoryCacheWithOptions(Options{
+		DefaultExpiration: time.Minute,
+		PrefixTTLs:        map[string]time.Duration{"movie": time.Hour, "search": 0},
+	})
+	tests := []struct {
+		key  string
+		want time.Duration
+	}{
+		{"movie:1", time.Hour},
+		{"movie", time.Hour},
+		{"search:toy:1", 0},
+		{"ratings:1", time.Minute},
+		{"other", time.Minute},
+	}
+	for _, tt := range tests {
+		if got := c.TTL(tt.key); got != tt.want {
+			t.Errorf("TTL(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestExpiration(t *testing.T) {
+	c := NewMemoryCacheWithOptions(Options{DefaultExpiration: 20 * time.Millisecond})
+	c.Set("short", 1)
+	c.SetWithExpiration("long", 2, time.Hour)
+	time.Sleep(40 * time.Millisecond)
+
+	if _, ok := c.Get("short"); ok {
+		t.Error("expired item should not be returned")
+	}
+	if value, ok := c.Get("long"); !ok || value != 2 {
+		t.Errorf("Get(long) = %v, %v, want 2, true", value, ok)
+	}
+}
+
+func TestDeletePrefixAndFlush(t *testing.T) {
+	c := NewMemoryCacheWithOptions(Options{})
+	for _, key := range []string{"movie:1", "movie:2", "movies:1", "ratings:1"} {
+		c.Set(key, key)
+	}
+
+	if deleted := c.DeletePrefix("movie:"); deleted != 2 {
+		t.Errorf("DeletePrefix(movie:) = %d, want 2", deleted)
+	}
+	if _, ok := c.Get("movies:1"); !ok {
+		t.Error("movies:1 should not match prefix movie:")
+	}
+
+	c.Flush()
+	if stats := c.Stats(); stats["total"] != 0 || stats["memory_size"] != int64(0) {
+		t.Errorf("after Flush total = %v, memory_size = %v, want 0", stats["total"], stats["memory_size"])
+	}
+}
+
+func TestKeyPrefix(t *testing.T) {
+	tests := []struct {
+		key, want string
+	}{
+		{"movie:1", "movie"},
+		{"search:toy:1", "search"},
+		{"total_movies_count", "total_movies_count"},
+		{":x", ""},
+	}
+	for _, tt := range tests {
+		if got := KeyPrefix(tt.key); got != tt.want {
+			t.Errorf("KeyPrefix(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestStopCleanup(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+	}{
+		{"未启动后台清理", 0},
+		{"已启动后台清理", time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewMemoryCacheWithOptions(Options{CleanupInterval: tt.interval})
+			done := make(chan struct{})
+			go func() {
+				c.StopCleanup()
+				c.StopCleanup()
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("StopCleanup blocked")
+			}
+		})
+	}
+}
+
+func TestCleanupRemovesDeadItems(t *testing.T) {
+	c := NewMemoryCacheWithOptions(Options{CleanupInterval: 5 * time.Millisecond})
+	defer c.StopCleanup()
+
+	c.SetWithExpiration("gone", 1, time.Millisecond)
+	c.SetWithExpiration("kept", 1, time.Hour)
+	time.Sleep(30 * time.Millisecond)
+
+	if stats := c.Stats(); stats["total"] != 1 {
+		t.Errorf("total = %v after cleanup, want 1", stats["total"])
+	}
+}
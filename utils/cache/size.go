@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// EstimateSize 估算值占用的内存字节数
+// 递归统计字符串、切片、映射和指针指向的数据，同一指针只统计一次；结果为近似值，仅用于容量控制
+func EstimateSize(value interface{}) int64 {
+	if value == nil {
+		return 0
+	}
+	return estimate(reflect.ValueOf(value), make(map[uintptr]bool))
+}
+
+// estimate 估算 v 的大小，visited 记录已统计的指针，避免循环引用和重复统计
+func estimate(v reflect.Value, visited map[uintptr]bool) int64 {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return 0
+	case reflect.Ptr:
+		if v.IsNil() {
+			return int64(unsafe.Sizeof(uintptr(0)))
+		}
+		if visited[v.Pointer()] {
+			return int64(unsafe.Sizeof(uintptr(0)))
+		}
+		visited[v.Pointer()] = true
+		return int64(unsafe.Sizeof(uintptr(0))) + estimate(v.Elem(), visited)
+	case reflect.Interface:
+		if v.IsNil() {
+			return int64(v.Type().Size())
+		}
+		return int64(v.Type().Size()) + estimate(v.Elem(), visited)
+	case reflect.String:
+		return int64(v.Type().Size()) + int64(v.Len())
+	case reflect.Slice:
+		if v.IsNil() {
+			return int64(v.Type().Size())
+		}
+		size := int64(v.Type().Size())
+		elemSize := int64(v.Type().Elem().Size())
+		size += int64(v.Cap()-v.Len()) * elemSize
+		for i := 0; i < v.Len(); i++ {
+			size += estimate(v.Index(i), visited)
+		}
+		return size
+	case reflect.Array:
+		size := int64(0)
+		for i := 0; i < v.Len(); i++ {
+			size += estimate(v.Index(i), visited)
+		}
+		return size
+	case reflect.Map:
+		if v.IsNil() {
+			return int64(v.Type().Size())
+		}
+		// 映射的桶结构开销按每个键值对额外 16 字节估算
+		size := int64(v.Type().Size())
+		iter := v.MapRange()
+		for iter.Next() {
+			size += estimate(iter.Key(), visited) + estimate(iter.Value(), visited) + 16
+		}
+		return size
+	case reflect.Struct:
+		size := int64(0)
+		for i := 0; i < v.NumField(); i++ {
+			size += estimate(v.Field(i), visited)
+		}
+		// 补齐字段对齐产生的填充
+		if padding := int64(v.Type().Size()) - fieldsSize(v.Type()); padding > 0 {
+			size += padding
+		}
+		return size
+	default:
+		return int64(v.Type().Size())
+	}
+}
+
+// fieldsSize 结构体各字段自身大小之和（不含填充）
+func fieldsSize(t reflect.Type) int64 {
+	size := int64(0)
+	for i := 0; i < t.NumField(); i++ {
+		size += int64(t.Field(i).Type.Size())
+	}
+	return size
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"gohbase/utils/charts"
+	"gohbase/utils/search"
+	"gohbase/utils/store"
+	"sort"
+	"time"
+)
+
+// ChartEngine 对外暴露的全局排行引擎
+var ChartEngine *charts.Engine
+
+// InitCharts 初始化加权评分排行与热度榜，基于搜索索引中的电影快照和 timeline 列族同步计算首个排行后按 interval 在后台重算
+// 需在 InitSearchEngine 之后调用；首次计算失败时仍会启动后台重算，并返回错误
+func InitCharts(ctx context.Context, minVotes int64, interval time.Duration) error {
+	charts.InitEngine(loadIndexedDocuments, loadChartActivity, minVotes, interval)
+	ChartEngine = charts.Default
+
+	err := ChartEngine.Refresh(ctx)
+	ChartEngine.Start()
+	return err
+}
+
+// loadIndexedDocuments 从当前搜索索引读取全部电影文档，评分人数和平均分与索引同步，供排行和相似电影计算使用
+func loadIndexedDocuments(ctx context.Context) ([]*search.Document, error) {
+	if SearchEngine == nil || SearchEngine.Index() == nil {
+		return nil, errors.New("搜索索引尚未构建")
+	}
+	return SearchEngine.Index().Filter(search.Filter{}), nil
+}
+
+// loadChartActivity 遍历全部电影的 timeline 列族，保留最近 charts.ActivityHorizon 天内有评分的日期
+func loadChartActivity(ctx context.Context) (map[string][]store.DayStat, error) {
+	cutoff := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -charts.ActivityHorizon)
+
+	activity := make(map[string][]store.DayStat)
+	err := Store.ForEachMovie(ctx, []string{store.TimelineFamily}, func(row store.Row) error {
+		days := store.ParseTimeline(row.Data[store.TimelineFamily])
+		// 按日期升序，跳过窗口之前的日期
+		first := sort.Search(len(days), func(i int) bool { return !days[i].Day.Before(cutoff) })
+		if first < len(days) {
+			activity[row.Key] = days[first:]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return activity, nil
+}
+
+// WeightedRating 使用当前排行快照的全库平均分计算加权评分，排行尚未计算或没有评分时返回0
+func WeightedRating(votes int64, avg float64) float64 {
+	if ChartEngine == nil {
+		return 0
+	}
+	snapshot := ChartEngine.Snapshot()
+	if snapshot == nil {
+		return 0
+	}
+	return snapshot.WeightedRating(votes, avg)
+}
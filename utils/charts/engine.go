@@ -0,0 +1,48 @@
+package charts
+
+import (
+	"context"
+	"gohbase/utils/refresher"
+	"gohbase/utils/search"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Source 读取参与排行的全部电影文档
+type Source func(ctx context.Context) ([]*search.Document, error)
+
+// Engine 持有当前的排行快照，并按固定间隔在后台重新计算
+type Engine struct {
+	*refresher.Refresher[Snapshot]
+}
+
+// NewEngine 创建排行引擎，需调用 Refresh 计算首个快照；activity 为 nil 时热度榜为空
+func NewEngine(source Source, activity ActivitySource, minVotes int64, interval time.Duration) *Engine {
+	// 重新读取电影及近期按天评分统计并计算排行
+	build := func(ctx context.Context) (*Snapshot, error) {
+		start := time.Now()
+		docs, err := source(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		snapshot := Build(docs, minVotes)
+		if activity != nil {
+			if snapshot.activity, err = activity(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		logrus.Infof("加权评分排行计算完成 [电影数: %d, 近期有评分的电影数: %d, 全库平均分: %.3f, 最少评分人数: %d, 耗时: %s]",
+			snapshot.Len(), len(snapshot.activity), snapshot.Mean, snapshot.MinVotes, time.Since(start).Round(time.Millisecond))
+		return snapshot, nil
+	}
+
+	return &Engine{Refresher: refresher.New("计算加权评分排行", build, interval)}
+}
+
+// Snapshot 返回当前排行快照，尚未计算时返回 nil
+func (e *Engine) Snapshot() *Snapshot {
+	return e.Value()
+}
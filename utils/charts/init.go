@@ -0,0 +1,13 @@
+package charts
+
+import (
+	"time"
+)
+
+// Default 全局排行引擎实例
+var Default *Engine
+
+// InitEngine 初始化全局排行引擎
+func InitEngine(source Source, activity ActivitySource, minVotes int64, interval time.Duration) {
+	Default = NewEngine(source, activity, minVotes, interval)
+}
@@ -0,0 +1,96 @@
+package charts
+
+import (
+	"context"
+	"gohbase/utils/search"
+	"gohbase/utils/store"
+	"sort"
+	"time"
+)
+
+const (
+	// MaxTrendingWindow 热度榜最长统计窗口（天）
+	MaxTrendingWindow = 30
+	// baselinePeriods 基线期长度为统计窗口的倍数，紧接在统计窗口之前
+	baselinePeriods = 4
+	// ActivityHorizon 计算热度所需的最长历史（天），更早的按天统计不进入快照
+	ActivityHorizon = MaxTrendingWindow * (baselinePeriods + 1)
+)
+
+// ActivitySource 读取每部电影最近 ActivityHorizon 天内的按天评分统计，键为 movieId
+type ActivitySource func(ctx context.Context) (map[string][]store.DayStat, error)
+
+// TrendingEntry 热度榜中的一部电影
+// Recent 为统计窗口内的评分数量，Baseline 为基线期（窗口之前 4 倍长度）的评分数量，
+// Score 为窗口内评分速度相对基线期平均速度的倍数
+type TrendingEntry struct {
+	Document *search.Document
+	Recent   int64
+	Baseline int64
+	Score    float64
+}
+
+// TrendingScore 计算热度：(recent+1) / (baseline/periods+1)，加1平滑使新片和冷门片不会因基线为0而分数失控
+func TrendingScore(recent, baseline int64, periods int) float64 {
+	expected := float64(baseline) / float64(periods)
+	return (float64(recent) + 1) / (expected + 1)
+}
+
+// Trending 返回最近 windowDays 天评分速度相对基线增长最多的 limit 部电影，窗口内没有评分的电影不参与排行
+// 窗口以快照计算时的UTC日期为最后一天
+func (s *Snapshot) Trending(windowDays, limit int) []TrendingEntry {
+	today := s.BuiltAt.UTC().Truncate(24 * time.Hour)
+	recentFrom := today.AddDate(0, 0, -(windowDays - 1))
+	baselineFrom := recentFrom.AddDate(0, 0, -windowDays*baselinePeriods)
+
+	entries := []TrendingEntry{}
+	for movieID, days := range s.activity {
+		doc, ok := s.documents[movieID]
+		if !ok {
+			continue
+		}
+
+		var recent, baseline int64
+		for _, day := range days {
+			switch {
+			case day.Day.After(today):
+			case !day.Day.Before(recentFrom):
+				recent += day.Count
+			case !day.Day.Before(baselineFrom):
+				baseline += day.Count
+			}
+		}
+		if recent == 0 {
+			continue
+		}
+
+		entries = append(entries, TrendingEntry{
+			Document: doc,
+			Recent:   recent,
+			Baseline: baseline,
+			Score:    TrendingScore(recent, baseline, baselinePeriods),
+		})
+	}
+
+	// 热度相同时窗口内评分多的在前，再按 movieId 保证顺序稳定
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Recent != b.Recent {
+			return a.Recent > b.Recent
+		}
+		return search.CompareDocuments(a.Document, b.Document, "", false) < 0
+	})
+
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries
+}
+
+// BaselineDays 返回统计窗口对应的基线期天数
+func BaselineDays(windowDays int) int {
+	return windowDays * baselinePeriods
+}
@@ -0,0 +1,80 @@
+package charts
+
+import (
+	"gohbase/utils/store"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTrendingScore(t *testing.T) {
+	tests := []struct {
+		recent, baseline int64
+		periods          int
+		want             float64
+	}{
+		{0, 0, 4, 1},
+		{9, 0, 4, 10},
+		{9, 36, 4, 1},
+		{19, 36, 4, 2},
+		{4, 40, 4, 5.0 / 11},
+	}
+	for _, tt := range tests {
+		got := TrendingScore(tt.recent, tt.baseline, tt.periods)
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("TrendingScore(%d, %d, %d) = %v, want %v", tt.recent, tt.baseline, tt.periods, got, tt.want)
+		}
+	}
+}
+
+func TestTrending(t *testing.T) {
+	builtAt := time.Date(2024, 3, 31, 15, 0, 0, 0, time.UTC)
+	today := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	day := func(daysAgo int, count int64) store.DayStat {
+		return store.DayStat{Day: today.AddDate(0, 0, -daysAgo), Count: count}
+	}
+
+	snapshot := Build(chartDocuments(), 10)
+	snapshot.BuiltAt = builtAt
+	snapshot.activity = map[string][]store.DayStat{
+		"1": {day(0, 5), day(10, 40)},             // 7天窗口内5条，基线期（7~34天前）40条
+		"2": {day(6, 3)},                          // 新近才有评分
+		"3": {day(7, 100), day(40, 100)},          // 窗口内没有评分
+		"4": {day(-1, 50), day(1, 3), day(20, 4)}, // 未来日期不计入
+		"9": {day(0, 100)},                        // 不在快照中的电影
+	}
+
+	tests := []struct {
+		name   string
+		window int
+		limit  int
+		want   []string
+	}{
+		{"7天窗口", 7, 10, []string{"2", "4", "1"}},
+		{"条数限制", 7, 1, []string{"2"}},
+		{"1天窗口", 1, 10, []string{"1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := []string{}
+			for _, entry := range snapshot.Trending(tt.window, tt.limit) {
+				ids = append(ids, entry.Document.MovieID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("Trending(%d, %d) = %v, want %v", tt.window, tt.limit, ids, tt.want)
+			}
+		})
+	}
+
+	entries := snapshot.Trending(7, 10)
+	if got := entries[len(entries)-1]; got.Recent != 5 || got.Baseline != 40 {
+		t.Errorf("movie 1 recent/baseline = %d/%d, want 5/40", got.Recent, got.Baseline)
+	}
+}
+
+func TestBaselineDays(t *testing.T) {
+	if got := BaselineDays(7); got != 28 {
+		t.Errorf("BaselineDays(7) = %d, want 28", got)
+	}
+}
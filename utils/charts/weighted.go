@@ -0,0 +1,111 @@
+package charts
+
+import (
+	"gohbase/utils/search"
+	"gohbase/utils/store"
+	"sort"
+	"time"
+)
+
+// WeightedRating 贝叶斯加权评分（IMDb 公式）：WR = v/(v+m)·R + m/(v+m)·C
+// v 为评分人数，R 为平均分，m 为最少评分人数阈值，C 为全库平均分；评分人数越少越向全库平均分收缩
+func WeightedRating(votes int64, avg float64, minVotes int64, mean float64) float64 {
+	if votes <= 0 {
+		return 0
+	}
+	v, m := float64(votes), float64(minVotes)
+	return v/(v+m)*avg + m/(v+m)*mean
+}
+
+// Entry 排行榜中的一部电影
+type Entry struct {
+	Document       *search.Document
+	WeightedRating float64
+}
+
+// Snapshot 某一时刻的加权评分排行，Mean 为全库所有评分的平均分
+type Snapshot struct {
+	Mean     float64
+	MinVotes int64
+	BuiltAt  time.Time
+	ranked   []Entry // 全部有评分的电影，按加权评分降序
+
+	documents map[string]*search.Document // 全部电影，键为 movieId
+	activity  map[string][]store.DayStat  // 最近 ActivityHorizon 天的按天评分统计，用于热度榜
+}
+
+// Build 根据电影文档计算全库平均分和每部电影的加权评分，并按加权评分降序排列
+func Build(docs []*search.Document, minVotes int64) *Snapshot {
+	var totalVotes int64
+	var totalSum float64
+	for _, doc := range docs {
+		totalVotes += doc.RatingCount
+		totalSum += doc.AvgRating * float64(doc.RatingCount)
+	}
+
+	snapshot := &Snapshot{
+		MinVotes:  minVotes,
+		BuiltAt:   time.Now(),
+		documents: make(map[string]*search.Document, len(docs)),
+	}
+	if totalVotes > 0 {
+		snapshot.Mean = totalSum / float64(totalVotes)
+	}
+
+	for _, doc := range docs {
+		snapshot.documents[doc.MovieID] = doc
+		if doc.RatingCount == 0 {
+			continue
+		}
+		snapshot.ranked = append(snapshot.ranked, Entry{
+			Document:       doc,
+			WeightedRating: WeightedRating(doc.RatingCount, doc.AvgRating, minVotes, snapshot.Mean),
+		})
+	}
+
+	// 加权评分相同时评分人数多的在前，再按 movieId 保证顺序稳定
+	sort.Slice(snapshot.ranked, func(i, j int) bool {
+		a, b := snapshot.ranked[i], snapshot.ranked[j]
+		if a.WeightedRating != b.WeightedRating {
+			return a.WeightedRating > b.WeightedRating
+		}
+		if a.Document.RatingCount != b.Document.RatingCount {
+			return a.Document.RatingCount > b.Document.RatingCount
+		}
+		return search.CompareDocuments(a.Document, b.Document, "", false) < 0
+	})
+
+	return snapshot
+}
+
+// WeightedRating 使用快照中的全库平均分计算加权评分
+func (s *Snapshot) WeightedRating(votes int64, avg float64) float64 {
+	return WeightedRating(votes, avg, s.MinVotes, s.Mean)
+}
+
+// Len 返回参与排行的电影数量
+func (s *Snapshot) Len() int {
+	return len(s.ranked)
+}
+
+// Top 返回加权评分最高的 limit 部电影，genre 不为空时只取该类型（不区分大小写），decade 不为0时只取该年代（如 1990）
+func (s *Snapshot) Top(genre string, decade, limit int) []Entry {
+	filter := search.Filter{}
+	if genre != "" {
+		filter.Genres = []string{genre}
+	}
+	if decade != 0 {
+		filter.YearFrom, filter.YearTo = decade, decade+9
+	}
+
+	entries := []Entry{}
+	for _, entry := range s.ranked {
+		if len(entries) >= limit {
+			break
+		}
+		if filter.Match(entry.Document) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
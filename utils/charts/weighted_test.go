@@ -0,0 +1,90 @@
+package charts
+
+import (
+	"gohbase/utils/search"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestWeightedRating(t *testing.T) {
+	tests := []struct {
+		name     string
+		votes    int64
+		avg      float64
+		minVotes int64
+		mean     float64
+		want     float64
+	}{
+		{"没有评分", 0, 5, 10, 3, 0},
+		{"评分人数等于阈值", 10, 5, 10, 3, 4},
+		{"评分人数远超阈值接近平均分", 990, 4, 10, 3, 3.99},
+		{"评分人数很少接近全库平均分", 1, 5, 99, 3, 3.02},
+		{"阈值为0时等于平均分", 3, 4.5, 0, 3, 4.5},
+	}
+	for _, tt := range tests {
+		got := WeightedRating(tt.votes, tt.avg, tt.minVotes, tt.mean)
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: WeightedRating = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// chartDocuments 测试用的电影文档
+func chartDocuments() []*search.Document {
+	return []*search.Document{
+		{MovieID: "1", Title: "Toy Story (1995)", Genres: []string{"Animation", "Comedy"}, Year: 1995, RatingCount: 100, AvgRating: 4},
+		{MovieID: "2", Title: "Obscure (1995)", Genres: []string{"Drama"}, Year: 1995, RatingCount: 1, AvgRating: 5},
+		{MovieID: "3", Title: "Matrix, The (1999)", Genres: []string{"Action"}, Year: 1999, RatingCount: 100, AvgRating: 4.5},
+		{MovieID: "4", Title: "Bad Movie (2003)", Genres: []string{"Comedy"}, Year: 2003, RatingCount: 50, AvgRating: 2},
+		{MovieID: "5", Title: "Unrated (2010)", Genres: []string{"Comedy"}, Year: 2010},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	snapshot := Build(chartDocuments(), 10)
+
+	// 全库平均分按评分人数加权：(400+5+450+100)/251
+	if want := 955.0 / 251; math.Abs(snapshot.Mean-want) > 1e-9 {
+		t.Errorf("Mean = %v, want %v", snapshot.Mean, want)
+	}
+	// 没有评分的电影不参与排行
+	if snapshot.Len() != 4 {
+		t.Errorf("Len = %d, want 4", snapshot.Len())
+	}
+
+	empty := Build(nil, 10)
+	if empty.Mean != 0 || empty.Len() != 0 {
+		t.Errorf("Build(nil) = mean %v len %d, want 0, 0", empty.Mean, empty.Len())
+	}
+}
+
+func TestTop(t *testing.T) {
+	snapshot := Build(chartDocuments(), 10)
+
+	tests := []struct {
+		name   string
+		genre  string
+		decade int
+		limit  int
+		want   []string
+	}{
+		{"全部", "", 0, 10, []string{"3", "1", "2", "4"}},
+		{"条数限制", "", 0, 2, []string{"3", "1"}},
+		{"类型不区分大小写", "comedy", 0, 10, []string{"1", "4"}},
+		{"年代", "", 1990, 10, []string{"3", "1", "2"}},
+		{"类型和年代", "Comedy", 2000, 10, []string{"4"}},
+		{"没有匹配", "Horror", 0, 10, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := []string{}
+			for _, entry := range snapshot.Top(tt.genre, tt.decade, tt.limit) {
+				ids = append(ids, entry.Document.MovieID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("Top(%q, %d, %d) = %v, want %v", tt.genre, tt.decade, tt.limit, ids, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package utils
+
+// StopEngines 停止搜索索引、排行、相似电影和推荐模型的后台刷新，服务关闭时调用
+func StopEngines() {
+	if SearchEngine != nil {
+		SearchEngine.Stop()
+	}
+	if ChartEngine != nil {
+		ChartEngine.Stop()
+	}
+	if SimilarEngine != nil {
+		SimilarEngine.Stop()
+	}
+	if Recommender != nil {
+		Recommender.Stop()
+	}
+}
@@ -2,17 +2,18 @@ package utils
 
 import (
 	"context"
+	"fmt"
 	"gohbase/config"
 	"gohbase/utils/hbase"
-	"strconv"
+	"gohbase/utils/store"
+	"io"
+	"sort"
 	"strings"
 
+	"github.com/sirupsen/logrus"
 	"github.com/tsuna/gohbase"
-	"github.com/tsuna/gohbase/hrpc"
 )
 
-var hbaseClient gohbase.Client
-
 // InitHBase 初始化HBase客户端
 func InitHBase(conf *config.HBaseConfig) error {
 	return hbase.InitHBase(conf)
@@ -26,17 +27,22 @@ func GetClient() gohbase.Client {
 
 // GetMovie 根据ID获取电影信息
 func GetMovie(ctx context.Context, movieID string) (map[string]map[string][]byte, error) {
-	return hbase.GetMovie(ctx, movieID)
+	return Store.GetMovie(ctx, movieID)
 }
 
 // GetMovieWithFamilies 根据ID和指定的列族获取电影信息
 func GetMovieWithFamilies(ctx context.Context, movieID string, families []string) (map[string]map[string][]byte, error) {
-	return hbase.GetMovieWithFamilies(ctx, movieID, families)
+	return Store.GetMovieWithFamilies(ctx, movieID, families)
 }
 
 // GetMoviesMultiple 根据多个ID获取电影信息
 func GetMoviesMultiple(ctx context.Context, movieIDs []string) (map[string]map[string]map[string][]byte, error) {
-	return hbase.GetMoviesMultiple(ctx, movieIDs)
+	return Store.GetMoviesMultiple(ctx, movieIDs)
+}
+
+// GetMoviesMultipleWithFamilies 根据多个ID和指定的列族获取电影信息
+func GetMoviesMultipleWithFamilies(ctx context.Context, movieIDs []string, families []string) (map[string]map[string]map[string][]byte, error) {
+	return Store.GetMoviesMultipleWithFamilies(ctx, movieIDs, families)
 }
 
 // ParseMovieData 从HBase结果解析电影数据
@@ -44,160 +50,389 @@ func ParseMovieData(movieID string, data map[string]map[string][]byte) map[strin
 	return hbase.ParseMovieData(movieID, data)
 }
 
-// ScanMovies 扫描电影列表（带缓存）
-func ScanMovies(ctx context.Context, startRow, endRow string, limit int64) ([]*hrpc.Result, error) {
-	return hbase.ScanMovies(ctx, startRow, endRow, limit)
+// ScanMovies 扫描电影列表
+func ScanMovies(ctx context.Context, startRow, endRow string, limit int64) ([]store.Row, error) {
+	return Store.ScanMovies(ctx, startRow, endRow, limit)
+}
+
+// ScanMoviesBefore 倒序扫描行键小于 beforeRow 的电影，用于向前翻页
+func ScanMoviesBefore(ctx context.Context, beforeRow string, limit int64) ([]store.Row, error) {
+	return Store.ScanMoviesBefore(ctx, beforeRow, limit)
 }
 
 // ScanMoviesWithFamilies 带特定列族的电影列表扫描
-func ScanMoviesWithFamilies(ctx context.Context, startRow, endRow string, families []string, limit int64) ([]*hrpc.Result, error) {
-	return hbase.ScanMoviesWithFamilies(ctx, startRow, endRow, families, limit)
+func ScanMoviesWithFamilies(ctx context.Context, startRow, endRow string, families []string, limit int64) ([]store.Row, error) {
+	return Store.ScanMoviesWithFamilies(ctx, startRow, endRow, families, limit)
 }
 
-// ScanMoviesByGenre 按类型扫描电影
-func ScanMoviesByGenre(ctx context.Context, genre string, limit int64) ([]*hrpc.Result, error) {
-	return hbase.ScanMoviesByGenre(ctx, genre, limit)
+// GetMoviesByGenre 通过二级索引获取指定类型的电影ID
+func GetMoviesByGenre(ctx context.Context, genre string, limit int64) ([]string, error) {
+	return Store.GetMoviesByGenre(ctx, genre, limit)
 }
 
-// ScanMoviesByTag 按标签扫描电影
-func ScanMoviesByTag(ctx context.Context, tag string, limit int64) ([]*hrpc.Result, error) {
-	return hbase.ScanMoviesByTag(ctx, tag, limit)
+// GetMoviesByTag 通过二级索引获取带有指定标签的电影ID
+func GetMoviesByTag(ctx context.Context, tag string, limit int64) ([]string, error) {
+	return Store.GetMoviesByTag(ctx, tag, limit)
 }
 
-// ScanMoviesWithPagination 扫描电影列表并支持分页
-func ScanMoviesWithPagination(ctx context.Context, page, pageSize int) ([]*hrpc.Result, int, error) {
-	return hbase.ScanMoviesWithPagination(ctx, page, pageSize)
+// SearchMovies 通过二级索引按标题单词前缀或类型前缀搜索电影ID
+func SearchMovies(ctx context.Context, query string, limit int64) ([]string, error) {
+	return Store.SearchMovies(ctx, query, limit)
+}
+
+// ForEachMovie 遍历全部电影
+func ForEachMovie(ctx context.Context, families []string, fn func(store.Row) error) error {
+	return Store.ForEachMovie(ctx, families, fn)
 }
 
 // GetMovieRatingStats 获取电影评分统计信息
 func GetMovieRatingStats(ctx context.Context, movieID string) (map[string]float64, error) {
-	return hbase.GetMovieRatingStats(ctx, movieID)
+	return Store.GetMovieRatingStats(ctx, movieID)
 }
 
 // GetMoviesByRatingRange 获取特定评分范围内的电影
 func GetMoviesByRatingRange(ctx context.Context, minRating, maxRating float64, limit int64) ([]string, error) {
-	// 由于HBase不支持直接的数值范围查询，我们需要扫描所有电影并在应用层过滤
-	// 注意：这种方法在数据量大时效率较低，实际应用中应考虑建立二级索引或使用其他辅助表
+	return Store.GetMoviesByRatingRange(ctx, minRating, maxRating, limit)
+}
 
-	// 创建扫描请求，只获取评分列族
-	scan, err := hrpc.NewScanStr(ctx, "moviedata",
-		hrpc.Families(map[string][]string{"rating": nil}))
+// GetMoviesByYearRange 获取特定年份范围内的电影
+func GetMoviesByYearRange(ctx context.Context, yearFrom, yearTo int, limit int64) ([]string, error) {
+	return Store.GetMoviesByYearRange(ctx, yearFrom, yearTo, limit)
+}
+
+// GetMovieWithAllData 获取电影的所有数据，包括基本信息、链接、评分和标签
+func GetMovieWithAllData(ctx context.Context, movieID string) (map[string]interface{}, error) {
+	data, err := Store.GetMovie(ctx, movieID)
 	if err != nil {
 		return nil, err
 	}
 
-	// 获取扫描器
-	scanner := hbaseClient.Scan(scan)
+	// 如果电影不存在
+	if data == nil {
+		return nil, nil
+	}
+
+	return hbase.ParseMovieData(movieID, data), nil
+}
 
-	// 存储满足条件的电影ID
-	var matchedMovieIDs []string
+// EnableCompression 为表启用压缩功能
+func EnableCompression(compression string) error {
+	return hbase.EnableCompression(compression)
+}
+
+// GetMovieRatings 获取电影的所有评分，按用户ID排序
+func GetMovieRatings(ctx context.Context, movieID string) ([]store.UserRating, error) {
+	return Store.GetMovieRatings(ctx, movieID)
+}
+
+// GetMovieTags 获取电影的所有标签
+func GetMovieTags(ctx context.Context, movieID string) (map[string]map[string][]byte, error) {
+	return Store.GetMovieTags(ctx, movieID)
+}
+
+// GetUserTaggedMovies 获取用户打过标签的电影ID
+func GetUserTaggedMovies(ctx context.Context, userID string) ([]string, error) {
+	return Store.GetUserTaggedMovies(ctx, userID)
+}
+
+// GetUserRating 获取特定用户对电影的评分
+func GetUserRating(ctx context.Context, movieID string, userID string) (float64, int64, error) {
+	return Store.GetUserRating(ctx, movieID, userID)
+}
+
+// CountMovies 统计电影总数（不使用缓存）
+func CountMovies(ctx context.Context) (int, error) {
+	return Store.CountMovies(ctx)
+}
+
+// GetTotalMoviesCount 获取电影总数
+func GetTotalMoviesCount(ctx context.Context) (int, error) {
+	// 使用缓存优化性能，并发未命中时只统计一次
+	// 统计使用脱离取消信号的上下文，避免发起统计的请求断开导致等待方和过期后的后台刷新一起失败
+	loadCtx := context.WithoutCancel(ctx)
+	count, err := Cache.GetOrLoad("total_movies_count", func() (interface{}, error) {
+		return Store.CountMovies(loadCtx)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count.(int), nil
+}
+
+// PutRating 仅当 rating:{userId} 列仍为 expected（读取到的原值，未评分时为空）时写入评分，返回是否写入
+func PutRating(ctx context.Context, movieID, userID string, expected []byte, rating float64, timestamp int64) (bool, error) {
+	return Store.PutRating(ctx, movieID, userID, expected, rating, timestamp)
+}
+
+// DeleteRating 仅当 rating:{userId} 列仍为 expected 时删除评分，返回是否删除
+func DeleteRating(ctx context.Context, movieID, userID string, expected []byte) (bool, error) {
+	return Store.DeleteRating(ctx, movieID, userID, expected)
+}
+
+// DeleteRatingRow 删除用户对电影的旧版评分行
+func DeleteRatingRow(ctx context.Context, movieID, userID string) error {
+	return Store.DeleteRatingRow(ctx, movieID, userID)
+}
+
+// GetRatingAggregate 获取电影评分聚合数据，尚未建立聚合时根据该电影的全部原始评分（含旧版评分行）计算
+func GetRatingAggregate(ctx context.Context, movieID string) (*store.RatingAggregate, error) {
+	aggregate, err := Store.GetRatingAggregate(ctx, movieID)
+	if err != nil || aggregate != nil {
+		return aggregate, err
+	}
+
+	ratings, err := Store.GetMovieRatings(ctx, movieID)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.ComputeRatingAggregate(ratings), nil
+}
+
+// UpdateRatingAggregate 评分变化后更新聚合数据
+func UpdateRatingAggregate(ctx context.Context, movieID string, oldRating, newRating float64) error {
+	return Store.UpdateRatingAggregate(ctx, movieID, oldRating, newRating)
+}
+
+// GetRatingTimeline 获取电影按天统计的评分
+func GetRatingTimeline(ctx context.Context, movieID string) ([]store.DayStat, error) {
+	return Store.GetRatingTimeline(ctx, movieID)
+}
+
+// UpdateRatingTimeline 评分变化后更新按天统计
+func UpdateRatingTimeline(ctx context.Context, movieID string, old, updated store.UserRating) error {
+	return Store.UpdateRatingTimeline(ctx, movieID, old, updated)
+}
+
+// RebuildRatingAggregates 根据原始评分（含旧版评分行）重建聚合数据及按天统计，movieIDs 为空时重建全部有评分的电影，返回重建数量
+// 旧版评分行计入所属电影，不会在旧版评分行的行键下写入聚合
+func RebuildRatingAggregates(ctx context.Context, movieIDs []string) (int, error) {
+	rebuilt := 0
+	rebuild := func(movieID string, ratings []store.UserRating) error {
+		if err := Store.PutRatingAggregate(ctx, movieID, store.ComputeRatingAggregate(ratings)); err != nil {
+			return fmt.Errorf("重建电影 %s 的评分聚合失败: %w", movieID, err)
+		}
+		if err := Store.PutRatingTimeline(ctx, movieID, ratings); err != nil {
+			return fmt.Errorf("重建电影 %s 的按天评分统计失败: %w", movieID, err)
+		}
+		rebuilt++
+		return nil
+	}
 
-	// 扫描所有电影
-	for {
-		res, err := scanner.Next()
+	if len(movieIDs) == 0 {
+		ratings, err := loadAllRatings(ctx)
 		if err != nil {
-			break // 到达结尾或发生错误，终止循环
+			return 0, err
 		}
+		movieIDs = make([]string, 0, len(ratings))
+		for movieID := range ratings {
+			movieIDs = append(movieIDs, movieID)
+		}
+		sort.Strings(movieIDs)
 
-		if len(res.Cells) == 0 {
-			continue
+		for _, movieID := range movieIDs {
+			if err := rebuild(movieID, ratings[movieID]); err != nil {
+				return rebuilt, err
+			}
 		}
+		return rebuilt, nil
+	}
 
-		// 获取电影ID
-		movieID := string(res.Cells[0].Row)
+	for _, movieID := range movieIDs {
+		ratings, err := Store.GetMovieRatings(ctx, movieID)
+		if err != nil {
+			return rebuilt, err
+		}
+		if err := rebuild(movieID, ratings); err != nil {
+			return rebuilt, err
+		}
+	}
 
-		// 解析评分数据
-		var sumRating float64
-		var count int
+	return rebuilt, nil
+}
 
-		// 遍历所有单元格
-		for _, cell := range res.Cells {
-			qualifier := string(cell.Qualifier)
+// GetUserRatings 从用户评分表读取用户的全部评分，按 movieId 排序
+func GetUserRatings(ctx context.Context, userID string) ([]store.MovieRating, error) {
+	return Store.GetUserRatings(ctx, userID)
+}
+
+// PutUserRating 在用户评分表中写入评分，与 PutRating 配合使用
+func PutUserRating(ctx context.Context, userID, movieID string, rating float64, timestamp int64) error {
+	return Store.PutUserRating(ctx, userID, movieID, rating, timestamp)
+}
+
+// DeleteUserRating 从用户评分表中删除评分，与 DeleteRating 配合使用
+func DeleteUserRating(ctx context.Context, userID, movieID string) error {
+	return Store.DeleteUserRating(ctx, userID, movieID)
+}
+
+// RebuildUserRatings 根据 moviedata 中的全部评分重建用户评分表，返回处理的用户数和评分数
+// 先扫描用户评分表删除 moviedata 中已不存在的评分（包括已没有任何评分的用户），再写入全部评分
+func RebuildUserRatings(ctx context.Context) (int, int, error) {
+	ratings, err := loadAllRatings(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
 
-			// 只处理评分列，不处理时间戳列
-			if strings.HasPrefix(qualifier, "rating:") {
-				rating, err := strconv.ParseFloat(string(cell.Value), 64)
-				if err == nil {
-					sumRating += rating
-					count++
-				}
+	byUser := make(map[string]map[string]store.UserRating)
+	for movieID, movieRatings := range ratings {
+		for _, rating := range movieRatings {
+			if byUser[rating.UserID] == nil {
+				byUser[rating.UserID] = make(map[string]store.UserRating)
 			}
+			byUser[rating.UserID][movieID] = rating
 		}
+	}
 
-		// 计算平均评分
-		var avgRating float64
-		if count > 0 {
-			avgRating = sumRating / float64(count)
-
-			// 检查评分是否在范围内
-			if avgRating >= minRating && avgRating <= maxRating {
-				matchedMovieIDs = append(matchedMovieIDs, movieID)
+	err = Store.ForEachUserRatings(ctx, func(row store.Row) error {
+		desired := byUser[row.Key]
+		for _, movieID := range store.UserRatingMovieIDs(row.Data[store.UserRatingsFamily]) {
+			if _, ok := desired[movieID]; ok {
+				continue
+			}
+			if err := Store.DeleteUserRating(ctx, row.Key, movieID); err != nil {
+				return fmt.Errorf("删除用户 %s 的评分失败: %w", row.Key, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
 
-				// 如果达到限制数量，则停止扫描
-				if int64(len(matchedMovieIDs)) >= limit {
-					break
-				}
+	written := 0
+	for userID, desired := range byUser {
+		for movieID, rating := range desired {
+			if err := Store.PutUserRating(ctx, userID, movieID, rating.Rating, rating.Timestamp); err != nil {
+				return 0, written, fmt.Errorf("写入用户 %s 的评分失败: %w", userID, err)
 			}
+			written++
 		}
 	}
 
-	return matchedMovieIDs, nil
+	return len(byUser), written, nil
 }
 
-// GetMovieWithAllData 获取电影的所有数据，包括基本信息、链接、评分和标签
-func GetMovieWithAllData(ctx context.Context, movieID string) (map[string]interface{}, error) {
-	return hbase.GetMovieWithAllData(ctx, movieID)
+// loadAllRatings 遍历电影表读取全部有评分的电影的评分，兼容 {movieId}_{userId} 形式的旧版评分行，同一用户以电影行中的评分为准
+// 同时读取 movie 列族以区分电影行，只有旧版评分行的电影也能读到评分，电影已不存在的旧版评分行被忽略
+func loadAllRatings(ctx context.Context) (map[string][]store.UserRating, error) {
+	ratings := make(map[string][]store.UserRating)
+	movies := make(map[string]bool)
+	legacy := make(map[string][]store.Row)
+	err := Store.ForEachMovie(ctx, []string{"movie", "rating"}, func(row store.Row) error {
+		if store.IsRatingRowKey(row.Key) {
+			movieID, _, _ := strings.Cut(row.Key, store.RatingRowSeparator)
+			legacy[movieID] = append(legacy[movieID], row)
+			return nil
+		}
+		if !store.IsMovieRow(row) {
+			return nil
+		}
+		movies[row.Key] = true
+		if parsed := store.ParseRatings(row.Data["rating"]); len(parsed) > 0 {
+			ratings[row.Key] = parsed
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for movieID, rows := range legacy {
+		if movies[movieID] {
+			ratings[movieID] = store.MergeRatings(ratings[movieID], rows, movieID)
+		}
+	}
+	return ratings, nil
 }
 
-// EnableCompression 为表启用压缩功能
-func EnableCompression(compression string) error {
-	return hbase.EnableCompression(compression)
+// PutTag 仅当用户还没有给电影打标签时写入标签，返回是否写入
+func PutTag(ctx context.Context, movieID, userID, tag string) (bool, error) {
+	return Store.PutTag(ctx, movieID, userID, tag)
 }
 
-// GetMovieRatings 获取电影的所有评分
-func GetMovieRatings(ctx context.Context, movieID string) (map[string]interface{}, error) {
-	return hbase.GetMovieRatings(ctx, movieID)
+// DeleteTag 删除用户对电影的标签
+func DeleteTag(ctx context.Context, movieID, userID string) error {
+	return Store.DeleteTag(ctx, movieID, userID)
 }
 
-// GetMovieTags 获取电影的所有标签
-func GetMovieTags(ctx context.Context, movieID string) (map[string]map[string][]byte, error) {
-	return hbase.GetMovieTags(ctx, movieID)
+// GetPendingTag 获取用户对电影的待审核标签，不存在时返回 nil
+func GetPendingTag(ctx context.Context, movieID, userID string) (*store.PendingTag, error) {
+	return Store.GetPendingTag(ctx, movieID, userID)
 }
 
-// GetUserRating 获取特定用户对电影的评分
-func GetUserRating(ctx context.Context, movieID string, userID string) (float64, int64, error) {
-	return hbase.GetUserRating(ctx, movieID, userID)
+// ListPendingTags 获取最早提交的 limit 个待审核标签
+func ListPendingTags(ctx context.Context, limit int64) ([]store.PendingTag, error) {
+	return Store.ListPendingTags(ctx, limit)
 }
 
-// GetTotalMoviesCount 获取电影总数
-func GetTotalMoviesCount(ctx context.Context) (int, error) {
-	// 使用缓存优化性能
-	cacheKey := "total_movies_count"
-	if cachedCount, found := Cache.Get(cacheKey); found {
-		return cachedCount.(int), nil
+// PutPendingTag 将标签加入待审核队列，用户对该电影已有待审核标签时返回 false
+func PutPendingTag(ctx context.Context, pending store.PendingTag) (bool, error) {
+	return Store.PutPendingTag(ctx, pending)
+}
+
+// DeletePendingTag 将标签移出待审核队列
+func DeletePendingTag(ctx context.Context, movieID, userID string) error {
+	return Store.DeletePendingTag(ctx, movieID, userID)
+}
+
+// UpdateMovieIndex 电影数据变化后同步其二级索引条目
+func UpdateMovieIndex(ctx context.Context, movieID string) error {
+	return Store.UpdateMovieIndex(ctx, movieID)
+}
+
+// RebuildMovieIndex 重建二级索引，movieIDs 为空时重建全部电影，返回处理的电影数量
+func RebuildMovieIndex(ctx context.Context, movieIDs []string) (int, error) {
+	if len(movieIDs) == 0 {
+		err := Store.ForEachMovie(ctx, []string{"movie"}, func(row store.Row) error {
+			if !store.IsMovieRow(row) {
+				return nil
+			}
+			movieIDs = append(movieIDs, row.Key)
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
 	}
 
-	// 构建扫描请求，只获取行键以提高效率
-	scanRequest, err := hrpc.NewScanStr(ctx, "moviedata")
+	for i, movieID := range movieIDs {
+		if err := Store.UpdateMovieIndex(ctx, movieID); err != nil {
+			return i, fmt.Errorf("重建电影 %s 的索引失败: %w", movieID, err)
+		}
+	}
+
+	return len(movieIDs), nil
+}
+
+// ImportAliases 从 movieId,标题 格式的CSV导入中文标题，不存在的电影被跳过，返回导入数量
+func ImportAliases(ctx context.Context, r io.Reader) (int, error) {
+	aliases, err := store.ParseAliasesCSV(r)
 	if err != nil {
 		return 0, err
 	}
 
-	// 执行扫描
-	scanner := hbaseClient.Scan(scanRequest)
-	count := 0
-
-	// 计算总行数
-	for {
-		_, err := scanner.Next()
+	imported := 0
+	for _, alias := range aliases {
+		data, err := Store.GetMovieWithFamilies(ctx, alias.MovieID, []string{"movie"})
 		if err != nil {
-			break
+			return imported, err
+		}
+		if data == nil {
+			logrus.Warnf("电影 %s 不存在，跳过中文标题 %q", alias.MovieID, alias.Title)
+			continue
 		}
-		count++
-	}
 
-	// 将结果存入缓存（24小时有效）
-	Cache.Set(cacheKey, count)
+		if err := Store.PutAlias(ctx, alias.MovieID, store.LocaleZh, alias.Title); err != nil {
+			return imported, fmt.Errorf("写入电影 %s 的中文标题失败: %w", alias.MovieID, err)
+		}
+		// 中文标题同样写入标题索引，失败时只记录日志，可通过重建命令修复
+		if err := Store.UpdateMovieIndex(ctx, alias.MovieID); err != nil {
+			logrus.Errorf("更新电影 %s 的二级索引失败，请执行 rebuild-index 重建: %v", alias.MovieID, err)
+		}
+		imported++
+	}
 
-	return count, nil
+	return imported, nil
 }
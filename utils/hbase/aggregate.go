@@ -0,0 +1,98 @@
+package hbase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"gohbase/utils/store"
+	"math"
+)
+
+// maxCASRetries CheckAndPut 冲突时的最大重试次数
+const maxCASRetries = 5
+
+// GetRatingAggregate 读取电影的评分聚合数据，只需一次Get
+func GetRatingAggregate(ctx context.Context, movieID string) (*store.RatingAggregate, error) {
+	data, err := GetMovieWithFamilies(ctx, movieID, []string{store.StatsFamily})
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, nil
+	}
+
+	return store.ParseRatingAggregate(data[store.StatsFamily]), nil
+}
+
+// UpdateRatingAggregate 在评分变化时原子更新聚合数据
+// 计数、总和与直方图通过一次多列 Increment 同时更新，最低/最高评分根据直方图通过 CheckAndPut 更新
+func UpdateRatingAggregate(ctx context.Context, movieID string, oldRating, newRating float64) error {
+	deltas := map[string]int64{}
+	if oldRating > 0 {
+		deltas["count"]--
+		deltas["sum"] -= int64(math.Round(oldRating * 2))
+		deltas[store.HistogramQualifier(oldRating)]--
+	}
+	if newRating > 0 {
+		deltas["count"]++
+		deltas["sum"] += int64(math.Round(newRating * 2))
+		deltas[store.HistogramQualifier(newRating)]++
+	}
+
+	if err := incrementRow(ctx, "UpdateRatingAggregate", "moviedata", movieID, store.StatsFamily, deltas); err != nil {
+		return fmt.Errorf("更新评分聚合失败: %w", err)
+	}
+
+	return refreshRatingRange(ctx, movieID)
+}
+
+// refreshRatingRange 根据最新的直方图修正最低/最高评分，并发写入冲突时重试
+func refreshRatingRange(ctx context.Context, movieID string) error {
+	for attempt := 0; attempt < maxCASRetries; attempt++ {
+		data, err := GetMovieWithFamilies(ctx, movieID, []string{store.StatsFamily})
+		if err != nil {
+			return err
+		}
+
+		statsData := data[store.StatsFamily]
+		aggregate := store.ParseRatingAggregate(statsData)
+		if aggregate == nil {
+			return nil
+		}
+
+		min, max := aggregate.HistogramRange()
+		minOK, err := checkAndPutStat(ctx, movieID, "min", statsData["min"], store.EncodeRatingValue(min))
+		if err != nil {
+			return err
+		}
+		maxOK, err := checkAndPutStat(ctx, movieID, "max", statsData["max"], store.EncodeRatingValue(max))
+		if err != nil {
+			return err
+		}
+
+		if minOK && maxOK {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("更新电影 %s 的评分范围冲突次数过多", movieID)
+}
+
+// checkAndPutStat 仅当列值仍为 expected 时写入新值，值未变化时直接返回成功
+func checkAndPutStat(ctx context.Context, movieID, qualifier string, expected, value []byte) (bool, error) {
+	if bytes.Equal(expected, value) {
+		return true, nil
+	}
+
+	values := map[string]map[string][]byte{
+		store.StatsFamily: {qualifier: value},
+	}
+	return checkAndPutRow(ctx, "UpdateRatingAggregate", "moviedata", movieID, values, store.StatsFamily, qualifier, expected)
+}
+
+// PutRatingAggregate 覆盖写入评分聚合数据
+func PutRatingAggregate(ctx context.Context, movieID string, aggregate *store.RatingAggregate) error {
+	return putRow(ctx, "PutRatingAggregate", "moviedata", movieID, map[string]map[string][]byte{
+		store.StatsFamily: store.EncodeRatingAggregate(aggregate),
+	})
+}
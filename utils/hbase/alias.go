@@ -0,0 +1,17 @@
+package hbase
+
+import (
+	"context"
+	"gohbase/utils/store"
+)
+
+// PutAlias 写入电影的本地化标题，列格式为 alias:{locale}
+func PutAlias(ctx context.Context, movieID, locale, title string) error {
+	values := map[string]map[string][]byte{
+		store.AliasFamily: {
+			locale: []byte(title),
+		},
+	}
+
+	return putRow(ctx, "PutAlias", "moviedata", movieID, values)
+}
@@ -7,6 +7,11 @@ import (
 
 // GetMoviesMultiple 根据多个ID获取电影信息
 func GetMoviesMultiple(ctx context.Context, movieIDs []string) (map[string]map[string]map[string][]byte, error) {
+	return GetMoviesMultipleWithFamilies(ctx, movieIDs, nil)
+}
+
+// GetMoviesMultipleWithFamilies 根据多个ID和指定的列族获取电影信息，families 为空时读取所有列族
+func GetMoviesMultipleWithFamilies(ctx context.Context, movieIDs []string, families []string) (map[string]map[string]map[string][]byte, error) {
 	results := make(map[string]map[string]map[string][]byte)
 
 	// 使用goroutine并发获取多部电影信息
@@ -20,7 +25,13 @@ func GetMoviesMultiple(ctx context.Context, movieIDs []string) (map[string]map[s
 
 	for _, id := range movieIDs {
 		go func(movieID string) {
-			data, err := GetMovie(ctx, movieID)
+			get := GetMovie
+			if len(families) > 0 {
+				get = func(ctx context.Context, movieID string) (map[string]map[string][]byte, error) {
+					return GetMovieWithFamilies(ctx, movieID, families)
+				}
+			}
+			data, err := get(ctx, movieID)
 			resultChan <- result{id: movieID, data: data, err: err}
 		}(id)
 	}
@@ -7,7 +7,6 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/tsuna/gohbase"
-	"github.com/tsuna/gohbase/hrpc"
 )
 
 var hbaseClient gohbase.Client
@@ -23,13 +22,7 @@ func InitHBase(conf *config.HBaseConfig) error {
 	// 测试连接是否成功
 	ctx := context.Background()
 	// 尝试获取一条记录来测试连接
-	get, err := hrpc.NewGetStr(ctx, "moviedata", "1")
-	if err != nil {
-		logrus.Errorf("创建Get请求失败: %v", err)
-		return err
-	}
-
-	_, err = hbaseClient.Get(get)
+	_, err := getRow(ctx, "InitHBase", "moviedata", "1")
 	if err != nil {
 		logrus.Errorf("HBase连接失败: %v", err)
 		return err
@@ -0,0 +1,111 @@
+package hbase
+
+import (
+	"context"
+	"fmt"
+	"gohbase/utils/store"
+	"io"
+	"sort"
+
+	"github.com/tsuna/gohbase/hrpc"
+)
+
+// scanIndex 扫描索引表 [startRow, stopRow)，返回去重后的 movieId，limit 小于0表示不限
+func scanIndex(ctx context.Context, site, startRow, stopRow string, limit int64) ([]string, error) {
+	scanner, err := openScanner(ctx, site, store.IndexTable, startRow, stopRow,
+		hrpc.Families(map[string][]string{store.IndexFamily: nil}))
+	if err != nil {
+		return nil, err
+	}
+	defer scanner.Close()
+
+	seen := make(map[string]bool)
+	var movieIDs []string
+
+	for limit < 0 || int64(len(movieIDs)) < limit {
+		result, err := scanner.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Cells) == 0 {
+			continue
+		}
+
+		movieID := store.IndexMovieID(string(result.Cells[0].Row))
+		if !seen[movieID] {
+			seen[movieID] = true
+			movieIDs = append(movieIDs, movieID)
+		}
+	}
+
+	return movieIDs, nil
+}
+
+// UpdateMovieIndex 根据电影当前数据同步其二级索引条目，电影不存在时删除其全部条目
+func UpdateMovieIndex(ctx context.Context, movieID string) error {
+	data, err := GetMovieWithFamilies(ctx, movieID, store.IndexFamilies)
+	if err != nil {
+		return err
+	}
+	desired := []string{}
+	if data != nil {
+		desired = store.MovieIndexKeys(movieID, data)
+	}
+
+	reverseKey := store.ReverseIndexKey(movieID)
+	reverse, err := getRow(ctx, "UpdateMovieIndex", store.IndexTable, reverseKey,
+		hrpc.Families(map[string][]string{store.IndexFamily: nil}))
+	if err != nil {
+		return err
+	}
+	current := make([]string, 0, len(reverse.Cells))
+	for _, cell := range reverse.Cells {
+		current = append(current, string(cell.Qualifier))
+	}
+	sort.Strings(current)
+
+	added, removed := store.DiffIndexKeys(current, desired)
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	// 先写入新条目再登记到反向行，中途失败时反向行不会遗漏已写入的条目
+	for _, key := range added {
+		values := map[string]map[string][]byte{store.IndexFamily: {"movieId": []byte(movieID)}}
+		if err := putRow(ctx, "UpdateMovieIndex", store.IndexTable, key, values); err != nil {
+			return fmt.Errorf("写入索引 %s 失败: %w", key, err)
+		}
+	}
+	if len(added) > 0 {
+		columns := make(map[string][]byte, len(added))
+		for _, key := range added {
+			columns[key] = []byte{}
+		}
+		values := map[string]map[string][]byte{store.IndexFamily: columns}
+		if err := putRow(ctx, "UpdateMovieIndex", store.IndexTable, reverseKey, values); err != nil {
+			return err
+		}
+	}
+
+	// 先删除旧条目再从反向行移除登记
+	for _, key := range removed {
+		if err := deleteRow(ctx, "UpdateMovieIndex", store.IndexTable, key, nil); err != nil {
+			return fmt.Errorf("删除索引 %s 失败: %w", key, err)
+		}
+	}
+	if len(removed) > 0 {
+		columns := make(map[string][]byte, len(removed))
+		for _, key := range removed {
+			columns[key] = nil
+		}
+		values := map[string]map[string][]byte{store.IndexFamily: columns}
+		if err := deleteRow(ctx, "UpdateMovieIndex", store.IndexTable, reverseKey, values); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
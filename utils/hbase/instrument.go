@@ -0,0 +1,185 @@
+package hbase
+
+import (
+	"context"
+	"gohbase/utils/metrics"
+	"gohbase/utils/store"
+	"gohbase/utils/tracing"
+	"io"
+	"time"
+
+	"github.com/tsuna/gohbase/hrpc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// call 一次HBase调用，负责上报监控指标和结束追踪 span
+type call struct {
+	site  string
+	op    string
+	start time.Time
+	span  trace.Span
+}
+
+// startCall 开始一次HBase调用，返回的 context 携带子 span，需用于构造 hrpc 请求
+func startCall(ctx context.Context, site, op, table string, attrs ...attribute.KeyValue) (context.Context, *call) {
+	attrs = append(attrs,
+		attribute.String("db.system", "hbase"),
+		attribute.String("db.operation", op),
+		attribute.String("hbase.table", table),
+		attribute.String("hbase.site", site),
+	)
+	ctx, span := tracing.Tracer().Start(ctx, "hbase."+op+" "+site,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+
+	return ctx, &call{site: site, op: op, start: time.Now(), span: span}
+}
+
+// end 结束调用并记录耗时和错误
+func (c *call) end(err error) {
+	metrics.ObserveHBaseCall(c.site, c.op, c.start, err)
+	if err != nil {
+		c.span.RecordError(err)
+		c.span.SetStatus(codes.Error, err.Error())
+	}
+	c.span.End()
+}
+
+// instrumentedScanner 记录扫描耗时、读取行数和错误的扫描器
+type instrumentedScanner struct {
+	hrpc.Scanner
+	call   *call
+	rows   int
+	err    error
+	closed bool
+}
+
+// openScanner 打开带监控和追踪的扫描器，扫描 [startRow, stopRow)，使用完毕后需调用 Close
+func openScanner(ctx context.Context, site, table, startRow, stopRow string, options ...func(hrpc.Call) error) (*instrumentedScanner, error) {
+	ctx, c := startCall(ctx, site, "scan", table,
+		attribute.String("hbase.start_row", startRow),
+		attribute.String("hbase.stop_row", stopRow))
+
+	scanRequest, err := hrpc.NewScanRangeStr(ctx, table, startRow, stopRow, options...)
+	if err != nil {
+		c.end(err)
+		return nil, err
+	}
+
+	return &instrumentedScanner{
+		Scanner: hbaseClient.Scan(scanRequest),
+		call:    c,
+	}, nil
+}
+
+// Next 读取下一行
+func (s *instrumentedScanner) Next() (*hrpc.Result, error) {
+	result, err := s.Scanner.Next()
+	if err == nil {
+		s.rows++
+	} else if err != io.EOF {
+		s.err = err
+	}
+	return result, err
+}
+
+// Close 关闭扫描器并上报监控数据，可重复调用
+func (s *instrumentedScanner) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	metrics.ObserveHBaseRows(s.call.site, s.rows)
+	s.call.span.SetAttributes(attribute.Int("hbase.rows_scanned", s.rows))
+	s.call.end(s.err)
+	return s.Scanner.Close()
+}
+
+// getRow 执行带监控和追踪的Get请求
+func getRow(ctx context.Context, site, table, key string, options ...func(hrpc.Call) error) (*hrpc.Result, error) {
+	ctx, c := startCall(ctx, site, "get", table, attribute.String("hbase.row", key))
+
+	getRequest, err := hrpc.NewGetStr(ctx, table, key, options...)
+	if err != nil {
+		c.end(err)
+		return nil, err
+	}
+
+	result, err := hbaseClient.Get(getRequest)
+	if err == nil {
+		c.span.SetAttributes(attribute.Int("hbase.cells", len(result.Cells)))
+	}
+	c.end(err)
+	return result, err
+}
+
+// putRow 执行带监控和追踪的Put请求
+func putRow(ctx context.Context, site, table, key string, values map[string]map[string][]byte) error {
+	ctx, c := startCall(ctx, site, "put", table, attribute.String("hbase.row", key))
+
+	putRequest, err := hrpc.NewPutStr(ctx, table, key, values)
+	if err == nil {
+		_, err = hbaseClient.Put(putRequest)
+	}
+	c.end(err)
+	return err
+}
+
+// deleteRow 执行带监控和追踪的Delete请求，values 为 nil 时删除整行
+func deleteRow(ctx context.Context, site, table, key string, values map[string]map[string][]byte) error {
+	ctx, c := startCall(ctx, site, "delete", table, attribute.String("hbase.row", key))
+
+	delRequest, err := hrpc.NewDelStr(ctx, table, key, values)
+	if err == nil {
+		_, err = hbaseClient.Delete(delRequest)
+	}
+	c.end(err)
+	return err
+}
+
+// incrementRow 执行带监控和追踪的多列Increment请求，同一行的所有列在HBase中原子更新，amount 为0的列被忽略
+// 客户端的 Increment 只接受单列结果，这里通过 SendBatch 发送
+func incrementRow(ctx context.Context, site, table, key, family string, amounts map[string]int64) error {
+	columns := make(map[string][]byte, len(amounts))
+	for qualifier, amount := range amounts {
+		if amount != 0 {
+			columns[qualifier] = store.EncodeCounter(amount)
+		}
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+
+	ctx, c := startCall(ctx, site, "increment", table,
+		attribute.String("hbase.row", key),
+		attribute.String("hbase.family", family),
+		attribute.Int("hbase.columns", len(columns)))
+
+	incRequest, err := hrpc.NewIncStr(ctx, table, key, map[string]map[string][]byte{family: columns})
+	if err == nil {
+		results, _ := hbaseClient.SendBatch(ctx, []hrpc.Call{incRequest})
+		err = results[0].Error
+	}
+	c.end(err)
+	return err
+}
+
+// checkAndPutRow 执行带监控和追踪的CheckAndPut请求，仅当 family:qualifier 的值等于 expected 时写入
+func checkAndPutRow(ctx context.Context, site, table, key string, values map[string]map[string][]byte,
+	family, qualifier string, expected []byte) (bool, error) {
+	ctx, c := startCall(ctx, site, "check_and_put", table,
+		attribute.String("hbase.row", key),
+		attribute.String("hbase.column", family+":"+qualifier))
+
+	var ok bool
+	putRequest, err := hrpc.NewPutStr(ctx, table, key, values)
+	if err == nil {
+		ok, err = hbaseClient.CheckAndPut(putRequest, family, qualifier, expected)
+	}
+	c.span.SetAttributes(attribute.Bool("hbase.processed", ok))
+	c.end(err)
+	return ok, err
+}
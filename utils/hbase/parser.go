@@ -22,6 +22,11 @@ func ParseMovieData(movieID string, data map[string]map[string][]byte) map[strin
 		}
 	}
 
+	// 处理中文标题
+	if localizedTitle, ok := data["alias"]["zh"]; ok && len(localizedTitle) > 0 {
+		result["localizedTitle"] = string(localizedTitle)
+	}
+
 	// 处理链接信息
 	if linkData, ok := data["link"]; ok {
 		links := map[string]interface{}{}
@@ -0,0 +1,41 @@
+package hbase
+
+import (
+	"context"
+	"gohbase/utils/store"
+	"strconv"
+)
+
+// PutRating 写入用户对电影的评分，列格式为 rating:{userId} 和 timestamp:{userId}
+// 使用 CheckAndPut，仅当 rating:{userId} 仍为 expected（读取到的原值，未评分时为空）时写入，返回是否写入
+// 期望值使用读取到的原始字节，导入数据中格式不同的评分（如 "4"、"4.50"）也能匹配
+func PutRating(ctx context.Context, movieID, userID string, expected []byte, rating float64, timestamp int64) (bool, error) {
+	values := map[string]map[string][]byte{
+		"rating": {
+			"rating:" + userID:    store.EncodeRatingValue(rating),
+			"timestamp:" + userID: []byte(strconv.FormatInt(timestamp, 10)),
+		},
+	}
+
+	return checkAndPutRow(ctx, "PutRating", "moviedata", movieID, values,
+		"rating", "rating:"+userID, expected)
+}
+
+// DeleteRating 删除用户对电影的评分及其时间戳，仅当 rating:{userId} 仍为 expected 时删除，返回是否删除
+// HBase 客户端不支持条件删除，这里以 CheckAndPut 将两列写为空值，读取时空值与未评分等同
+func DeleteRating(ctx context.Context, movieID, userID string, expected []byte) (bool, error) {
+	values := map[string]map[string][]byte{
+		"rating": {
+			"rating:" + userID:    {},
+			"timestamp:" + userID: {},
+		},
+	}
+
+	return checkAndPutRow(ctx, "DeleteRating", "moviedata", movieID, values,
+		"rating", "rating:"+userID, expected)
+}
+
+// DeleteRatingRow 删除以 {movieId}_{userId} 为行键的旧版评分行（整行）
+func DeleteRatingRow(ctx context.Context, movieID, userID string) error {
+	return deleteRow(ctx, "DeleteRatingRow", "moviedata", store.RatingRowPrefix(movieID)+userID, nil)
+}
@@ -2,232 +2,193 @@ package hbase
 
 import (
 	"context"
-	"strings"
+	"gohbase/utils/store"
+	"io"
 
+	"github.com/tsuna/gohbase/filter"
 	"github.com/tsuna/gohbase/hrpc"
 )
 
-// ScanMovies 扫描电影
-func ScanMovies(ctx context.Context, startRow, endRow string, limit int64) ([]*hrpc.Result, error) {
-	// 构建Scan对象
-	scanRequest, err := hrpc.NewScanRangeStr(ctx, "moviedata", startRow, endRow)
+// ScanMovies 扫描电影，跳过旧版评分行等非电影行，直到凑满 limit 部电影
+func ScanMovies(ctx context.Context, startRow, endRow string, limit int64) ([]store.Row, error) {
+	// 执行扫描
+	scanner, err := openScanner(ctx, "ScanMovies", "moviedata", startRow, endRow)
 	if err != nil {
 		return nil, err
 	}
+	defer scanner.Close()
 
-	// 执行扫描
-	scanner := hbaseClient.Scan(scanRequest)
-	var results []*hrpc.Result
-	count := int64(0)
+	var results []store.Row
 
 	// 收集结果
-	for count < limit {
+	for int64(len(results)) < limit {
 		result, err := scanner.Next()
-		if err != nil {
+		if err == io.EOF {
 			break
 		}
-		results = append(results, result)
-		count++
+		if err != nil {
+			return nil, err
+		}
+
+		row := resultToRow(result)
+		if !store.IsMovieRow(row) {
+			continue
+		}
+		results = append(results, row)
 	}
 
 	return results, nil
 }
 
-// ScanMoviesWithFamilies 使用指定列族扫描电影
-func ScanMoviesWithFamilies(ctx context.Context, startRow, endRow string, families []string, limit int64) ([]*hrpc.Result, error) {
-	// 构建列族映射
-	familiesMap := make(map[string][]string)
-	for _, family := range families {
-		familiesMap[family] = nil
-	}
-
-	// 构建Scan对象，并指定列族
-	scanRequest, err := hrpc.NewScanRangeStr(ctx, "moviedata", startRow, endRow, hrpc.Families(familiesMap))
+// ScanMoviesBefore 倒序扫描行键小于 beforeRow 的电影，beforeRow 为空时从最后一行开始
+// HBase 倒序扫描的起始行是包含在内的，因此需跳过 beforeRow 本身，非电影行同样跳过
+func ScanMoviesBefore(ctx context.Context, beforeRow string, limit int64) ([]store.Row, error) {
+	scanner, err := openScanner(ctx, "ScanMoviesBefore", "moviedata", beforeRow, "", hrpc.Reversed())
 	if err != nil {
 		return nil, err
 	}
+	defer scanner.Close()
 
-	// 执行扫描
-	scanner := hbaseClient.Scan(scanRequest)
-	var results []*hrpc.Result
-	count := int64(0)
-
-	// 收集结果
-	for count < limit {
+	var results []store.Row
+	for int64(len(results)) < limit {
 		result, err := scanner.Next()
-		if err != nil {
+		if err == io.EOF {
 			break
 		}
-		results = append(results, result)
-		count++
+		if err != nil {
+			return nil, err
+		}
+
+		row := resultToRow(result)
+		if row.Key == beforeRow || !store.IsMovieRow(row) {
+			continue
+		}
+		results = append(results, row)
 	}
 
 	return results, nil
 }
 
-// ScanMoviesByGenre 根据电影类型扫描电影
-func ScanMoviesByGenre(ctx context.Context, genre string, limit int64) ([]*hrpc.Result, error) {
-	// 简化为基本扫描，然后在应用层做过滤
-	scanRequest, err := hrpc.NewScanStr(ctx, "moviedata")
+// ScanMoviesWithFamilies 使用指定列族扫描电影，跳过旧版评分行
+// 指定的列族可能不含 movie 列族，因此只按行键过滤
+func ScanMoviesWithFamilies(ctx context.Context, startRow, endRow string, families []string, limit int64) ([]store.Row, error) {
+	// 执行扫描
+	scanner, err := openScanner(ctx, "ScanMoviesWithFamilies", "moviedata", startRow, endRow, hrpc.Families(familiesMap(families)))
 	if err != nil {
 		return nil, err
 	}
+	defer scanner.Close()
 
-	// 执行扫描
-	scanner := hbaseClient.Scan(scanRequest)
-	var results []*hrpc.Result
-	count := int64(0)
+	var results []store.Row
 
-	// 收集结果并筛选包含指定类型的电影
-	for count < limit {
+	// 收集结果
+	for int64(len(results)) < limit {
 		result, err := scanner.Next()
-		if err != nil {
+		if err == io.EOF {
 			break
 		}
+		if err != nil {
+			return nil, err
+		}
 
-		// 检查这个结果是否包含指定的类型
-		for _, cell := range result.Cells {
-			if string(cell.Family) == "movie" && string(cell.Qualifier) == "genres" {
-				genreValue := string(cell.Value)
-				if strings.Contains(strings.ToLower(genreValue), strings.ToLower(genre)) {
-					results = append(results, result)
-					count++
-					break
-				}
-			}
+		row := resultToRow(result)
+		if store.IsRatingRowKey(row.Key) {
+			continue
 		}
+		results = append(results, row)
 	}
 
 	return results, nil
 }
 
-// ScanMoviesByTag 根据标签扫描电影
-func ScanMoviesByTag(ctx context.Context, tag string, limit int64) ([]*hrpc.Result, error) {
-	// 简化为基本扫描，然后在应用层做过滤
-	scanRequest, err := hrpc.NewScanStr(ctx, "moviedata")
+// GetMoviesByGenre 根据电影类型获取电影ID，通过 genre#类型# 前缀扫描索引表，类型不区分大小写
+func GetMoviesByGenre(ctx context.Context, genre string, limit int64) ([]string, error) {
+	prefix := store.IndexPrefix(store.IndexGenre, genre)
+	return scanIndex(ctx, "GetMoviesByGenre", prefix, store.PrefixEnd(prefix), limit)
+}
+
+// GetMoviesByTag 根据标签获取电影ID，通过 tag#标签# 前缀扫描索引表，标签不区分大小写
+func GetMoviesByTag(ctx context.Context, tag string, limit int64) ([]string, error) {
+	prefix := store.IndexPrefix(store.IndexTag, tag)
+	return scanIndex(ctx, "GetMoviesByTag", prefix, store.PrefixEnd(prefix), limit)
+}
+
+// SearchMovies 搜索电影ID，按标题单词前缀和类型前缀扫描索引表
+func SearchMovies(ctx context.Context, query string, limit int64) ([]string, error) {
+	movieIDs, err := store.SearchIndex(query, func(start, stop string) ([]string, error) {
+		return scanIndex(ctx, "SearchMovies", start, stop, -1)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// 执行扫描
-	scanner := hbaseClient.Scan(scanRequest)
-	var results []*hrpc.Result
-	count := int64(0)
-
-	// 收集结果并筛选包含指定标签的电影
-	for count < limit {
-		result, err := scanner.Next()
-		if err != nil {
-			break
-		}
-
-		// 检查这个结果是否包含指定的标签
-		hasTag := false
-		for _, cell := range result.Cells {
-			if string(cell.Family) == "tag" && strings.HasPrefix(string(cell.Qualifier), "tag:") {
-				tagValue := string(cell.Value)
-				if strings.Contains(strings.ToLower(tagValue), strings.ToLower(tag)) {
-					hasTag = true
-					break
-				}
-			}
-		}
-
-		if hasTag {
-			results = append(results, result)
-			count++
-		}
+	if limit >= 0 && int64(len(movieIDs)) > limit {
+		movieIDs = movieIDs[:limit]
 	}
+	return movieIDs, nil
+}
 
-	return results, nil
+// GetMoviesByRatingRange 获取特定评分范围内的电影，通过评分区间索引做有界扫描，结果按平均分升序排列
+func GetMoviesByRatingRange(ctx context.Context, minRating, maxRating float64, limit int64) ([]string, error) {
+	startRow, stopRow := store.RatingRange(minRating, maxRating)
+	return scanIndex(ctx, "GetMoviesByRatingRange", startRow, stopRow, limit)
 }
 
-// ScanMoviesWithPagination 带分页的电影扫描
-func ScanMoviesWithPagination(ctx context.Context, page, pageSize int) ([]*hrpc.Result, int, error) {
-	// 计算扫描范围
-	startRow := "1" // 第一行
-	totalRows := 0
+// GetMoviesByYearRange 获取特定年份范围内的电影，通过年份索引做有界扫描，结果按年份升序排列
+func GetMoviesByYearRange(ctx context.Context, yearFrom, yearTo int, limit int64) ([]string, error) {
+	startRow, stopRow := store.YearRange(yearFrom, yearTo)
+	return scanIndex(ctx, "GetMoviesByYearRange", startRow, stopRow, limit)
+}
 
-	// 构建扫描请求
-	scanRequest, err := hrpc.NewScanRangeStr(ctx, "moviedata", startRow, "")
+// CountMovies 统计电影总数，扫描索引表中每部电影一行的反向行，每行只返回第一个单元格的行键
+func CountMovies(ctx context.Context) (int, error) {
+	startRow, stopRow := store.ReverseIndexRange()
+	scanner, err := openScanner(ctx, "CountMovies", store.IndexTable, startRow, stopRow,
+		hrpc.Filters(filter.NewFirstKeyOnlyFilter()))
 	if err != nil {
-		return nil, 0, err
+		return 0, err
 	}
+	defer scanner.Close()
 
-	// 执行扫描
-	scanner := hbaseClient.Scan(scanRequest)
-	var allResults []*hrpc.Result
-
-	// 收集所有结果（注意：在实际应用中，这种方式可能不适用于大数据集）
+	count := 0
 	for {
-		result, err := scanner.Next()
+		_, err := scanner.Next()
+		if err == io.EOF {
+			return count, nil
+		}
 		if err != nil {
-			break
+			return 0, err
 		}
-		allResults = append(allResults, result)
-	}
-
-	// 计算总行数
-	totalRows = len(allResults)
-
-	// 计算分页
-	startIndex := (page - 1) * pageSize
-	endIndex := startIndex + pageSize
-	if endIndex > totalRows {
-		endIndex = totalRows
-	}
-
-	// 如果起始索引超出范围
-	if startIndex >= totalRows {
-		return []*hrpc.Result{}, totalRows, nil
+		count++
 	}
-
-	// 返回分页结果
-	return allResults[startIndex:endIndex], totalRows, nil
 }
 
-// SearchMovies 搜索电影
-func SearchMovies(ctx context.Context, query string, limit int64) ([]*hrpc.Result, error) {
-	query = strings.ToLower(query)
+// ForEachMovie 遍历全部电影
+func ForEachMovie(ctx context.Context, families []string, fn func(store.Row) error) error {
+	var options []func(hrpc.Call) error
+	if len(families) > 0 {
+		options = append(options, hrpc.Families(familiesMap(families)))
+	}
 
-	// 使用简单扫描，然后在应用层做过滤
-	scanRequest, err := hrpc.NewScanStr(ctx, "moviedata")
+	scanner, err := openScanner(ctx, "ForEachMovie", "moviedata", "", "", options...)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer scanner.Close()
 
-	// 执行扫描
-	scanner := hbaseClient.Scan(scanRequest)
-	var results []*hrpc.Result
-	count := int64(0)
-
-	// 收集结果并筛选匹配查询的电影
-	for count < limit {
+	for {
 		result, err := scanner.Next()
+		if err == io.EOF {
+			return nil
+		}
 		if err != nil {
-			break
+			return err
 		}
-
-		// 检查标题和类型是否匹配查询
-		isMatch := false
-		for _, cell := range result.Cells {
-			family := string(cell.Family)
-			qualifier := string(cell.Qualifier)
-			value := string(cell.Value)
-
-			if family == "movie" && (qualifier == "title" || qualifier == "genres") {
-				if strings.Contains(strings.ToLower(value), query) {
-					isMatch = true
-					break
-				}
-			}
+		if len(result.Cells) == 0 {
+			continue
 		}
-
-		if isMatch {
-			results = append(results, result)
-			count++
+		if err := fn(resultToRow(result)); err != nil {
+			return err
 		}
 	}
-
-	return results, nil
 }
@@ -0,0 +1,248 @@
+package hbase
+
+import (
+	"context"
+	"gohbase/utils/store"
+
+	"github.com/tsuna/gohbase/hrpc"
+)
+
+// Store 基于HBase的 store.MovieStore 实现
+type Store struct{}
+
+// NewStore 创建HBase存储，调用前需先执行 InitHBase
+func NewStore() *Store {
+	return &Store{}
+}
+
+var _ store.MovieStore = (*Store)(nil)
+
+// GetMovie 根据ID获取电影信息
+func (s *Store) GetMovie(ctx context.Context, movieID string) (map[string]map[string][]byte, error) {
+	return GetMovie(ctx, movieID)
+}
+
+// GetMovieWithFamilies 根据ID和指定的列族获取电影信息
+func (s *Store) GetMovieWithFamilies(ctx context.Context, movieID string, families []string) (map[string]map[string][]byte, error) {
+	return GetMovieWithFamilies(ctx, movieID, families)
+}
+
+// GetMoviesMultiple 根据多个ID获取电影信息
+func (s *Store) GetMoviesMultiple(ctx context.Context, movieIDs []string) (map[string]map[string]map[string][]byte, error) {
+	return GetMoviesMultiple(ctx, movieIDs)
+}
+
+// GetMoviesMultipleWithFamilies 根据多个ID和指定的列族获取电影信息
+func (s *Store) GetMoviesMultipleWithFamilies(ctx context.Context, movieIDs []string, families []string) (map[string]map[string]map[string][]byte, error) {
+	return GetMoviesMultipleWithFamilies(ctx, movieIDs, families)
+}
+
+// GetMovieTags 获取电影标签
+func (s *Store) GetMovieTags(ctx context.Context, movieID string) (map[string]map[string][]byte, error) {
+	return GetMovieTags(ctx, movieID)
+}
+
+// GetMovieRatings 获取电影的全部评分
+func (s *Store) GetMovieRatings(ctx context.Context, movieID string) ([]store.UserRating, error) {
+	return GetMovieRatings(ctx, movieID)
+}
+
+// GetRatingTimeline 读取电影按天统计的评分
+func (s *Store) GetRatingTimeline(ctx context.Context, movieID string) ([]store.DayStat, error) {
+	return GetRatingTimeline(ctx, movieID)
+}
+
+// UpdateRatingTimeline 更新按天统计的评分
+func (s *Store) UpdateRatingTimeline(ctx context.Context, movieID string, old, updated store.UserRating) error {
+	return UpdateRatingTimeline(ctx, movieID, old, updated)
+}
+
+// PutRatingTimeline 覆盖写入按天统计的评分
+func (s *Store) PutRatingTimeline(ctx context.Context, movieID string, ratings []store.UserRating) error {
+	return PutRatingTimeline(ctx, movieID, ratings)
+}
+
+// GetMovieRatingStats 获取电影评分统计
+func (s *Store) GetMovieRatingStats(ctx context.Context, movieID string) (map[string]float64, error) {
+	return GetMovieRatingStats(ctx, movieID)
+}
+
+// GetUserRating 获取用户对电影的评分
+func (s *Store) GetUserRating(ctx context.Context, movieID string, userID string) (float64, int64, error) {
+	return GetUserRating(ctx, movieID, userID)
+}
+
+// GetUserTaggedMovies 获取用户打过标签的电影ID
+func (s *Store) GetUserTaggedMovies(ctx context.Context, userID string) ([]string, error) {
+	return GetUserTaggedMovies(ctx, userID)
+}
+
+// ScanMovies 扫描电影
+func (s *Store) ScanMovies(ctx context.Context, startRow, endRow string, limit int64) ([]store.Row, error) {
+	return ScanMovies(ctx, startRow, endRow, limit)
+}
+
+// ScanMoviesBefore 倒序扫描行键小于 beforeRow 的电影
+func (s *Store) ScanMoviesBefore(ctx context.Context, beforeRow string, limit int64) ([]store.Row, error) {
+	return ScanMoviesBefore(ctx, beforeRow, limit)
+}
+
+// ScanMoviesWithFamilies 使用指定列族扫描电影
+func (s *Store) ScanMoviesWithFamilies(ctx context.Context, startRow, endRow string, families []string, limit int64) ([]store.Row, error) {
+	return ScanMoviesWithFamilies(ctx, startRow, endRow, families, limit)
+}
+
+// GetMoviesByGenre 根据电影类型获取电影ID
+func (s *Store) GetMoviesByGenre(ctx context.Context, genre string, limit int64) ([]string, error) {
+	return GetMoviesByGenre(ctx, genre, limit)
+}
+
+// GetMoviesByTag 根据标签获取电影ID
+func (s *Store) GetMoviesByTag(ctx context.Context, tag string, limit int64) ([]string, error) {
+	return GetMoviesByTag(ctx, tag, limit)
+}
+
+// SearchMovies 搜索电影
+func (s *Store) SearchMovies(ctx context.Context, query string, limit int64) ([]string, error) {
+	return SearchMovies(ctx, query, limit)
+}
+
+// GetMoviesByRatingRange 获取特定评分范围内的电影
+func (s *Store) GetMoviesByRatingRange(ctx context.Context, minRating, maxRating float64, limit int64) ([]string, error) {
+	return GetMoviesByRatingRange(ctx, minRating, maxRating, limit)
+}
+
+// GetMoviesByYearRange 获取特定年份范围内的电影
+func (s *Store) GetMoviesByYearRange(ctx context.Context, yearFrom, yearTo int, limit int64) ([]string, error) {
+	return GetMoviesByYearRange(ctx, yearFrom, yearTo, limit)
+}
+
+// CountMovies 统计电影总数
+func (s *Store) CountMovies(ctx context.Context) (int, error) {
+	return CountMovies(ctx)
+}
+
+// ForEachMovie 遍历全部电影
+func (s *Store) ForEachMovie(ctx context.Context, families []string, fn func(store.Row) error) error {
+	return ForEachMovie(ctx, families, fn)
+}
+
+// PutRating 条件写入用户评分
+func (s *Store) PutRating(ctx context.Context, movieID, userID string, expected []byte, rating float64, timestamp int64) (bool, error) {
+	return PutRating(ctx, movieID, userID, expected, rating, timestamp)
+}
+
+// DeleteRating 条件删除用户评分
+func (s *Store) DeleteRating(ctx context.Context, movieID, userID string, expected []byte) (bool, error) {
+	return DeleteRating(ctx, movieID, userID, expected)
+}
+
+// DeleteRatingRow 删除旧版评分行
+func (s *Store) DeleteRatingRow(ctx context.Context, movieID, userID string) error {
+	return DeleteRatingRow(ctx, movieID, userID)
+}
+
+// ForEachUserRatings 遍历用户评分表
+func (s *Store) ForEachUserRatings(ctx context.Context, fn func(store.Row) error) error {
+	return ForEachUserRatings(ctx, fn)
+}
+
+// GetUserRatings 读取用户的全部评分
+func (s *Store) GetUserRatings(ctx context.Context, userID string) ([]store.MovieRating, error) {
+	return GetUserRatings(ctx, userID)
+}
+
+// PutUserRating 在用户评分表中写入评分
+func (s *Store) PutUserRating(ctx context.Context, userID, movieID string, rating float64, timestamp int64) error {
+	return PutUserRating(ctx, userID, movieID, rating, timestamp)
+}
+
+// DeleteUserRating 从用户评分表中删除评分
+func (s *Store) DeleteUserRating(ctx context.Context, userID, movieID string) error {
+	return DeleteUserRating(ctx, userID, movieID)
+}
+
+// GetRatingAggregate 读取电影评分聚合数据
+func (s *Store) GetRatingAggregate(ctx context.Context, movieID string) (*store.RatingAggregate, error) {
+	return GetRatingAggregate(ctx, movieID)
+}
+
+// UpdateRatingAggregate 原子更新评分聚合
+func (s *Store) UpdateRatingAggregate(ctx context.Context, movieID string, oldRating, newRating float64) error {
+	return UpdateRatingAggregate(ctx, movieID, oldRating, newRating)
+}
+
+// PutRatingAggregate 覆盖写入评分聚合数据
+func (s *Store) PutRatingAggregate(ctx context.Context, movieID string, aggregate *store.RatingAggregate) error {
+	return PutRatingAggregate(ctx, movieID, aggregate)
+}
+
+// PutAlias 写入电影的本地化标题
+func (s *Store) PutAlias(ctx context.Context, movieID, locale, title string) error {
+	return PutAlias(ctx, movieID, locale, title)
+}
+
+// PutTag 仅当用户还没有标签时写入标签
+func (s *Store) PutTag(ctx context.Context, movieID, userID, tag string) (bool, error) {
+	return PutTag(ctx, movieID, userID, tag)
+}
+
+// DeleteTag 删除用户对电影的标签
+func (s *Store) DeleteTag(ctx context.Context, movieID, userID string) error {
+	return DeleteTag(ctx, movieID, userID)
+}
+
+// GetPendingTag 读取待审核标签
+func (s *Store) GetPendingTag(ctx context.Context, movieID, userID string) (*store.PendingTag, error) {
+	return GetPendingTag(ctx, movieID, userID)
+}
+
+// ListPendingTags 读取最早提交的待审核标签
+func (s *Store) ListPendingTags(ctx context.Context, limit int64) ([]store.PendingTag, error) {
+	return ListPendingTags(ctx, limit)
+}
+
+// PutPendingTag 加入待审核队列
+func (s *Store) PutPendingTag(ctx context.Context, pending store.PendingTag) (bool, error) {
+	return PutPendingTag(ctx, pending)
+}
+
+// DeletePendingTag 删除待审核标签
+func (s *Store) DeletePendingTag(ctx context.Context, movieID, userID string) error {
+	return DeletePendingTag(ctx, movieID, userID)
+}
+
+// UpdateMovieIndex 同步电影的二级索引条目
+func (s *Store) UpdateMovieIndex(ctx context.Context, movieID string) error {
+	return UpdateMovieIndex(ctx, movieID)
+}
+
+// familiesMap 将列族列表转换为 hrpc.Families 所需的映射
+func familiesMap(families []string) map[string][]string {
+	result := make(map[string][]string)
+	for _, family := range families {
+		result[family] = nil
+	}
+	return result
+}
+
+// resultToRow 将HBase扫描结果转换为 store.Row
+func resultToRow(result *hrpc.Result) store.Row {
+	row := store.Row{
+		Data: make(map[string]map[string][]byte),
+	}
+
+	for _, cell := range result.Cells {
+		if row.Key == "" {
+			row.Key = string(cell.Row)
+		}
+
+		family := string(cell.Family)
+		if _, ok := row.Data[family]; !ok {
+			row.Data[family] = make(map[string][]byte)
+		}
+		row.Data[family][string(cell.Qualifier)] = cell.Value
+	}
+
+	return row
+}
@@ -0,0 +1,108 @@
+package hbase
+
+import (
+	"context"
+	"gohbase/utils/store"
+	"io"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tsuna/gohbase/hrpc"
+)
+
+// PutTag 写入用户对电影的标签，列格式为 tag:{userId}，通过 CheckAndPut 保证只在该列为空时写入
+func PutTag(ctx context.Context, movieID, userID, tag string) (bool, error) {
+	values := map[string]map[string][]byte{
+		store.TagFamily: {
+			store.TagColumn(userID): []byte(tag),
+		},
+	}
+
+	return checkAndPutRow(ctx, "PutTag", "moviedata", movieID, values, store.TagFamily, store.TagColumn(userID), nil)
+}
+
+// DeleteTag 删除用户对电影的标签
+func DeleteTag(ctx context.Context, movieID, userID string) error {
+	values := map[string]map[string][]byte{
+		store.TagFamily: {
+			store.TagColumn(userID): nil,
+		},
+	}
+
+	return deleteRow(ctx, "DeleteTag", "moviedata", movieID, values)
+}
+
+// GetPendingTag 读取待审核标签的查找条目，单行读取
+func GetPendingTag(ctx context.Context, movieID, userID string) (*store.PendingTag, error) {
+	key := store.TagPendingKey(movieID, userID)
+	result, err := getRow(ctx, "GetPendingTag", store.TagQueueTable, key,
+		hrpc.Families(map[string][]string{store.TagQueueFamily: nil}))
+	if err != nil {
+		return nil, err
+	}
+
+	row := resultToRow(result)
+	return store.ParsePendingTag(key, row.Data[store.TagQueueFamily]), nil
+}
+
+// ListPendingTags 在队列条目的行键范围内扫描，读到 limit 个待审核标签即停止
+func ListPendingTags(ctx context.Context, limit int64) ([]store.PendingTag, error) {
+	scanner, err := openScanner(ctx, "ListPendingTags", store.TagQueueTable, store.TagQueueStartRow, store.TagQueueStopRow,
+		hrpc.Families(map[string][]string{store.TagQueueFamily: nil}))
+	if err != nil {
+		return nil, err
+	}
+	defer scanner.Close()
+
+	var pending []store.PendingTag
+	for int64(len(pending)) < limit {
+		result, err := scanner.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		row := resultToRow(result)
+		if tag := store.ParsePendingTag(row.Key, row.Data[store.TagQueueFamily]); tag != nil {
+			pending = append(pending, *tag)
+		}
+	}
+
+	return pending, nil
+}
+
+// PutPendingTag 先通过 CheckAndPut 写入查找条目（已存在时返回 false），再写入队列条目
+// 队列条目写入失败时删除查找条目，避免残留的查找条目挡住用户之后的标签
+func PutPendingTag(ctx context.Context, pending store.PendingTag) (bool, error) {
+	values := map[string]map[string][]byte{
+		store.TagQueueFamily: store.EncodePendingTag(pending),
+	}
+
+	pendingKey := store.TagPendingKey(pending.MovieID, pending.UserID)
+	ok, err := checkAndPutRow(ctx, "PutPendingTag", store.TagQueueTable, pendingKey, values, store.TagQueueFamily, "tag", nil)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	if err := putRow(ctx, "PutPendingTag", store.TagQueueTable, store.TagQueueKey(pending), values); err != nil {
+		if err := deleteRow(ctx, "PutPendingTag", store.TagQueueTable, pendingKey, nil); err != nil {
+			logrus.Errorf("删除电影 %s 用户 %s 的待审核标签查找条目失败: %v", pending.MovieID, pending.UserID, err)
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// DeletePendingTag 根据查找条目中的提交时间删除队列条目，再删除查找条目
+func DeletePendingTag(ctx context.Context, movieID, userID string) error {
+	pending, err := GetPendingTag(ctx, movieID, userID)
+	if err != nil || pending == nil {
+		return err
+	}
+
+	if err := deleteRow(ctx, "DeletePendingTag", store.TagQueueTable, store.TagQueueKey(*pending), nil); err != nil {
+		return err
+	}
+	return deleteRow(ctx, "DeletePendingTag", store.TagQueueTable, store.TagPendingKey(movieID, userID), nil)
+}
@@ -0,0 +1,40 @@
+package hbase
+
+import (
+	"context"
+	"fmt"
+	"gohbase/utils/store"
+)
+
+// GetRatingTimeline 读取电影按天统计的评分数量与总和
+func GetRatingTimeline(ctx context.Context, movieID string) ([]store.DayStat, error) {
+	data, err := GetMovieWithFamilies(ctx, movieID, []string{store.TimelineFamily})
+	if err != nil {
+		return nil, err
+	}
+	return store.ParseTimeline(data[store.TimelineFamily]), nil
+}
+
+// UpdateRatingTimeline 评分变化时通过一次多列 Increment 同时更新对应日期的数量与总和
+func UpdateRatingTimeline(ctx context.Context, movieID string, old, updated store.UserRating) error {
+	if err := incrementRow(ctx, "UpdateRatingTimeline", "moviedata", movieID, store.TimelineFamily, store.TimelineDeltas(old, updated)); err != nil {
+		return fmt.Errorf("更新按天评分统计失败: %w", err)
+	}
+	return nil
+}
+
+// PutRatingTimeline 先删除整个 timeline 列族再写入重新计算的统计，避免残留已无评分的日期
+func PutRatingTimeline(ctx context.Context, movieID string, ratings []store.UserRating) error {
+	if err := deleteRow(ctx, "PutRatingTimeline", "moviedata", movieID,
+		map[string]map[string][]byte{store.TimelineFamily: nil}); err != nil {
+		return err
+	}
+
+	columns := store.ComputeTimeline(ratings)
+	if len(columns) == 0 {
+		return nil
+	}
+	return putRow(ctx, "PutRatingTimeline", "moviedata", movieID, map[string]map[string][]byte{
+		store.TimelineFamily: columns,
+	})
+}
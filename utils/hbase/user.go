@@ -2,6 +2,7 @@ package hbase
 
 import (
 	"context"
+	"gohbase/utils/store"
 	"strconv"
 )
 
@@ -23,7 +24,8 @@ func GetUserRating(ctx context.Context, movieID string, userID string) (float64,
 	if ratingData, ok := data["rating"]; ok {
 		// 评分字段格式为 rating:{userId}
 		ratingKey := "rating:" + userID
-		if ratingValue, ok := ratingData[ratingKey]; ok {
+		// 已删除的评分为空值（见 DeleteRating），视为未评分
+		if ratingValue, ok := ratingData[ratingKey]; ok && len(ratingValue) > 0 {
 			rating, err := strconv.ParseFloat(string(ratingValue), 64)
 			if err != nil {
 				return 0, 0, err
@@ -44,21 +46,8 @@ func GetUserRating(ctx context.Context, movieID string, userID string) (float64,
 	return 0, 0, nil
 }
 
-// GetUserFavoriteGenres 获取用户最喜欢的电影类型
-func GetUserFavoriteGenres(ctx context.Context, userID string) (map[string]int, error) {
-	// 这里需要扫描用户的所有评分
-	// 为简化实现，我们暂时返回空结果
-	return map[string]int{}, nil
-}
-
-// GetUserTags 获取用户的标签
-func GetUserTags(ctx context.Context, userID string) ([]string, error) {
-	// 为简化实现，暂时返回空结果
-	return []string{}, nil
-}
-
-// GetRecommendedMoviesForUser 获取推荐给用户的电影
-func GetRecommendedMoviesForUser(ctx context.Context, userID string) ([]string, error) {
-	// 为简化实现，暂时返回空结果
-	return []string{}, nil
+// GetUserTaggedMovies 通过二级索引获取用户打过标签的电影ID
+func GetUserTaggedMovies(ctx context.Context, userID string) ([]string, error) {
+	prefix := store.IndexPrefix(store.IndexUserTag, userID)
+	return scanIndex(ctx, "GetUserTaggedMovies", prefix, store.PrefixEnd(prefix), -1)
 }
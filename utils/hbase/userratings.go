@@ -0,0 +1,66 @@
+package hbase
+
+import (
+	"context"
+	"gohbase/utils/store"
+	"io"
+
+	"github.com/tsuna/gohbase/hrpc"
+)
+
+// GetUserRatings 读取用户评分表中用户的全部评分，单行读取
+func GetUserRatings(ctx context.Context, userID string) ([]store.MovieRating, error) {
+	result, err := getRow(ctx, "GetUserRatings", store.UserRatingsTable, userID,
+		hrpc.Families(map[string][]string{store.UserRatingsFamily: nil}))
+	if err != nil {
+		return nil, err
+	}
+
+	ratingData := make(map[string][]byte, len(result.Cells))
+	for _, cell := range result.Cells {
+		ratingData[string(cell.Qualifier)] = cell.Value
+	}
+	return store.ParseUserRatings(ratingData), nil
+}
+
+// ForEachUserRatings 扫描用户评分表的全部行
+func ForEachUserRatings(ctx context.Context, fn func(store.Row) error) error {
+	scanner, err := openScanner(ctx, "ForEachUserRatings", store.UserRatingsTable, "", "",
+		hrpc.Families(map[string][]string{store.UserRatingsFamily: nil}))
+	if err != nil {
+		return err
+	}
+	defer scanner.Close()
+
+	for {
+		result, err := scanner.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(result.Cells) == 0 {
+			continue
+		}
+		if err := fn(resultToRow(result)); err != nil {
+			return err
+		}
+	}
+}
+
+// PutUserRating 在用户评分表中写入一条评分
+func PutUserRating(ctx context.Context, userID, movieID string, rating float64, timestamp int64) error {
+	values := map[string]map[string][]byte{
+		store.UserRatingsFamily: store.UserRatingColumns(movieID, rating, timestamp),
+	}
+	return putRow(ctx, "PutUserRating", store.UserRatingsTable, userID, values)
+}
+
+// DeleteUserRating 从用户评分表中删除一条评分
+func DeleteUserRating(ctx context.Context, userID, movieID string) error {
+	values := map[string]map[string][]byte{
+		store.UserRatingsFamily: store.UserRatingColumns(movieID, 0, 0),
+	}
+	return deleteRow(ctx, "DeleteUserRating", store.UserRatingsTable, userID, values)
+}
@@ -0,0 +1,14 @@
+package utils
+
+import (
+	"gohbase/utils/logbuffer"
+)
+
+// LogBuffer 对外暴露的全局日志缓冲区，需作为 logrus hook 安装后才会记录日志
+var LogBuffer *logbuffer.RingBuffer
+
+// InitLogBuffer 初始化日志缓冲区
+func InitLogBuffer(capacity int) {
+	logbuffer.InitBuffer(capacity)
+	LogBuffer = logbuffer.Buffer
+}
@@ -0,0 +1,9 @@
+package logbuffer
+
+// Buffer 全局日志缓冲区实例
+var Buffer *RingBuffer
+
+// InitBuffer 初始化日志缓冲区
+func InitBuffer(capacity int) {
+	Buffer = NewRingBuffer(capacity)
+}
@@ -0,0 +1,164 @@
+package logbuffer
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Entry 一条日志记录
+type Entry struct {
+	ID        uint64                 `json:"id"`
+	Timestamp time.Time              `json:"timestamp"`
+	Level     string                 `json:"level"`
+	Message   string                 `json:"message"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+}
+
+// Query 日志查询条件，零值表示不限
+type Query struct {
+	MinLevel logrus.Level // 最低日志级别，如 warn 表示返回 warn/error/fatal/panic
+	HasLevel bool         // 是否按级别过滤
+	From     time.Time    // 起始时间（含）
+	To       time.Time    // 结束时间（含）
+	Contains string       // 消息或字段值包含的子串，不区分大小写
+	SinceID  uint64       // 只返回ID大于该值的日志，用于轮询
+	Limit    int          // 最大返回条数
+}
+
+// RingBuffer 保存最近日志的环形缓冲区，实现 logrus.Hook
+type RingBuffer struct {
+	mu      sync.RWMutex
+	entries []Entry
+	next    int    // 下一条日志写入的位置
+	full    bool   // 缓冲区是否已写满
+	lastID  uint64 // 最近一条日志的ID，从1开始递增
+}
+
+// NewRingBuffer 创建指定容量的日志缓冲区
+func NewRingBuffer(capacity int) *RingBuffer {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &RingBuffer{
+		entries: make([]Entry, capacity),
+	}
+}
+
+// Levels 实现 logrus.Hook，记录所有级别的日志
+func (b *RingBuffer) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire 实现 logrus.Hook，将日志写入缓冲区，缓冲区满时覆盖最旧的日志
+func (b *RingBuffer) Fire(entry *logrus.Entry) error {
+	var fields map[string]interface{}
+	if len(entry.Data) > 0 {
+		fields = make(map[string]interface{}, len(entry.Data))
+		for key, value := range entry.Data {
+			// error 类型序列化为JSON时会丢失内容，统一转换为字符串
+			if err, ok := value.(error); ok {
+				value = err.Error()
+			}
+			fields[key] = value
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	b.entries[b.next] = Entry{
+		ID:        b.lastID,
+		Timestamp: entry.Time,
+		Level:     entry.Level.String(),
+		Message:   entry.Message,
+		Fields:    fields,
+	}
+
+	b.next = (b.next + 1) % len(b.entries)
+	if b.next == 0 {
+		b.full = true
+	}
+
+	return nil
+}
+
+// LastID 返回最近一条日志的ID
+func (b *RingBuffer) LastID() uint64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.lastID
+}
+
+// Query 按条件查询日志，结果按时间从旧到新排列
+// 指定 SinceID 时返回该ID之后最早的 Limit 条，便于轮询时不遗漏；否则返回最新的 Limit 条
+func (b *RingBuffer) Query(q Query) []Entry {
+	contains := strings.ToLower(q.Contains)
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	matched := make([]Entry, 0)
+	for _, entry := range b.ordered() {
+		if entry.ID <= q.SinceID {
+			continue
+		}
+		if q.HasLevel {
+			// logrus 中级别数值越小越严重
+			level, err := logrus.ParseLevel(entry.Level)
+			if err != nil || level > q.MinLevel {
+				continue
+			}
+		}
+		if !q.From.IsZero() && entry.Timestamp.Before(q.From) {
+			continue
+		}
+		if !q.To.IsZero() && entry.Timestamp.After(q.To) {
+			continue
+		}
+		if contains != "" && !entryContains(entry, contains) {
+			continue
+		}
+
+		matched = append(matched, entry)
+		if q.SinceID > 0 && q.Limit > 0 && len(matched) >= q.Limit {
+			break
+		}
+	}
+
+	if q.Limit > 0 && len(matched) > q.Limit {
+		matched = matched[len(matched)-q.Limit:]
+	}
+
+	return matched
+}
+
+// ordered 按写入顺序返回缓冲区中的日志，调用方需持有读锁
+func (b *RingBuffer) ordered() []Entry {
+	if !b.full {
+		return b.entries[:b.next]
+	}
+
+	entries := make([]Entry, 0, len(b.entries))
+	entries = append(entries, b.entries[b.next:]...)
+	entries = append(entries, b.entries[:b.next]...)
+	return entries
+}
+
+// entryContains 判断日志消息或字段值是否包含子串，substr 需为小写
+func entryContains(entry Entry, substr string) bool {
+	if strings.Contains(strings.ToLower(entry.Message), substr) {
+		return true
+	}
+	for _, value := range entry.Fields {
+		if strings.Contains(strings.ToLower(fmt.Sprint(value)), substr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,770 @@
+package memstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"gohbase/utils/store"
+)
+
+// movieTable 电影数据表名，与HBase中的表名保持一致
+const movieTable = "moviedata"
+
+// table 内存表，结构为 行键 -> 列族 -> 列 -> 值
+type table map[string]map[string]map[string][]byte
+
+// Store 基于内存的 store.MovieStore 实现，用于本地开发和单元测试
+type Store struct {
+	mu     sync.RWMutex
+	tables map[string]table
+}
+
+var _ store.MovieStore = (*Store)(nil)
+
+// New 创建空的内存存储
+func New() *Store {
+	return &Store{
+		tables: map[string]table{
+			movieTable:             {},
+			store.IndexTable:       {},
+			store.UserRatingsTable: {},
+			store.TagQueueTable:    {},
+		},
+	}
+}
+
+// LoadFixtures 从JSON文件加载测试数据
+func (s *Store) LoadFixtures(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("打开数据文件失败: %w", err)
+	}
+	defer file.Close()
+
+	return s.Load(file)
+}
+
+// Load 从JSON读取数据，格式为 {表名: {行键: {列族: {列: 值}}}}
+func (s *Store) Load(r io.Reader) error {
+	var fixtures map[string]map[string]map[string]map[string]string
+	if err := json.NewDecoder(r).Decode(&fixtures); err != nil {
+		return fmt.Errorf("解析数据文件失败: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	movies := s.tables[movieTable]
+	for tableName, rows := range fixtures {
+		t, ok := s.tables[tableName]
+		if !ok {
+			t = table{}
+			s.tables[tableName] = t
+		}
+
+		for rowKey, families := range rows {
+			for family, columns := range families {
+				for qualifier, value := range columns {
+					t.put(rowKey, family, qualifier, []byte(value))
+				}
+			}
+		}
+	}
+
+	// 合并电影行中的评分与旧版评分行，旧版评分行本身不是电影，不补齐聚合
+	ratings := make(map[string][]store.UserRating)
+	for rowKey, row := range movies {
+		if store.IsRatingRowKey(rowKey) || len(row["movie"]) == 0 {
+			continue
+		}
+		prefix := store.RatingRowPrefix(rowKey)
+		var legacy []store.Row
+		for _, key := range movies.keys(prefix, store.PrefixEnd(prefix)) {
+			legacy = append(legacy, store.Row{Key: key, Data: movies.get(key, []string{"rating"})})
+		}
+		if merged := store.MergeRatings(store.ParseRatings(row["rating"]), legacy, rowKey); len(merged) > 0 {
+			ratings[rowKey] = merged
+		}
+	}
+
+	// 数据文件中未提供评分聚合或按天统计时，根据原始评分补齐
+	for rowKey, movieRatings := range ratings {
+		row := movies[rowKey]
+		if _, ok := row[store.StatsFamily]; !ok {
+			aggregate := store.ComputeRatingAggregate(movieRatings)
+			for qualifier, value := range store.EncodeRatingAggregate(aggregate) {
+				movies.put(rowKey, store.StatsFamily, qualifier, value)
+			}
+		}
+		if _, ok := row[store.TimelineFamily]; !ok {
+			for qualifier, value := range store.ComputeTimeline(movieRatings) {
+				movies.put(rowKey, store.TimelineFamily, qualifier, value)
+			}
+		}
+	}
+
+	// 数据文件中未提供用户评分表时，根据电影的评分建立
+	if _, ok := fixtures[store.UserRatingsTable]; !ok {
+		userRatings := s.tables[store.UserRatingsTable]
+		for rowKey, movieRatings := range ratings {
+			for _, rating := range movieRatings {
+				for qualifier, value := range store.UserRatingColumns(rowKey, rating.Rating, rating.Timestamp) {
+					userRatings.put(rating.UserID, store.UserRatingsFamily, qualifier, value)
+				}
+			}
+		}
+	}
+
+	// 根据电影数据建立二级索引
+	for rowKey := range movies {
+		s.updateIndex(rowKey)
+	}
+
+	return nil
+}
+
+// Put 写入单元格，主要用于在测试中构造数据
+func (s *Store) Put(tableName, rowKey string, values map[string]map[string][]byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tables[tableName]
+	if !ok {
+		t = table{}
+		s.tables[tableName] = t
+	}
+
+	for family, columns := range values {
+		for qualifier, value := range columns {
+			t.put(rowKey, family, qualifier, value)
+		}
+	}
+}
+
+// put 写入单元格，调用方需持有写锁
+func (t table) put(rowKey, family, qualifier string, value []byte) {
+	row, ok := t[rowKey]
+	if !ok {
+		row = make(map[string]map[string][]byte)
+		t[rowKey] = row
+	}
+	if _, ok := row[family]; !ok {
+		row[family] = make(map[string][]byte)
+	}
+	row[family][qualifier] = value
+}
+
+// delete 删除单元格，列族为空时删除整行，调用方需持有写锁
+func (t table) delete(rowKey, family, qualifier string) {
+	row, ok := t[rowKey]
+	if !ok {
+		return
+	}
+
+	delete(row[family], qualifier)
+	if len(row[family]) == 0 {
+		delete(row, family)
+	}
+	if len(row) == 0 {
+		delete(t, rowKey)
+	}
+}
+
+// get 获取行数据的副本，families 为空时返回所有列族，调用方需持有读锁
+func (t table) get(rowKey string, families []string) map[string]map[string][]byte {
+	row, ok := t[rowKey]
+	if !ok {
+		return nil
+	}
+
+	result := make(map[string]map[string][]byte)
+	for family, columns := range row {
+		if len(families) > 0 && !containsString(families, family) {
+			continue
+		}
+
+		copied := make(map[string][]byte, len(columns))
+		for qualifier, value := range columns {
+			copied[qualifier] = value
+		}
+		result[family] = copied
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+
+	return result
+}
+
+// keys 返回 [startRow, endRow) 范围内按字典序排列的行键，endRow 为空表示不限
+func (t table) keys(startRow, endRow string) []string {
+	keys := make([]string, 0, len(t))
+	for key := range t {
+		if key < startRow {
+			continue
+		}
+		if endRow != "" && key >= endRow {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// scan 按条件扫描电影表，match 为 nil 时返回全部行
+func (s *Store) scan(startRow, endRow string, families []string, limit int64, match func(store.Row) bool) []store.Row {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t := s.tables[movieTable]
+	var results []store.Row
+
+	for _, key := range t.keys(startRow, endRow) {
+		if limit >= 0 && int64(len(results)) >= limit {
+			break
+		}
+
+		data := t.get(key, families)
+		if data == nil {
+			continue
+		}
+
+		row := store.Row{Key: key, Data: data}
+		if match != nil && !match(row) {
+			continue
+		}
+		results = append(results, row)
+	}
+
+	return results
+}
+
+// GetMovie 根据ID获取电影信息
+func (s *Store) GetMovie(ctx context.Context, movieID string) (map[string]map[string][]byte, error) {
+	return s.GetMovieWithFamilies(ctx, movieID, nil)
+}
+
+// GetMovieWithFamilies 根据ID和指定的列族获取电影信息
+func (s *Store) GetMovieWithFamilies(ctx context.Context, movieID string, families []string) (map[string]map[string][]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tables[movieTable].get(movieID, families), nil
+}
+
+// GetMoviesMultiple 根据多个ID获取电影信息
+func (s *Store) GetMoviesMultiple(ctx context.Context, movieIDs []string) (map[string]map[string]map[string][]byte, error) {
+	return s.GetMoviesMultipleWithFamilies(ctx, movieIDs, nil)
+}
+
+// GetMoviesMultipleWithFamilies 根据多个ID和指定的列族获取电影信息
+func (s *Store) GetMoviesMultipleWithFamilies(ctx context.Context, movieIDs []string, families []string) (map[string]map[string]map[string][]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	results := make(map[string]map[string]map[string][]byte)
+	for _, movieID := range movieIDs {
+		if data := s.tables[movieTable].get(movieID, families); data != nil {
+			results[movieID] = data
+		}
+	}
+
+	return results, nil
+}
+
+// GetMovieTags 获取电影标签
+func (s *Store) GetMovieTags(ctx context.Context, movieID string) (map[string]map[string][]byte, error) {
+	return s.GetMovieWithFamilies(ctx, movieID, []string{"tag"})
+}
+
+// GetMovieRatings 获取电影的全部评分，兼容以 {movieId}_{userId} 为行键的旧版评分行
+func (s *Store) GetMovieRatings(ctx context.Context, movieID string) ([]store.UserRating, error) {
+	data, err := s.GetMovieWithFamilies(ctx, movieID, []string{"rating"})
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := store.RatingRowPrefix(movieID)
+	rows := s.scan(prefix, store.PrefixEnd(prefix), []string{"rating"}, -1, nil)
+
+	return store.MergeRatings(store.ParseRatings(data["rating"]), rows, movieID), nil
+}
+
+// GetMovieRatingStats 获取电影评分统计
+func (s *Store) GetMovieRatingStats(ctx context.Context, movieID string) (map[string]float64, error) {
+	ratings, err := s.GetMovieRatings(ctx, movieID)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregate := &store.RatingAggregate{}
+	for _, rating := range ratings {
+		aggregate.Add(rating.Rating)
+	}
+
+	return map[string]float64{
+		"avgRating": aggregate.Avg(),
+		"minRating": aggregate.Min,
+		"maxRating": aggregate.Max,
+		"count":     float64(aggregate.Count),
+	}, nil
+}
+
+// GetUserRating 获取用户对电影的评分
+func (s *Store) GetUserRating(ctx context.Context, movieID string, userID string) (float64, int64, error) {
+	data, err := s.GetMovieWithFamilies(ctx, movieID, []string{"rating"})
+	if err != nil || data == nil {
+		return 0, 0, err
+	}
+
+	for _, rating := range store.ParseRatings(data["rating"]) {
+		if rating.UserID == userID {
+			return rating.Rating, rating.Timestamp, nil
+		}
+	}
+
+	return 0, 0, nil
+}
+
+// GetUserTaggedMovies 通过二级索引获取用户打过标签的电影ID
+func (s *Store) GetUserTaggedMovies(ctx context.Context, userID string) ([]string, error) {
+	prefix := store.IndexPrefix(store.IndexUserTag, userID)
+	return s.scanIndex(prefix, store.PrefixEnd(prefix), -1), nil
+}
+
+// ScanMovies 扫描电影
+func (s *Store) ScanMovies(ctx context.Context, startRow, endRow string, limit int64) ([]store.Row, error) {
+	return s.scan(startRow, endRow, nil, limit, store.IsMovieRow), nil
+}
+
+// ScanMoviesBefore 倒序返回行键小于 beforeRow 的电影，beforeRow 为空时从最后一行开始
+func (s *Store) ScanMoviesBefore(ctx context.Context, beforeRow string, limit int64) ([]store.Row, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t := s.tables[movieTable]
+	keys := t.keys("", beforeRow)
+
+	var results []store.Row
+	for i := len(keys) - 1; i >= 0 && int64(len(results)) < limit; i-- {
+		row := store.Row{Key: keys[i], Data: t.get(keys[i], nil)}
+		if store.IsMovieRow(row) {
+			results = append(results, row)
+		}
+	}
+
+	return results, nil
+}
+
+// ScanMoviesWithFamilies 使用指定列族扫描电影
+func (s *Store) ScanMoviesWithFamilies(ctx context.Context, startRow, endRow string, families []string, limit int64) ([]store.Row, error) {
+	return s.scan(startRow, endRow, families, limit, func(row store.Row) bool {
+		return !store.IsRatingRowKey(row.Key)
+	}), nil
+}
+
+// GetMoviesByGenre 根据电影类型获取电影ID
+func (s *Store) GetMoviesByGenre(ctx context.Context, genre string, limit int64) ([]string, error) {
+	prefix := store.IndexPrefix(store.IndexGenre, genre)
+	return s.scanIndex(prefix, store.PrefixEnd(prefix), limit), nil
+}
+
+// GetMoviesByTag 根据标签获取电影ID
+func (s *Store) GetMoviesByTag(ctx context.Context, tag string, limit int64) ([]string, error) {
+	prefix := store.IndexPrefix(store.IndexTag, tag)
+	return s.scanIndex(prefix, store.PrefixEnd(prefix), limit), nil
+}
+
+// SearchMovies 搜索电影
+func (s *Store) SearchMovies(ctx context.Context, query string, limit int64) ([]string, error) {
+	movieIDs, _ := store.SearchIndex(query, func(start, stop string) ([]string, error) {
+		return s.scanIndex(start, stop, -1), nil
+	})
+
+	if limit >= 0 && int64(len(movieIDs)) > limit {
+		movieIDs = movieIDs[:limit]
+	}
+	return movieIDs, nil
+}
+
+// GetMoviesByRatingRange 获取特定评分范围内的电影
+func (s *Store) GetMoviesByRatingRange(ctx context.Context, minRating, maxRating float64, limit int64) ([]string, error) {
+	startRow, stopRow := store.RatingRange(minRating, maxRating)
+	return s.scanIndex(startRow, stopRow, limit), nil
+}
+
+// GetMoviesByYearRange 获取特定年份范围内的电影
+func (s *Store) GetMoviesByYearRange(ctx context.Context, yearFrom, yearTo int, limit int64) ([]string, error) {
+	startRow, stopRow := store.YearRange(yearFrom, yearTo)
+	return s.scanIndex(startRow, stopRow, limit), nil
+}
+
+// CountMovies 通过索引表中的反向行统计电影总数
+func (s *Store) CountMovies(ctx context.Context) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	startRow, stopRow := store.ReverseIndexRange()
+	return len(s.tables[store.IndexTable].keys(startRow, stopRow)), nil
+}
+
+// ForEachMovie 遍历全部电影
+func (s *Store) ForEachMovie(ctx context.Context, families []string, fn func(store.Row) error) error {
+	for _, row := range s.scan("", "", families, -1, nil) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PutRating 仅当用户当前的评分仍为 expected 时写入评分
+func (s *Store) PutRating(ctx context.Context, movieID, userID string, expected []byte, rating float64, timestamp int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.tables[movieTable]
+	if !t.matchRating(movieID, userID, expected) {
+		return false, nil
+	}
+	t.put(movieID, "rating", "rating:"+userID, store.EncodeRatingValue(rating))
+	t.put(movieID, "rating", "timestamp:"+userID, []byte(strconv.FormatInt(timestamp, 10)))
+	return true, nil
+}
+
+// DeleteRating 仅当用户当前的评分仍为 expected 时删除评分
+func (s *Store) DeleteRating(ctx context.Context, movieID, userID string, expected []byte) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.tables[movieTable]
+	if !t.matchRating(movieID, userID, expected) {
+		return false, nil
+	}
+	t.delete(movieID, "rating", "rating:"+userID)
+	t.delete(movieID, "rating", "timestamp:"+userID)
+	return true, nil
+}
+
+// DeleteRatingRow 删除旧版评分行
+func (s *Store) DeleteRatingRow(ctx context.Context, movieID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tables[movieTable], store.RatingRowPrefix(movieID)+userID)
+	return nil
+}
+
+// matchRating 判断 rating:{userId} 列是否与期望值一致，语义与 HBase CheckAndPut 相同（空值与不存在的列匹配）
+func (t table) matchRating(movieID, userID string, expected []byte) bool {
+	current := t.get(movieID, []string{"rating"})["rating"]["rating:"+userID]
+	return bytes.Equal(current, expected)
+}
+
+// GetUserRatings 从用户评分表读取用户的全部评分
+func (s *Store) GetUserRatings(ctx context.Context, userID string) ([]store.MovieRating, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data := s.tables[store.UserRatingsTable].get(userID, []string{store.UserRatingsFamily})
+	return store.ParseUserRatings(data[store.UserRatingsFamily]), nil
+}
+
+// PutUserRating 在用户评分表中写入评分
+func (s *Store) PutUserRating(ctx context.Context, userID, movieID string, rating float64, timestamp int64) error {
+	s.Put(store.UserRatingsTable, userID, map[string]map[string][]byte{
+		store.UserRatingsFamily: store.UserRatingColumns(movieID, rating, timestamp),
+	})
+	return nil
+}
+
+// DeleteUserRating 从用户评分表中删除评分
+func (s *Store) DeleteUserRating(ctx context.Context, userID, movieID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.tables[store.UserRatingsTable]
+	for qualifier := range store.UserRatingColumns(movieID, 0, 0) {
+		t.delete(userID, store.UserRatingsFamily, qualifier)
+	}
+	return nil
+}
+
+// ForEachUserRatings 遍历用户评分表，遍历前复制全部行，fn 中可以修改用户评分表
+func (s *Store) ForEachUserRatings(ctx context.Context, fn func(store.Row) error) error {
+	s.mu.RLock()
+	t := s.tables[store.UserRatingsTable]
+	rows := make([]store.Row, 0, len(t))
+	for _, key := range t.keys("", "") {
+		if data := t.get(key, []string{store.UserRatingsFamily}); data != nil {
+			rows = append(rows, store.Row{Key: key, Data: data})
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetRatingAggregate 读取电影评分聚合数据
+func (s *Store) GetRatingAggregate(ctx context.Context, movieID string) (*store.RatingAggregate, error) {
+	data, err := s.GetMovieWithFamilies(ctx, movieID, []string{store.StatsFamily})
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return store.ParseRatingAggregate(data[store.StatsFamily]), nil
+}
+
+// UpdateRatingAggregate 原子更新评分聚合
+func (s *Store) UpdateRatingAggregate(ctx context.Context, movieID string, oldRating, newRating float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.tables[movieTable]
+	aggregate := store.ParseRatingAggregate(t.get(movieID, []string{store.StatsFamily})[store.StatsFamily])
+	if aggregate == nil {
+		aggregate = &store.RatingAggregate{}
+	}
+
+	if oldRating > 0 {
+		aggregate.Count--
+		aggregate.Sum -= oldRating
+		if index, ok := store.HistogramIndex(oldRating); ok {
+			aggregate.Histogram[index]--
+		}
+	}
+	if newRating > 0 {
+		aggregate.Count++
+		aggregate.Sum += newRating
+		if index, ok := store.HistogramIndex(newRating); ok {
+			aggregate.Histogram[index]++
+		}
+	}
+	aggregate.Min, aggregate.Max = aggregate.HistogramRange()
+
+	for qualifier, value := range store.EncodeRatingAggregate(aggregate) {
+		t.put(movieID, store.StatsFamily, qualifier, value)
+	}
+	return nil
+}
+
+// PutRatingAggregate 覆盖写入评分聚合数据
+func (s *Store) PutRatingAggregate(ctx context.Context, movieID string, aggregate *store.RatingAggregate) error {
+	s.Put(movieTable, movieID, map[string]map[string][]byte{
+		store.StatsFamily: store.EncodeRatingAggregate(aggregate),
+	})
+	return nil
+}
+
+// GetRatingTimeline 读取电影按天统计的评分
+func (s *Store) GetRatingTimeline(ctx context.Context, movieID string) ([]store.DayStat, error) {
+	data, err := s.GetMovieWithFamilies(ctx, movieID, []string{store.TimelineFamily})
+	if err != nil || data == nil {
+		return []store.DayStat{}, err
+	}
+	return store.ParseTimeline(data[store.TimelineFamily]), nil
+}
+
+// UpdateRatingTimeline 原子更新按天统计的评分
+func (s *Store) UpdateRatingTimeline(ctx context.Context, movieID string, old, updated store.UserRating) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.tables[movieTable]
+	current := t.get(movieID, []string{store.TimelineFamily})[store.TimelineFamily]
+	for qualifier, amount := range store.TimelineDeltas(old, updated) {
+		t.put(movieID, store.TimelineFamily, qualifier, store.EncodeCounter(store.DecodeCounter(current[qualifier])+amount))
+	}
+	return nil
+}
+
+// PutRatingTimeline 覆盖写入按天统计的评分
+func (s *Store) PutRatingTimeline(ctx context.Context, movieID string, ratings []store.UserRating) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.tables[movieTable]
+	if row, ok := t[movieID]; ok {
+		delete(row, store.TimelineFamily)
+	}
+	for qualifier, value := range store.ComputeTimeline(ratings) {
+		t.put(movieID, store.TimelineFamily, qualifier, value)
+	}
+	return nil
+}
+
+// PutAlias 写入电影的本地化标题
+func (s *Store) PutAlias(ctx context.Context, movieID, locale, title string) error {
+	s.Put(movieTable, movieID, map[string]map[string][]byte{
+		store.AliasFamily: {locale: []byte(title)},
+	})
+	return nil
+}
+
+// PutTag 仅当用户还没有标签时写入标签
+func (s *Store) PutTag(ctx context.Context, movieID, userID, tag string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.tables[movieTable]
+	if len(t.get(movieID, []string{store.TagFamily})[store.TagFamily][store.TagColumn(userID)]) > 0 {
+		return false, nil
+	}
+	t.put(movieID, store.TagFamily, store.TagColumn(userID), []byte(tag))
+	return true, nil
+}
+
+// DeleteTag 删除用户对电影的标签
+func (s *Store) DeleteTag(ctx context.Context, movieID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tables[movieTable].delete(movieID, store.TagFamily, store.TagColumn(userID))
+	return nil
+}
+
+// GetPendingTag 读取待审核标签
+func (s *Store) GetPendingTag(ctx context.Context, movieID, userID string) (*store.PendingTag, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tables[store.TagQueueTable].pendingTag(movieID, userID), nil
+}
+
+// ListPendingTags 读取最早提交的待审核标签
+func (s *Store) ListPendingTags(ctx context.Context, limit int64) ([]store.PendingTag, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t := s.tables[store.TagQueueTable]
+	var pending []store.PendingTag
+	for _, key := range t.keys(store.TagQueueStartRow, store.TagQueueStopRow) {
+		if int64(len(pending)) >= limit {
+			break
+		}
+		if tag := store.ParsePendingTag(key, t.get(key, []string{store.TagQueueFamily})[store.TagQueueFamily]); tag != nil {
+			pending = append(pending, *tag)
+		}
+	}
+	return pending, nil
+}
+
+// PutPendingTag 仅当用户对该电影没有待审核标签时加入待审核队列
+func (s *Store) PutPendingTag(ctx context.Context, pending store.PendingTag) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.tables[store.TagQueueTable]
+	if t.pendingTag(pending.MovieID, pending.UserID) != nil {
+		return false, nil
+	}
+	for qualifier, value := range store.EncodePendingTag(pending) {
+		t.put(store.TagPendingKey(pending.MovieID, pending.UserID), store.TagQueueFamily, qualifier, value)
+		t.put(store.TagQueueKey(pending), store.TagQueueFamily, qualifier, value)
+	}
+	return true, nil
+}
+
+// DeletePendingTag 删除待审核标签的队列条目和查找条目
+func (s *Store) DeletePendingTag(ctx context.Context, movieID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.tables[store.TagQueueTable]
+	if pending := t.pendingTag(movieID, userID); pending != nil {
+		delete(t, store.TagQueueKey(*pending))
+	}
+	delete(t, store.TagPendingKey(movieID, userID))
+	return nil
+}
+
+// pendingTag 读取待审核标签的查找条目，调用方需持有读锁
+func (t table) pendingTag(movieID, userID string) *store.PendingTag {
+	key := store.TagPendingKey(movieID, userID)
+	return store.ParsePendingTag(key, t.get(key, []string{store.TagQueueFamily})[store.TagQueueFamily])
+}
+
+// UpdateMovieIndex 同步电影的二级索引条目
+func (s *Store) UpdateMovieIndex(ctx context.Context, movieID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.updateIndex(movieID)
+	return nil
+}
+
+// updateIndex 根据电影当前数据同步索引表和反向行，调用方需持有写锁
+func (s *Store) updateIndex(movieID string) {
+	index := s.tables[store.IndexTable]
+	reverseKey := store.ReverseIndexKey(movieID)
+
+	var desired []string
+	if data := s.tables[movieTable].get(movieID, store.IndexFamilies); data != nil {
+		desired = store.MovieIndexKeys(movieID, data)
+	}
+
+	var current []string
+	for key := range index[reverseKey][store.IndexFamily] {
+		current = append(current, key)
+	}
+	sort.Strings(current)
+
+	added, removed := store.DiffIndexKeys(current, desired)
+	for _, key := range added {
+		index.put(key, store.IndexFamily, "movieId", []byte(movieID))
+		index.put(reverseKey, store.IndexFamily, key, []byte{})
+	}
+	for _, key := range removed {
+		index.delete(key, store.IndexFamily, "movieId")
+		index.delete(reverseKey, store.IndexFamily, key)
+	}
+}
+
+// scanIndex 扫描索引表 [startRow, stopRow)，返回去重后的 movieId，limit 小于0表示不限
+func (s *Store) scanIndex(startRow, stopRow string, limit int64) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var movieIDs []string
+	for _, key := range s.tables[store.IndexTable].keys(startRow, stopRow) {
+		if limit >= 0 && int64(len(movieIDs)) >= limit {
+			break
+		}
+		movieID := store.IndexMovieID(key)
+		if !seen[movieID] {
+			seen[movieID] = true
+			movieIDs = append(movieIDs, movieID)
+		}
+	}
+	return movieIDs
+}
+
+// containsString 判断切片中是否包含指定字符串
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"gohbase/utils/cache"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// cacheCollector 在每次抓取时从缓存读取按键前缀划分的统计
+type cacheCollector struct {
+	cache     *cache.MemoryCache
+	hits      *prometheus.Desc
+	misses    *prometheus.Desc
+	evictions *prometheus.Desc
+	items     *prometheus.Desc
+	bytes     *prometheus.Desc
+}
+
+// RegisterCache 注册缓存指标，需在缓存初始化之后调用
+func RegisterCache(memoryCache *cache.MemoryCache) error {
+	labels := []string{"prefix"}
+	return prometheus.Register(&cacheCollector{
+		cache: memoryCache,
+		hits: prometheus.NewDesc(prometheus.BuildFQName(namespace, "cache", "hits_total"),
+			"缓存命中总数", labels, nil),
+		misses: prometheus.NewDesc(prometheus.BuildFQName(namespace, "cache", "misses_total"),
+			"缓存未命中总数", labels, nil),
+		evictions: prometheus.NewDesc(prometheus.BuildFQName(namespace, "cache", "evictions_total"),
+			"缓存淘汰总数", labels, nil),
+		items: prometheus.NewDesc(prometheus.BuildFQName(namespace, "cache", "items"),
+			"当前缓存项数量", labels, nil),
+		bytes: prometheus.NewDesc(prometheus.BuildFQName(namespace, "cache", "bytes"),
+			"当前缓存项占用字节数（估算值）", labels, nil),
+	})
+}
+
+// Describe 实现 prometheus.Collector
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.evictions
+	ch <- c.items
+	ch <- c.bytes
+}
+
+// Collect 实现 prometheus.Collector
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	for prefix, stats := range c.cache.PrefixStats() {
+		ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits), prefix)
+		ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses), prefix)
+		ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(stats.Evictions), prefix)
+		ch <- prometheus.MustNewConstMetric(c.items, prometheus.GaugeValue, float64(stats.Items), prefix)
+		ch <- prometheus.MustNewConstMetric(c.bytes, prometheus.GaugeValue, float64(stats.Bytes), prefix)
+	}
+}
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// namespace 所有指标的命名空间
+const namespace = "teddyscore"
+
+var (
+	// httpRequests HTTP请求数，按路由、方法和状态码区分
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP请求总数",
+	}, []string{"method", "route", "status"})
+
+	// httpDuration HTTP请求耗时
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP请求耗时（秒）",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// hbaseDuration HBase调用耗时，按调用位置和操作类型区分
+	hbaseDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "hbase",
+		Name:      "call_duration_seconds",
+		Help:      "HBase调用耗时（秒），扫描操作为从打开到关闭扫描器的总耗时",
+		Buckets:   []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"site", "op"})
+
+	// hbaseErrors HBase调用错误数
+	hbaseErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "hbase",
+		Name:      "errors_total",
+		Help:      "HBase调用错误总数",
+	}, []string{"site", "op"})
+
+	// hbaseRowsScanned HBase扫描返回的行数
+	hbaseRowsScanned = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "hbase",
+		Name:      "rows_scanned_total",
+		Help:      "HBase扫描读取的行数",
+	}, []string{"site"})
+)
+
+// GinMiddleware 记录每个请求的数量和耗时，路由使用注册时的模板（如 /api/movies/:id）以控制标签数量
+func GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		httpDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObserveHBaseCall 记录一次HBase调用的耗时和错误
+func ObserveHBaseCall(site, op string, start time.Time, err error) {
+	hbaseDuration.WithLabelValues(site, op).Observe(time.Since(start).Seconds())
+	if err != nil {
+		hbaseErrors.WithLabelValues(site, op).Inc()
+	}
+}
+
+// ObserveHBaseRows 记录HBase扫描读取的行数
+func ObserveHBaseRows(site string, rows int) {
+	hbaseRowsScanned.WithLabelValues(site).Add(float64(rows))
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"gohbase/utils/recommend"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Recommender 对外暴露的全局推荐引擎
+var Recommender *recommend.Engine
+
+// InitRecommender 初始化协同过滤推荐，优先加载 path 中上次训练的模型，没有可用模型时在后台训练，之后按 interval 在后台重新训练
+// 训练需要读取全部评分，不阻塞服务启动，模型就绪前推荐退回加权评分排行
+func InitRecommender(neighbors int, path string, interval time.Duration) {
+	recommend.InitEngine(loadAllRatings, neighbors, path, interval)
+	Recommender = recommend.Default
+
+	if err := Recommender.Load(); err != nil && !os.IsNotExist(err) {
+		logrus.Warnf("无法使用已保存的推荐模型，将重新训练: %v", err)
+	}
+	Recommender.Start()
+}
+
+// RecommendModel 返回当前推荐模型，推荐引擎未初始化或模型尚未就绪时返回 nil
+func RecommendModel() *recommend.Model {
+	if Recommender == nil {
+		return nil
+	}
+	return Recommender.Model()
+}
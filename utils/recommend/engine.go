@@ -0,0 +1,111 @@
+package recommend
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"gohbase/utils/refresher"
+	"gohbase/utils/store"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Source 读取全部评分，键为 movieId
+type Source func(ctx context.Context) (map[string][]store.UserRating, error)
+
+// Engine 持有当前的推荐模型，按固定间隔在后台重新训练，每次训练后把模型写入本地文件
+type Engine struct {
+	*refresher.Refresher[Model]
+	path string
+}
+
+// NewEngine 创建推荐引擎，path 为模型文件路径（为空时不持久化）
+// 可先调用 Load 加载上次训练的模型；调用 Start 后，没有模型或模型已超过 interval 未更新时在后台立即训练一次
+func NewEngine(source Source, neighbors int, path string, interval time.Duration) *Engine {
+	e := &Engine{path: path}
+
+	// 读取全部评分重新训练模型，完成后写入模型文件；写文件失败只记录日志
+	train := func(ctx context.Context) (*Model, error) {
+		start := time.Now()
+		ratings, err := source(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		model := Train(ratings, neighbors)
+		logrus.Infof("推荐模型训练完成 [电影数: %d, 用户数: %d, 评分数: %d, 每部保留: %d, 耗时: %s]",
+			len(model.Items), model.Users, model.Ratings, model.Neighbors, time.Since(start).Round(time.Millisecond))
+
+		if err := e.save(model); err != nil {
+			logrus.Errorf("保存推荐模型失败，重启后需重新训练: %v", err)
+		}
+		return model, nil
+	}
+
+	e.Refresher = refresher.New("训练推荐模型", train, interval)
+	return e
+}
+
+// Load 从模型文件加载上次训练的模型，文件不存在时返回的错误满足 os.IsNotExist
+func (e *Engine) Load() error {
+	if e.path == "" {
+		return os.ErrNotExist
+	}
+
+	file, err := os.Open(e.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var model Model
+	if err := gob.NewDecoder(file).Decode(&model); err != nil {
+		return fmt.Errorf("解析推荐模型文件 %s 失败: %w", e.path, err)
+	}
+	if model.Version != ModelVersion {
+		return fmt.Errorf("推荐模型文件 %s 的版本为 %d，当前版本为 %d", e.path, model.Version, ModelVersion)
+	}
+
+	e.Set(&model, model.BuiltAt)
+
+	logrus.Infof("已加载推荐模型 [文件: %s, 训练时间: %s, 电影数: %d, 用户数: %d]",
+		e.path, model.BuiltAt.Format(time.RFC3339), len(model.Items), model.Users)
+	return nil
+}
+
+// save 将模型写入临时文件后重命名，避免进程中途退出留下不完整的模型文件
+func (e *Engine) save(model *Model) error {
+	if e.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(e.path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(e.path), filepath.Base(e.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := gob.NewEncoder(tmp).Encode(model); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), e.path)
+}
+
+// Model 返回当前推荐模型，尚未加载或训练时返回 nil
+func (e *Engine) Model() *Model {
+	return e.Value()
+}
@@ -0,0 +1,13 @@
+package recommend
+
+import (
+	"time"
+)
+
+// Default 全局推荐引擎实例
+var Default *Engine
+
+// InitEngine 初始化全局推荐引擎
+func InitEngine(source Source, neighbors int, path string, interval time.Duration) {
+	Default = NewEngine(source, neighbors, path, interval)
+}
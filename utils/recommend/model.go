@@ -0,0 +1,215 @@
+package recommend
+
+import (
+	"gohbase/utils/store"
+	"gohbase/utils/topk"
+	"math"
+	"sort"
+	"time"
+)
+
+// ModelVersion 模型文件格式版本，结构变化时递增，旧版本的模型文件会被忽略并重新训练
+const ModelVersion = 2
+
+const (
+	// shrinkage 相似度收缩系数：共同评分用户数为 n 的两部电影，相似度乘以 n/(n+shrinkage)，避免少数用户造成的偶然高相似度
+	shrinkage = 10
+	// predictionDamping 预测评分的阻尼：相似度之和较小（依据不足）的预测向用户平均分收缩
+	predictionDamping = 1
+)
+
+// Neighbor 与某部电影相似的一部电影
+type Neighbor struct {
+	MovieID    string
+	Similarity float64
+}
+
+// Model 基于物品的协同过滤模型（item-item CF），可通过 gob 持久化到本地文件
+// Items 为每部电影最相似的若干部电影（调整余弦相似度，只保留正相关）；模型不保存用户评分，推荐时使用用户当前的评分
+type Model struct {
+	Version   int
+	BuiltAt   time.Time
+	Neighbors int
+	Users     int
+	Ratings   int
+	Items     map[string][]Neighbor
+}
+
+// Recommendation 一条推荐，Predicted 为预测评分，Support 为参与预测的相似度之和
+type Recommendation struct {
+	MovieID   string
+	Predicted float64
+	Support   float64
+}
+
+// userItem 用户对某部电影的去均值评分
+type userItem struct {
+	index    int
+	centered float64
+}
+
+// Train 根据全部评分训练模型，ratings 的键为 movieId，每部电影最多保留 neighbors 部相似电影
+// 相似度为调整余弦相似度：先减去每个用户的平均分以消除打分习惯的差异，再计算两部电影评分向量的余弦，并按共同评分用户数收缩
+func Train(ratings map[string][]store.UserRating, neighbors int) *Model {
+	model := &Model{
+		Version:   ModelVersion,
+		BuiltAt:   time.Now(),
+		Neighbors: neighbors,
+		Items:     make(map[string][]Neighbor, len(ratings)),
+	}
+
+	// 电影按 movieId 排序编号，保证同样的数据训练出同样的模型
+	movieIDs := make([]string, 0, len(ratings))
+	for movieID := range ratings {
+		movieIDs = append(movieIDs, movieID)
+	}
+	sort.Strings(movieIDs)
+
+	// 每个用户的平均分
+	sums := make(map[string]float64)
+	counts := make(map[string]int)
+	for _, movieID := range movieIDs {
+		for _, rating := range ratings[movieID] {
+			sums[rating.UserID] += rating.Rating
+			counts[rating.UserID]++
+			model.Ratings++
+		}
+	}
+	means := make(map[string]float64, len(sums))
+	for userID, sum := range sums {
+		means[userID] = sum / float64(counts[userID])
+	}
+	model.Users = len(means)
+
+	// 按用户和按电影分别整理去均值评分，并计算每部电影评分向量的模
+	byUser := make(map[string][]userItem, len(means))
+	byItem := make([][]string, len(movieIDs))
+	centered := make([]map[string]float64, len(movieIDs))
+	norms := make([]float64, len(movieIDs))
+	for i, movieID := range movieIDs {
+		centered[i] = make(map[string]float64, len(ratings[movieID]))
+		for _, rating := range ratings[movieID] {
+			value := rating.Rating - means[rating.UserID]
+			if _, ok := centered[i][rating.UserID]; ok {
+				continue
+			}
+			centered[i][rating.UserID] = value
+			byItem[i] = append(byItem[i], rating.UserID)
+			byUser[rating.UserID] = append(byUser[rating.UserID], userItem{index: i, centered: value})
+			norms[i] += value * value
+		}
+		norms[i] = math.Sqrt(norms[i])
+	}
+
+	// 对每部电影，经由评过它的用户累加与其他电影的点积及共同评分用户数，dots 和 shared 在各电影间复用
+	dots := make([]float64, len(movieIDs))
+	shared := make([]int, len(movieIDs))
+	var touched []int
+	for i, movieID := range movieIDs {
+		if norms[i] == 0 {
+			continue
+		}
+
+		touched = touched[:0]
+		for _, userID := range byItem[i] {
+			value := centered[i][userID]
+			for _, item := range byUser[userID] {
+				if item.index == i {
+					continue
+				}
+				if shared[item.index] == 0 {
+					touched = append(touched, item.index)
+				}
+				shared[item.index]++
+				dots[item.index] += value * item.centered
+			}
+		}
+
+		top := topk.New(neighbors, ranksBefore)
+		for _, j := range touched {
+			dot, count := dots[j], shared[j]
+			dots[j], shared[j] = 0, 0
+			if norms[j] == 0 || dot <= 0 {
+				continue
+			}
+
+			top.Push(Neighbor{
+				MovieID:    movieIDs[j],
+				Similarity: dot / (norms[i] * norms[j]) * float64(count) / float64(count+shrinkage),
+			})
+		}
+		if top.Len() == 0 {
+			continue
+		}
+		model.Items[movieID] = top.Sorted()
+	}
+
+	return model
+}
+
+// Recommend 根据用户当前的评分（键为 movieId）推荐 limit 部电影，按预测评分降序；excludeRated 为 true 时排除已评分的电影
+// 预测评分 = 用户平均分 + Σ sim·(r - 用户平均分) / (Σ sim + 阻尼)，累加用户评过的每部电影的相似电影
+// 训练后才评分的用户同样适用；没有评分或没有可推荐的电影时返回空
+func (m *Model) Recommend(ratings map[string]float64, limit int, excludeRated bool) []Recommendation {
+	if len(ratings) == 0 {
+		return nil
+	}
+	var mean float64
+	for _, rating := range ratings {
+		mean += rating
+	}
+	mean /= float64(len(ratings))
+
+	type score struct{ num, den float64 }
+	scores := make(map[string]*score)
+	for movieID, rating := range ratings {
+		deviation := rating - mean
+		for _, neighbor := range m.Items[movieID] {
+			if _, rated := ratings[neighbor.MovieID]; rated && excludeRated {
+				continue
+			}
+			s, ok := scores[neighbor.MovieID]
+			if !ok {
+				s = &score{}
+				scores[neighbor.MovieID] = s
+			}
+			s.num += neighbor.Similarity * deviation
+			s.den += neighbor.Similarity
+		}
+	}
+
+	recommendations := make([]Recommendation, 0, len(scores))
+	for movieID, s := range scores {
+		predicted := mean + s.num/(s.den+predictionDamping)
+		recommendations = append(recommendations, Recommendation{
+			MovieID:   movieID,
+			Predicted: math.Max(0.5, math.Min(5, predicted)),
+			Support:   s.den,
+		})
+	}
+
+	// 预测评分相同时依据更充分的在前，再按 movieId 保证顺序稳定
+	sort.Slice(recommendations, func(i, j int) bool {
+		a, b := recommendations[i], recommendations[j]
+		if a.Predicted != b.Predicted {
+			return a.Predicted > b.Predicted
+		}
+		if a.Support != b.Support {
+			return a.Support > b.Support
+		}
+		return a.MovieID < b.MovieID
+	})
+
+	if len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+	}
+	return recommendations
+}
+
+// ranksBefore 判断 a 是否排在 b 之前：相似度高的在前，相同时按 movieId 保证顺序稳定
+func ranksBefore(a, b Neighbor) bool {
+	if a.Similarity != b.Similarity {
+		return a.Similarity > b.Similarity
+	}
+	return a.MovieID < b.MovieID
+}
@@ -0,0 +1,119 @@
+package refresher
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Build 重新计算快照
+type Build[T any] func(ctx context.Context) (*T, error)
+
+// Refresher 持有当前快照，并按固定间隔在后台调用 build 重新计算，计算完成后整体替换，读取方无需加锁
+type Refresher[T any] struct {
+	mu       sync.RWMutex
+	value    *T
+	builtAt  time.Time
+	name     string // 后台任务名称，用于日志
+	build    Build[T]
+	interval time.Duration
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// New 创建刷新器，需调用 Refresh 同步计算首个快照，或由 Start 在后台计算
+func New[T any](name string, build Build[T], interval time.Duration) *Refresher[T] {
+	return &Refresher[T]{
+		name:     name,
+		build:    build,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Refresh 重新计算快照，成功后替换当前快照；失败时保留旧快照
+func (r *Refresher[T]) Refresh(ctx context.Context) error {
+	value, err := r.build(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Set(value, time.Now())
+	return nil
+}
+
+// Set 直接替换当前快照，builtAt 为快照的计算时间（如从文件加载的旧快照）
+func (r *Refresher[T]) Set(value *T, builtAt time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.value = value
+	r.builtAt = builtAt
+}
+
+// Start 启动后台刷新：尚无快照或快照已超过 interval 未更新时先立即计算一次，之后每隔 interval 重新计算
+// interval 不大于0时不定时刷新，只在尚无快照时计算一次
+func (r *Refresher[T]) Start() {
+	go func() {
+		if r.stale() {
+			r.refreshInBackground()
+		}
+		if r.interval <= 0 {
+			return
+		}
+
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.refreshInBackground()
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止后台刷新，可重复调用
+func (r *Refresher[T]) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+}
+
+// Value 返回当前快照，尚未计算时返回 nil
+func (r *Refresher[T]) Value() *T {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.value
+}
+
+// BuiltAt 返回当前快照的计算时间
+func (r *Refresher[T]) BuiltAt() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.builtAt
+}
+
+// stale 判断是否需要立即计算：尚无快照，或快照已超过 interval 未更新
+func (r *Refresher[T]) stale() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.value == nil || (r.interval > 0 && time.Since(r.builtAt) > r.interval)
+}
+
+// refreshInBackground 在后台任务中刷新，已停止时跳过，失败时只记录日志并继续使用旧快照
+func (r *Refresher[T]) refreshInBackground() {
+	select {
+	case <-r.stop:
+		return
+	default:
+	}
+
+	if err := r.Refresh(context.Background()); err != nil {
+		logrus.Errorf("后台%s失败，继续使用旧结果: %v", r.name, err)
+	}
+}
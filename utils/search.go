@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"context"
+	"gohbase/utils/search"
+	"gohbase/utils/store"
+	"sort"
+	"time"
+)
+
+// SearchEngine 对外暴露的全局搜索引擎
+var SearchEngine *search.Engine
+
+// InitSearchEngine 初始化搜索引擎，同步构建首个索引后按 interval 在后台重建
+// 首次构建失败时仍会启动后台重建，并返回错误，调用方可选择降级为全表扫描
+func InitSearchEngine(ctx context.Context, interval time.Duration) error {
+	search.InitEngine(loadSearchDocuments, interval)
+	SearchEngine = search.Default
+
+	err := SearchEngine.Refresh(ctx)
+	SearchEngine.Start()
+	return err
+}
+
+// loadSearchDocuments 遍历全部电影生成索引文档
+func loadSearchDocuments(ctx context.Context) ([]search.Document, error) {
+	var docs []search.Document
+	err := Store.ForEachMovie(ctx, store.IndexFamilies, func(row store.Row) error {
+		if !store.IsMovieRow(row) {
+			return nil
+		}
+		docs = append(docs, NewSearchDocument(row))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return docs, nil
+}
+
+// NewSearchDocument 根据电影数据（需包含 store.IndexFamilies 中的列族）生成索引文档，评分数量和平均分取自评分聚合
+func NewSearchDocument(row store.Row) search.Document {
+	movieData := ParseMovieData(row.Key, row.Data)
+
+	doc := search.Document{MovieID: row.Key}
+	if title, ok := movieData["title"].(string); ok {
+		doc.Title = title
+		doc.Year = store.ParseYear(title)
+	}
+	if localizedTitle, ok := movieData["localizedTitle"].(string); ok {
+		doc.LocalizedTitle = localizedTitle
+	}
+	if genres, ok := movieData["genres"].([]string); ok {
+		doc.Genres = genres
+	}
+	if tags, ok := movieData["uniqueTags"].([]string); ok {
+		sort.Strings(tags)
+		doc.Tags = tags
+	}
+	if aggregate := store.ParseRatingAggregate(row.Data[store.StatsFamily]); aggregate != nil {
+		doc.RatingCount = aggregate.Count
+		doc.AvgRating = aggregate.Avg()
+	}
+
+	return doc
+}
+
+// IndexedDocument 从当前搜索索引中取电影文档，索引尚未构建或电影不在索引中时返回 nil
+func IndexedDocument(movieID string) *search.Document {
+	if SearchEngine == nil || SearchEngine.Index() == nil {
+		return nil
+	}
+	doc, _ := SearchEngine.Index().Document(movieID)
+	return doc
+}
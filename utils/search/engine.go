@@ -0,0 +1,40 @@
+package search
+
+import (
+	"context"
+	"gohbase/utils/refresher"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Loader 读取全部待索引的电影
+type Loader func(ctx context.Context) ([]Document, error)
+
+// Engine 持有当前的倒排索引快照，并按固定间隔在后台重建
+type Engine struct {
+	*refresher.Refresher[Index]
+}
+
+// NewEngine 创建搜索引擎，需调用 Refresh 构建首个索引
+func NewEngine(loader Loader, interval time.Duration) *Engine {
+	build := func(ctx context.Context) (*Index, error) {
+		start := time.Now()
+		docs, err := loader(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		index := Build(docs)
+		logrus.Infof("搜索索引构建完成 [电影数: %d, 词项数: %d, 耗时: %s]",
+			index.Len(), len(index.terms), time.Since(start).Round(time.Millisecond))
+		return index, nil
+	}
+
+	return &Engine{Refresher: refresher.New("重建搜索索引", build, interval)}
+}
+
+// Index 返回当前索引快照，尚未构建时返回 nil
+func (e *Engine) Index() *Index {
+	return e.Value()
+}
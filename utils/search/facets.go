@@ -0,0 +1,104 @@
+package search
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 支持的分面字段
+const (
+	FacetGenre  = "genre"
+	FacetDecade = "decade"
+	FacetRating = "rating"
+)
+
+// FacetCount 分面中某个取值及其匹配数量
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// Facets 分面字段到各取值计数的映射
+type Facets map[string][]FacetCount
+
+// IsFacetField 判断是否为支持的分面字段
+func IsFacetField(field string) bool {
+	switch field {
+	case FacetGenre, FacetDecade, FacetRating:
+		return true
+	}
+	return false
+}
+
+// CountFacets 统计文档在各分面字段上的取值数量
+// genre 按数量降序；decade 为 "1990s" 形式，按年代升序，无年份的不计入；
+// rating 为平均分向下取整到半星的区间下限（如 "3.5" 表示 [3.5, 4.0)），按分数升序，无评分的不计入
+func CountFacets(docs []*Document, fields []string) Facets {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	facets := make(Facets, len(fields))
+	for _, field := range fields {
+		counts := make(map[string]int)
+		for _, doc := range docs {
+			for _, value := range facetValues(doc, field) {
+				counts[value]++
+			}
+		}
+		facets[field] = sortFacetCounts(field, counts)
+	}
+	return facets
+}
+
+// facetValues 返回文档在分面字段上的取值，一部电影可属于多个类型
+func facetValues(doc *Document, field string) []string {
+	switch field {
+	case FacetGenre:
+		return doc.Genres
+	case FacetDecade:
+		if doc.Year > 0 {
+			return []string{fmt.Sprintf("%ds", doc.Year/10*10)}
+		}
+	case FacetRating:
+		// 评分最低为0.5，平均分为0即没有评分
+		if doc.AvgRating > 0 {
+			return []string{strconv.FormatFloat(halfStar(doc.AvgRating), 'f', 1, 64)}
+		}
+	}
+	return nil
+}
+
+// halfStar 将平均分向下取整到半星，加上微小偏移避免 3.9999… 这类浮点误差落入低一档
+func halfStar(avg float64) float64 {
+	return math.Floor(avg*2+1e-9) / 2
+}
+
+// sortFacetCounts 将计数转为有序列表
+func sortFacetCounts(field string, counts map[string]int) []FacetCount {
+	result := make([]FacetCount, 0, len(counts))
+	for value, count := range counts {
+		result = append(result, FacetCount{Value: value, Count: count})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		switch field {
+		case FacetGenre:
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+			return strings.ToLower(a.Value) < strings.ToLower(b.Value)
+		case FacetRating:
+			x, _ := strconv.ParseFloat(a.Value, 64)
+			y, _ := strconv.ParseFloat(b.Value, 64)
+			return x < y
+		}
+		// 年代取值位数相同，按字典序即按时间顺序
+		return a.Value < b.Value
+	})
+	return result
+}
@@ -0,0 +1,157 @@
+package search
+
+import (
+	"sort"
+	"strings"
+)
+
+// 可排序的字段
+const (
+	SortTitle       = "title"
+	SortYear        = "year"
+	SortAvgRating   = "avgRating"
+	SortRatingCount = "ratingCount"
+)
+
+// Filter 结构化筛选条件，零值字段表示不限
+type Filter struct {
+	Genres         []string // 类型，不区分大小写
+	MatchAllGenres bool     // true 时须包含全部类型，否则包含任一即可
+	YearFrom       int
+	YearTo         int
+	MinRating      float64
+	MaxRating      float64
+	MinVotes       int64    // 最少评分人数
+	Tags           []string // 须包含全部标签，不区分大小写
+}
+
+// IsEmpty 判断是否没有任何筛选条件
+func (f Filter) IsEmpty() bool {
+	return len(f.Genres) == 0 && f.YearFrom == 0 && f.YearTo == 0 && f.MinRating == 0 &&
+		f.MaxRating == 0 && f.MinVotes == 0 && len(f.Tags) == 0
+}
+
+// Match 判断文档是否满足筛选条件
+func (f Filter) Match(doc *Document) bool {
+	if f.YearFrom > 0 && doc.Year < f.YearFrom {
+		return false
+	}
+	if f.YearTo > 0 && (doc.Year == 0 || doc.Year > f.YearTo) {
+		return false
+	}
+	if f.MinRating > 0 && (doc.RatingCount == 0 || doc.AvgRating < f.MinRating) {
+		return false
+	}
+	if f.MaxRating > 0 && doc.AvgRating > f.MaxRating {
+		return false
+	}
+	if doc.RatingCount < f.MinVotes {
+		return false
+	}
+
+	if len(f.Genres) > 0 {
+		matched := 0
+		for _, genre := range f.Genres {
+			if containsFold(doc.Genres, genre) {
+				matched++
+			}
+		}
+		if matched == 0 || (f.MatchAllGenres && matched < len(f.Genres)) {
+			return false
+		}
+	}
+
+	for _, tag := range f.Tags {
+		if !containsFold(doc.Tags, tag) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Filter 返回满足条件的全部文档，按 movieId 排序
+func (idx *Index) Filter(filter Filter) []*Document {
+	var docs []*Document
+	for i := range idx.docs {
+		if filter.Match(&idx.docs[i]) {
+			docs = append(docs, &idx.docs[i])
+		}
+	}
+
+	sort.Slice(docs, func(i, j int) bool {
+		return lessMovieID(docs[i].MovieID, docs[j].MovieID)
+	})
+	return docs
+}
+
+// SortDocuments 按字段排序，值相同时按 movieId 排序以保证分页稳定
+func SortDocuments(docs []*Document, field string, descending bool) {
+	sort.SliceStable(docs, func(i, j int) bool {
+		return CompareDocuments(docs[i], docs[j], field, descending) < 0
+	})
+}
+
+// CompareDocuments 按排序字段比较两个文档在结果中的先后，字段相同时按 movieId 字典序升序，field 为空时只比较 movieId
+func CompareDocuments(a, b *Document, field string, descending bool) int {
+	var cmp int
+	switch field {
+	case SortTitle:
+		cmp = strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	case SortYear:
+		cmp = compareNumbers(float64(a.Year), float64(b.Year))
+	case SortAvgRating:
+		cmp = compareNumbers(a.AvgRating, b.AvgRating)
+	case SortRatingCount:
+		cmp = compareNumbers(float64(a.RatingCount), float64(b.RatingCount))
+	}
+
+	if cmp != 0 {
+		if descending {
+			return -cmp
+		}
+		return cmp
+	}
+	switch {
+	case lessMovieID(a.MovieID, b.MovieID):
+		return -1
+	case lessMovieID(b.MovieID, a.MovieID):
+		return 1
+	}
+	return 0
+}
+
+// IsSortField 判断是否为支持的排序字段
+func IsSortField(field string) bool {
+	switch field {
+	case SortTitle, SortYear, SortAvgRating, SortRatingCount:
+		return true
+	}
+	return false
+}
+
+// compareNumbers 比较两个数
+func compareNumbers(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// lessMovieID 按字典序比较 movieId，与不带筛选条件的电影列表按行键排列的顺序一致
+func lessMovieID(a, b string) bool {
+	return a < b
+}
+
+// containsFold 判断切片中是否包含指定字符串（不区分大小写）
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+	return false
+}
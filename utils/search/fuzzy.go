@@ -0,0 +1,81 @@
+package search
+
+import (
+	"unicode/utf8"
+)
+
+// fuzzyWeight 词项仅通过编辑距离匹配时的得分折扣
+const fuzzyWeight = 0.4
+
+// maxEditDistance 根据查询词长度决定允许的编辑距离：3个字符及以下必须精确，4~7个字符允许1处错误，更长允许2处
+func maxEditDistance(token string) int {
+	switch length := utf8.RuneCountInString(token); {
+	case length <= 3:
+		return 0
+	case length <= 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// fuzzyTerms 返回与查询词编辑距离在允许范围内的词项
+func fuzzyTerms(terms []string, token string) []string {
+	limit := maxEditDistance(token)
+	if limit == 0 {
+		return nil
+	}
+
+	source := []rune(token)
+	var matches []string
+	for _, term := range terms {
+		if abs(utf8.RuneCountInString(term)-len(source)) > limit {
+			continue
+		}
+		if editDistance(source, []rune(term), limit) <= limit {
+			matches = append(matches, term)
+		}
+	}
+	return matches
+}
+
+// editDistance 计算两个字符串的编辑距离（插入、删除、替换、相邻交换各计1），超过 limit 时提前返回 limit+1
+func editDistance(a, b []rune, limit int) int {
+	// 使用三行滚动数组实现 Damerau-Levenshtein（最优字符串对齐）距离
+	prevPrev := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		rowMin := current[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(prev[j]+1, current[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				current[j] = min(current[j], prevPrev[j-2]+1)
+			}
+			rowMin = min(rowMin, current[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prevPrev, prev, current = prev, current, prevPrev
+	}
+
+	return prev[len(b)]
+}
+
+// abs 整数绝对值
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,44 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		limit int
+		want  int
+	}{
+		{"matrix", "matrix", 2, 0},
+		{"matrix", "matrx", 2, 1},
+		{"matrix", "mtarix", 2, 1}, // 相邻交换计1
+		{"matrix", "matrics", 2, 2},
+		{"matrix", "forrest", 1, 2}, // 超过 limit 时返回 limit+1
+		{"", "abc", 3, 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance([]rune(tt.a), []rune(tt.b), tt.limit); got != tt.want {
+			t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestFuzzyTerms(t *testing.T) {
+	terms := []string{"forest", "forrest", "gump", "matrix", "matrices"}
+	tests := []struct {
+		token string
+		want  []string
+	}{
+		{"gum", nil}, // 3个字符以下必须精确
+		{"forrest", []string{"forest", "forrest"}},
+		{"matrx", []string{"matrix"}},
+		{"matrixes", []string{"matrix", "matrices"}},
+	}
+	for _, tt := range tests {
+		if got := fuzzyTerms(terms, tt.token); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("fuzzyTerms(%q) = %q, want %q", tt.token, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// BM25 参数
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	// popularityBoost 评分数量加权系数，最终得分为 BM25 × (1 + popularityBoost × ln(1 + 评分数))
+	popularityBoost = 0.1
+	// prefixWeight 词项仅前缀匹配时的得分折扣，便于输入未完成时也能命中
+	prefixWeight = 0.5
+	// maxPrefixExpansions 单个查询词最多展开的前缀匹配词项数
+	maxPrefixExpansions = 50
+)
+
+// 各字段的权重，标题命中比类型、标签命中更重要
+const (
+	titleWeight  = 3.0
+	pinyinWeight = 1.5
+	genreWeight  = 1.0
+	tagWeight    = 1.0
+)
+
+// Document 参与索引的电影
+type Document struct {
+	MovieID        string
+	Title          string // 原始标题
+	LocalizedTitle string // 中文标题，来自 alias 列族
+	Genres         []string
+	Tags           []string
+	Year           int
+	RatingCount    int64
+	AvgRating      float64
+}
+
+// Hit 一条搜索结果
+type Hit struct {
+	Document *Document
+	Score    float64
+}
+
+// posting 倒排表中的一项，tf 为按字段权重加权后的词频
+type posting struct {
+	doc int
+	tf  float64
+}
+
+// Index 只读的倒排索引快照，构建后可被多个协程并发查询
+type Index struct {
+	docs      []Document
+	byID      map[string]int
+	postings  map[string][]posting
+	terms     []string // 有序词项，用于前缀匹配
+	docLength []float64
+	avgLength float64
+
+	// 仅由标题产生的词项，用于自动补全
+	titleDocs  map[string][]int
+	titleTerms []string
+	titleTexts [][]string // 各标题形式分词后以空格连接（拼音为连写），用于判断输入是否为标题开头
+}
+
+// Build 根据文档构建倒排索引
+func Build(docs []Document) *Index {
+	index := &Index{
+		docs:       docs,
+		byID:       make(map[string]int, len(docs)),
+		postings:   make(map[string][]posting),
+		docLength:  make([]float64, len(docs)),
+		titleDocs:  make(map[string][]int),
+		titleTexts: make([][]string, len(docs)),
+	}
+
+	var totalLength float64
+	for i := range docs {
+		doc := &docs[i]
+		index.byID[doc.MovieID] = i
+
+		frequencies := make(map[string]float64)
+		var titleTerms []string
+		addTerms := func(terms []string, weight float64) {
+			for _, term := range terms {
+				frequencies[term] += weight
+				index.docLength[i] += weight
+			}
+		}
+
+		// 英文标题、中文标题及其拼音都参与自动补全
+		normalizedTitle := Tokenize(NormalizeTitle(doc.Title))
+		addTerms(normalizedTitle, titleWeight)
+		titleTerms = append(titleTerms, normalizedTitle...)
+		index.titleTexts[i] = append(index.titleTexts[i], strings.Join(normalizedTitle, " "))
+
+		if doc.LocalizedTitle != "" {
+			localizedTitle := Tokenize(doc.LocalizedTitle)
+			addTerms(localizedTitle, titleWeight)
+			titleTerms = append(titleTerms, localizedTitle...)
+			index.titleTexts[i] = append(index.titleTexts[i], strings.Join(localizedTitle, " "))
+
+			if pinyinTerms := PinyinTerms(doc.LocalizedTitle); len(pinyinTerms) > 0 {
+				addTerms(pinyinTerms, pinyinWeight)
+				titleTerms = append(titleTerms, pinyinTerms...)
+				// 全拼连写和首字母连写位于末尾
+				index.titleTexts[i] = append(index.titleTexts[i], pinyinTerms[len(pinyinTerms)-2:]...)
+			}
+		}
+
+		for _, term := range uniqueStrings(titleTerms) {
+			index.titleDocs[term] = append(index.titleDocs[term], i)
+		}
+		for _, genre := range doc.Genres {
+			addTerms(Tokenize(genre), genreWeight)
+		}
+		for _, tag := range doc.Tags {
+			addTerms(Tokenize(tag), tagWeight)
+		}
+
+		for term, tf := range frequencies {
+			index.postings[term] = append(index.postings[term], posting{doc: i, tf: tf})
+		}
+		totalLength += index.docLength[i]
+	}
+
+	if len(docs) > 0 {
+		index.avgLength = totalLength / float64(len(docs))
+	}
+
+	index.terms = make([]string, 0, len(index.postings))
+	for term := range index.postings {
+		index.terms = append(index.terms, term)
+	}
+	sort.Strings(index.terms)
+
+	index.titleTerms = make([]string, 0, len(index.titleDocs))
+	for term := range index.titleDocs {
+		index.titleTerms = append(index.titleTerms, term)
+	}
+	sort.Strings(index.titleTerms)
+
+	return index
+}
+
+// uniqueStrings 去重，保持首次出现的顺序
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var result []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// Len 返回索引中的文档数量
+func (idx *Index) Len() int {
+	return len(idx.docs)
+}
+
+// Document 根据 movieId 获取文档
+func (idx *Index) Document(movieID string) (*Document, bool) {
+	i, ok := idx.byID[movieID]
+	if !ok {
+		return nil, false
+	}
+	return &idx.docs[i], true
+}
+
+// Search 搜索文档，查询中的每个词都须命中（完整词项或词项前缀），结果按得分从高到低排列
+func (idx *Index) Search(query string) []Hit {
+	tokens := Tokenize(query)
+	if len(tokens) == 0 {
+		return []Hit{}
+	}
+
+	var scores map[int]float64
+	for _, token := range tokens {
+		tokenScores := idx.scoreToken(token)
+		if scores == nil {
+			scores = tokenScores
+			continue
+		}
+
+		// 只保留所有查询词都命中的文档
+		for doc, score := range scores {
+			if tokenScore, ok := tokenScores[doc]; ok {
+				scores[doc] = score + tokenScore
+			} else {
+				delete(scores, doc)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for doc, score := range scores {
+		document := &idx.docs[doc]
+		score *= 1 + popularityBoost*math.Log1p(float64(document.RatingCount))
+		hits = append(hits, Hit{Document: document, Score: score})
+	}
+	sortHits(hits)
+
+	return hits
+}
+
+// scoreToken 计算单个查询词对各文档的得分，前缀展开的词项取最高分而不累加
+func (idx *Index) scoreToken(token string) map[int]float64 {
+	scores := make(map[int]float64)
+
+	for _, match := range idx.expand(token) {
+		postings := idx.postings[match.term]
+		idf := math.Log(1 + (float64(len(idx.docs))-float64(len(postings))+0.5)/(float64(len(postings))+0.5))
+
+		for _, p := range postings {
+			norm := bm25K1 * (1 - bm25B + bm25B*idx.docLength[p.doc]/idx.avgLength)
+			score := match.weight * idf * p.tf * (bm25K1 + 1) / (p.tf + norm)
+			if score > scores[p.doc] {
+				scores[p.doc] = score
+			}
+		}
+	}
+
+	return scores
+}
+
+// termMatch 查询词展开得到的词项及其权重
+type termMatch struct {
+	term   string
+	weight float64
+}
+
+// expand 将查询词展开为完整匹配的词项和以其为前缀的词项，没有完整匹配时再加入编辑距离相近的词项
+func (idx *Index) expand(token string) []termMatch {
+	var matches []termMatch
+	exact := false
+	for _, term := range prefixTerms(idx.terms, token, maxPrefixExpansions) {
+		weight := prefixWeight
+		if term == token {
+			weight = 1
+			exact = true
+		}
+		matches = append(matches, termMatch{term: term, weight: weight})
+	}
+
+	if !exact {
+		for _, term := range fuzzyTerms(idx.terms, token) {
+			if !strings.HasPrefix(term, token) {
+				matches = append(matches, termMatch{term: term, weight: fuzzyWeight})
+			}
+		}
+	}
+
+	return matches
+}
+
+// prefixTerms 返回有序词项中以 prefix 开头的词项，limit 不大于0表示不限
+func prefixTerms(terms []string, prefix string, limit int) []string {
+	var matches []string
+	for i := sort.SearchStrings(terms, prefix); i < len(terms); i++ {
+		if !strings.HasPrefix(terms[i], prefix) || (limit > 0 && len(matches) >= limit) {
+			break
+		}
+		matches = append(matches, terms[i])
+	}
+	return matches
+}
+
+// sortHits 按得分从高到低排序，得分相同时按 movieId 排序以保证结果稳定
+func sortHits(hits []Hit) {
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Document.MovieID < hits[j].Document.MovieID
+	})
+}
@@ -0,0 +1,102 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testDocuments 测试用的电影文档
+func testDocuments() []Document {
+	return []Document{
+		{MovieID: "1", Title: "Toy Story (1995)", LocalizedTitle: "玩具总动员", Genres: []string{"Animation", "Comedy"}, Year: 1995, RatingCount: 200},
+		{MovieID: "2", Title: "Toy Soldiers (1991)", Genres: []string{"Action"}, Year: 1991, RatingCount: 5},
+		{MovieID: "3", Title: "Matrix, The (1999)", LocalizedTitle: "黑客帝国", Genres: []string{"Action", "Sci-Fi"}, Year: 1999, RatingCount: 300},
+		{MovieID: "4", Title: "Forrest Gump (1994)", LocalizedTitle: "阿甘正传", Genres: []string{"Comedy", "Drama"}, Tags: []string{"classic"}, Year: 1994, RatingCount: 250},
+		{MovieID: "5", Title: "Shawshank Redemption, The (1994)", LocalizedTitle: "肖申克的救赎", Genres: []string{"Drama"}, Year: 1994, RatingCount: 280},
+	}
+}
+
+// hitIDs 返回搜索结果的 movieId
+func hitIDs(hits []Hit) []string {
+	ids := []string{}
+	for _, hit := range hits {
+		ids = append(ids, hit.Document.MovieID)
+	}
+	return ids
+}
+
+func TestIndexSearch(t *testing.T) {
+	index := Build(testDocuments())
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"完整词项", "matrix", []string{"3"}},
+		{"全部词须命中", "toy story", []string{"1"}},
+		{"前缀匹配", "sold", []string{"2"}},
+		{"标题优先于类型", "action", []string{"2", "3"}},
+		{"编辑距离", "forest gump", []string{"4"}},
+		{"中文双字", "救赎", []string{"5"}},
+		{"拼音全拼", "heikediguo", []string{"3"}},
+		{"拼音首字母", "wjzdy", []string{"1"}},
+		{"标签", "classic", []string{"4"}},
+		{"无结果", "zzzz", []string{}},
+		{"空查询", "  ", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hitIDs(index.Search(tt.query)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexSearchScoring(t *testing.T) {
+	index := Build(testDocuments())
+
+	// 完整词项的得分高于仅前缀匹配
+	hits := index.Search("toy")
+	if len(hits) != 2 {
+		t.Fatalf("Search(toy) = %v, want 2 hits", hitIDs(hits))
+	}
+	exact := index.Search("story")[0].Score
+	prefix := index.Search("stor")[0].Score
+	if prefix >= exact {
+		t.Errorf("prefix score %v should be lower than exact score %v", prefix, exact)
+	}
+
+	// 词项相同时评分人数多的排在前面
+	if hits[0].Document.MovieID != "1" {
+		t.Errorf("Search(toy) first hit = %s, want the more popular movie 1", hits[0].Document.MovieID)
+	}
+}
+
+func TestIndexSuggest(t *testing.T) {
+	index := Build(testDocuments())
+
+	tests := []struct {
+		query string
+		limit int
+		want  []string
+	}{
+		{"to", 10, []string{"1", "2"}},
+		{"toy s", 10, []string{"1", "2"}},
+		{"toy s", 1, []string{"1"}},
+		{"the m", 10, []string{"3"}},
+		{"xsk", 10, []string{"5"}},
+		{"matirx", 10, []string{"3"}},
+		{"toy", 0, []string{}},
+	}
+	for _, tt := range tests {
+		ids := []string{}
+		for _, doc := range index.Suggest(tt.query, tt.limit) {
+			ids = append(ids, doc.MovieID)
+		}
+		if !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("Suggest(%q, %d) = %v, want %v", tt.query, tt.limit, ids, tt.want)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package search
+
+import (
+	"time"
+)
+
+// Default 全局搜索引擎实例
+var Default *Engine
+
+// InitEngine 初始化全局搜索引擎
+func InitEngine(loader Loader, interval time.Duration) {
+	Default = NewEngine(loader, interval)
+}
@@ -0,0 +1,31 @@
+package search
+
+import (
+	"strings"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// pinyinArgs 不带声调、多音字取常用读音
+var pinyinArgs = pinyin.NewArgs()
+
+// PinyinTerms 返回中文标题的拼音词项：每个音节、全拼连写和首字母连写
+// 例如 "肖申克的救赎" -> xiao shen ke de jiu shu xiaoshenkedejiushu xskdjs，不含汉字时返回 nil
+func PinyinTerms(text string) []string {
+	if !containsHan(text) {
+		return nil
+	}
+
+	syllables := pinyin.LazyPinyin(text, pinyinArgs)
+	if len(syllables) == 0 {
+		return nil
+	}
+
+	var initials strings.Builder
+	for _, syllable := range syllables {
+		initials.WriteByte(syllable[0])
+	}
+
+	terms := append([]string{}, syllables...)
+	return append(terms, strings.Join(syllables, ""), initials.String())
+}
@@ -0,0 +1,23 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPinyinTerms(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"肖申克的救赎", []string{"xiao", "shen", "ke", "de", "jiu", "shu", "xiaoshenkedejiushu", "xskdjs"}},
+		{"玩具总动员", []string{"wan", "ju", "zong", "dong", "yuan", "wanjuzongdongyuan", "wjzdy"}},
+		{"Toy Story", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := PinyinTerms(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PinyinTerms(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
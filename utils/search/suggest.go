@@ -0,0 +1,86 @@
+package search
+
+import (
+	"sort"
+	"strings"
+)
+
+// Suggest 标题自动补全：最后一个词按前缀匹配，之前的词须完整匹配，均无结果时改用编辑距离匹配
+// 标题开头与输入一致的排在前面，其余按评分人数从多到少排列
+func (idx *Index) Suggest(query string, limit int) []*Document {
+	tokens := Tokenize(query)
+	if len(tokens) == 0 || limit <= 0 {
+		return []*Document{}
+	}
+
+	candidates := idx.suggestCandidates(tokens, false)
+	if len(candidates) == 0 {
+		candidates = idx.suggestCandidates(tokens, true)
+	}
+
+	typed := strings.Join(tokens, " ")
+	leading := make(map[int]bool, len(candidates))
+	for _, doc := range candidates {
+		for _, text := range idx.titleTexts[doc] {
+			if strings.HasPrefix(text, typed) {
+				leading[doc] = true
+				break
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if leading[a] != leading[b] {
+			return leading[a]
+		}
+		if idx.docs[a].RatingCount != idx.docs[b].RatingCount {
+			return idx.docs[a].RatingCount > idx.docs[b].RatingCount
+		}
+		return idx.docs[a].Title < idx.docs[b].Title
+	})
+
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	results := make([]*Document, 0, len(candidates))
+	for _, doc := range candidates {
+		results = append(results, &idx.docs[doc])
+	}
+	return results
+}
+
+// suggestCandidates 返回标题命中全部输入词的文档
+func (idx *Index) suggestCandidates(tokens []string, fuzzy bool) []int {
+	var result map[int]bool
+	for i, token := range tokens {
+		var terms []string
+		if i == len(tokens)-1 {
+			terms = prefixTerms(idx.titleTerms, token, 0)
+		} else if _, ok := idx.titleDocs[token]; ok {
+			terms = append(terms, token)
+		}
+		if fuzzy {
+			terms = append(terms, fuzzyTerms(idx.titleTerms, token)...)
+		}
+
+		matched := make(map[int]bool)
+		for _, term := range terms {
+			for _, doc := range idx.titleDocs[term] {
+				if result == nil || result[doc] {
+					matched[doc] = true
+				}
+			}
+		}
+		result = matched
+		if len(result) == 0 {
+			return nil
+		}
+	}
+
+	docs := make([]int, 0, len(result))
+	for doc := range result {
+		docs = append(docs, doc)
+	}
+	return docs
+}
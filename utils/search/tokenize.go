@@ -0,0 +1,130 @@
+package search
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// trailingArticle MovieLens 将冠词后置的标题，如 "Matrix, The"、"Cité des enfants perdus, La"
+var trailingArticle = regexp.MustCompile(`^(.*), (The|A|An|Les|Le|La|L'|Il|Lo|Das|Der|Die|Den|Det|El|Los|Las|Una|Un)$`)
+
+// NormalizeTitle 规范化 MovieLens 标题：去掉结尾的年份，并把后置冠词移回开头
+// 例如 "Matrix, The (1999)" -> "The Matrix"，别名括号中的标题同样处理
+func NormalizeTitle(title string) string {
+	title = strings.TrimSpace(title)
+	if _, rest, ok := splitYear(title); ok {
+		title = rest
+	}
+
+	// 主标题与括号中的别名分别处理
+	var parts []string
+	for {
+		open := strings.Index(title, " (")
+		if open < 0 || !strings.HasSuffix(title, ")") {
+			break
+		}
+		parts = append(parts, title[:open])
+		title = title[open+2 : len(title)-1]
+	}
+	parts = append(parts, title)
+
+	for i, part := range parts {
+		parts[i] = moveArticle(strings.TrimSpace(part))
+	}
+
+	normalized := parts[0]
+	for _, alias := range parts[1:] {
+		normalized += " (" + alias + ")"
+	}
+	return normalized
+}
+
+// moveArticle 把 "Matrix, The" 转为 "The Matrix"
+func moveArticle(title string) string {
+	match := trailingArticle.FindStringSubmatch(title)
+	if match == nil {
+		return title
+	}
+	if strings.HasSuffix(match[2], "'") {
+		return match[2] + match[1]
+	}
+	return match[2] + " " + match[1]
+}
+
+// splitYear 拆出标题结尾的 "(1995)"，返回年份和去掉年份后的标题
+func splitYear(title string) (int, string, bool) {
+	if !strings.HasSuffix(title, ")") {
+		return 0, title, false
+	}
+	open := strings.LastIndex(title, "(")
+	if open < 0 {
+		return 0, title, false
+	}
+	year, err := strconv.Atoi(strings.TrimSpace(title[open+1 : len(title)-1]))
+	if err != nil || year < 1800 || year > 3000 {
+		return 0, title, false
+	}
+	return year, strings.TrimSpace(title[:open]), true
+}
+
+// Tokenize 将文本拆分为小写词项，撇号被去掉（"Schindler's" -> "schindlers"），其余非字母数字字符作为分隔
+// 中日韩文字没有空格分词，按字切分为单字和相邻双字（"肖申克" -> 肖 肖申 申 申克 克）
+func Tokenize(text string) []string {
+	text = strings.NewReplacer("'", "", "’", "").Replace(strings.ToLower(text))
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := make([]string, 0, len(fields))
+	for _, field := range fields {
+		tokens = appendFieldTokens(tokens, field)
+	}
+	return tokens
+}
+
+// appendFieldTokens 将一个不含分隔符的片段拆为词项，连续的非中日韩字符作为一个词项，中日韩字符生成单字和双字
+func appendFieldTokens(tokens []string, field string) []string {
+	runes := []rune(field)
+	start := 0
+	for start < len(runes) {
+		end := start
+		if !isCJK(runes[start]) {
+			for end < len(runes) && !isCJK(runes[end]) {
+				end++
+			}
+			tokens = append(tokens, string(runes[start:end]))
+			start = end
+			continue
+		}
+
+		for end < len(runes) && isCJK(runes[end]) {
+			end++
+		}
+		for i := start; i < end; i++ {
+			tokens = append(tokens, string(runes[i]))
+			if i+1 < end {
+				tokens = append(tokens, string(runes[i:i+2]))
+			}
+		}
+		start = end
+	}
+	return tokens
+}
+
+// isCJK 判断是否为中日韩文字
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
+
+// containsHan 判断文本中是否包含汉字
+func containsHan(text string) bool {
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
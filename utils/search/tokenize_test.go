@@ -0,0 +1,43 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Toy Story (1995)", "Toy Story"},
+		{"Matrix, The (1999)", "The Matrix"},
+		{"City of Lost Children, The (Cité des enfants perdus, La) (1995)", "The City of Lost Children (La Cité des enfants perdus)"},
+		{"Homme d'Amérique, L' (2001)", "L'Homme d'Amérique"},
+		{"Blade Runner", "Blade Runner"},
+		{"2001: A Space Odyssey (1968)", "2001: A Space Odyssey"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeTitle(tt.title); got != tt.want {
+			t.Errorf("NormalizeTitle(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Schindler's List", []string{"schindlers", "list"}},
+		{"Star Wars: Episode IV", []string{"star", "wars", "episode", "iv"}},
+		{"肖申克", []string{"肖", "肖申", "申", "申克", "克"}},
+		{"WALL·E 机器人", []string{"wall", "e", "机", "机器", "器", "器人", "人"}},
+		{"  --  ", []string{}},
+	}
+	for _, tt := range tests {
+		if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package utils
+
+import (
+	"gohbase/utils/similar"
+	"time"
+)
+
+// SimilarEngine 对外暴露的全局相似电影引擎
+var SimilarEngine *similar.Engine
+
+// InitSimilar 初始化相似电影，基于搜索索引中的电影快照在后台计算首个结果后按 interval 重算
+// 需在 InitSearchEngine 之后调用；计算需要两两比较同类型的电影，不阻塞服务启动，计算完成前相似电影接口返回503
+func InitSimilar(neighbors int, ratingBoost float64, interval time.Duration) {
+	similar.InitEngine(loadIndexedDocuments, neighbors, ratingBoost, interval)
+	SimilarEngine = similar.Default
+	SimilarEngine.Start()
+}
@@ -0,0 +1,41 @@
+package similar
+
+import (
+	"context"
+	"gohbase/utils/refresher"
+	"gohbase/utils/search"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Source 读取参与计算的全部电影文档
+type Source func(ctx context.Context) ([]*search.Document, error)
+
+// Engine 持有当前的相似电影快照，并按固定间隔在后台重新计算
+type Engine struct {
+	*refresher.Refresher[Snapshot]
+}
+
+// NewEngine 创建相似电影引擎，调用 Start 后在后台计算首个快照，也可先调用 Refresh 同步计算
+func NewEngine(source Source, neighbors int, ratingBoost float64, interval time.Duration) *Engine {
+	build := func(ctx context.Context) (*Snapshot, error) {
+		start := time.Now()
+		docs, err := source(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		snapshot := Build(docs, neighbors, ratingBoost)
+		logrus.Infof("相似电影计算完成 [电影数: %d, 特征数: %d, 每部保留: %d, 耗时: %s]",
+			snapshot.Len(), snapshot.features, snapshot.Neighbors, time.Since(start).Round(time.Millisecond))
+		return snapshot, nil
+	}
+
+	return &Engine{Refresher: refresher.New("计算相似电影", build, interval)}
+}
+
+// Snapshot 返回当前相似电影快照，尚未计算时返回 nil
+func (e *Engine) Snapshot() *Snapshot {
+	return e.Value()
+}
@@ -0,0 +1,13 @@
+package similar
+
+import (
+	"time"
+)
+
+// Default 全局相似电影引擎实例
+var Default *Engine
+
+// InitEngine 初始化全局相似电影引擎
+func InitEngine(source Source, neighbors int, ratingBoost float64, interval time.Duration) {
+	Default = NewEngine(source, neighbors, ratingBoost, interval)
+}
@@ -0,0 +1,173 @@
+package similar
+
+import (
+	"gohbase/utils/search"
+	"gohbase/utils/topk"
+	"math"
+	"strings"
+	"time"
+)
+
+// noGenres 没有类型的电影在 movie:genres 中的占位值，不作为特征
+const noGenres = "(no genres listed)"
+
+// maxRatingGap 两部电影平均分的最大差距（0.5~5.0）
+const maxRatingGap = 4.5
+
+// Neighbor 一部相似电影，Score 为余弦相似度（按评分接近程度加权后）
+type Neighbor struct {
+	Document *search.Document
+	Score    float64
+}
+
+// Snapshot 某一时刻预先计算的相似电影，每部电影最多保留 Neighbors 部
+type Snapshot struct {
+	Neighbors   int
+	RatingBoost float64
+	BuiltAt     time.Time
+	neighbors   map[string][]Neighbor // 键为 movieId，按相似度降序，没有相似电影时为空
+	features    int                   // 特征（类型与标签）总数
+}
+
+// feature 电影特征向量中的一项
+type feature struct {
+	doc    int
+	weight float64
+}
+
+// Build 计算每部电影的相似电影
+// 每部电影的类型和用户标签（不区分大小写）构成特征，权重为 TF-IDF（同一特征在一部电影中只出现一次，即 IDF），
+// 向量归一化后以余弦相似度衡量相似程度；ratingBoost 大于0时，两部电影都有评分的，相似度再乘以 1 + ratingBoost·(1 - 平均分差距/4.5)
+func Build(docs []*search.Document, neighbors int, ratingBoost float64) *Snapshot {
+	snapshot := &Snapshot{
+		Neighbors:   neighbors,
+		RatingBoost: ratingBoost,
+		BuiltAt:     time.Now(),
+		neighbors:   make(map[string][]Neighbor, len(docs)),
+	}
+
+	// 统计每个特征出现在多少部电影中
+	docFeatures := make([][]string, len(docs))
+	df := make(map[string]int)
+	for i, doc := range docs {
+		docFeatures[i] = documentFeatures(doc)
+		for _, name := range docFeatures[i] {
+			df[name]++
+		}
+	}
+	snapshot.features = len(df)
+
+	// 平滑 IDF：ln((1+N)/(1+df)) + 1，出现在所有电影中的特征仍有少量权重
+	n := float64(len(docs))
+	postings := make(map[string][]feature, len(df))
+	weights := make([][]float64, len(docs))
+	for i, names := range docFeatures {
+		var norm float64
+		weights[i] = make([]float64, len(names))
+		for j, name := range names {
+			weights[i][j] = math.Log((1+n)/(1+float64(df[name]))) + 1
+			norm += weights[i][j] * weights[i][j]
+		}
+		norm = math.Sqrt(norm)
+		for j, name := range names {
+			weights[i][j] /= norm
+			postings[name] = append(postings[name], feature{doc: i, weight: weights[i][j]})
+		}
+	}
+
+	// 通过倒排表只累加有共同特征的电影，scores 和 touched 在各电影间复用
+	scores := make([]float64, len(docs))
+	var touched []int
+	for i, names := range docFeatures {
+		touched = touched[:0]
+		for j, name := range names {
+			weight := weights[i][j]
+			for _, other := range postings[name] {
+				if other.doc == i {
+					continue
+				}
+				if scores[other.doc] == 0 {
+					touched = append(touched, other.doc)
+				}
+				scores[other.doc] += weight * other.weight
+			}
+		}
+		if len(touched) == 0 {
+			snapshot.neighbors[docs[i].MovieID] = []Neighbor{}
+			continue
+		}
+
+		// 只保留最相似的 neighbors 部，堆顶为已保留中最不相似的一部
+		top := topk.New(neighbors, ranksBefore)
+		for _, j := range touched {
+			top.Push(Neighbor{
+				Document: docs[j],
+				Score:    scores[j] * ratingFactor(docs[i], docs[j], ratingBoost),
+			})
+			scores[j] = 0
+		}
+		snapshot.neighbors[docs[i].MovieID] = top.Sorted()
+	}
+
+	return snapshot
+}
+
+// ranksBefore 判断 a 是否排在 b 之前：相似度高的在前，相同时评分人数多的在前，再按 movieId 保证顺序稳定
+func ranksBefore(a, b Neighbor) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	if a.Document.RatingCount != b.Document.RatingCount {
+		return a.Document.RatingCount > b.Document.RatingCount
+	}
+	return search.CompareDocuments(a.Document, b.Document, "", false) < 0
+}
+
+// documentFeatures 返回电影的特征名，类型与标签分别加前缀以免同名时混淆，结果已去重
+func documentFeatures(doc *search.Document) []string {
+	seen := make(map[string]bool, len(doc.Genres)+len(doc.Tags))
+	var names []string
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	for _, genre := range doc.Genres {
+		genre = strings.ToLower(strings.TrimSpace(genre))
+		if genre != "" && genre != noGenres {
+			add("genre:" + genre)
+		}
+	}
+	for _, tag := range doc.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" {
+			add("tag:" + tag)
+		}
+	}
+	return names
+}
+
+// ratingFactor 按两部电影平均分的接近程度计算相似度的加权系数，任一部没有评分时为1
+func ratingFactor(a, b *search.Document, boost float64) float64 {
+	if boost <= 0 || a.RatingCount == 0 || b.RatingCount == 0 {
+		return 1
+	}
+	gap := math.Min(math.Abs(a.AvgRating-b.AvgRating), maxRatingGap)
+	return 1 + boost*(1-gap/maxRatingGap)
+}
+
+// Similar 返回与 movieID 最相似的 limit 部电影，ok 为 false 表示该电影不在快照中
+func (s *Snapshot) Similar(movieID string, limit int) (neighbors []Neighbor, ok bool) {
+	neighbors, ok = s.neighbors[movieID]
+	if len(neighbors) > limit {
+		neighbors = neighbors[:limit]
+	}
+	return neighbors, ok
+}
+
+// Len 返回参与计算的电影数量
+func (s *Snapshot) Len() int {
+	return len(s.neighbors)
+}
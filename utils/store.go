@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"fmt"
+	"gohbase/config"
+	"gohbase/utils/hbase"
+	"gohbase/utils/memstore"
+	"gohbase/utils/store"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Store 对外暴露的全局数据存储实例
+var Store store.MovieStore
+
+// InitStore 根据配置初始化数据存储后端
+func InitStore(cfg *config.Config) error {
+	switch cfg.Store.Backend {
+	case config.StoreBackendHBase:
+		if err := hbase.InitHBase(&cfg.HBase); err != nil {
+			return err
+		}
+		Store = hbase.NewStore()
+	case config.StoreBackendMemory:
+		memStore := memstore.New()
+		if cfg.Store.FixturesPath != "" {
+			if err := memStore.LoadFixtures(cfg.Store.FixturesPath); err != nil {
+				return err
+			}
+		}
+		Store = memStore
+	default:
+		return fmt.Errorf("未知的存储后端: %s", cfg.Store.Backend)
+	}
+
+	logrus.Infof("数据存储初始化成功 [后端: %s]", cfg.Store.Backend)
+	return nil
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// AliasFamily 本地化标题所在的列族，列名为语言代码，如 alias:zh
+const AliasFamily = "alias"
+
+// LocaleZh 中文标题的列名
+const LocaleZh = "zh"
+
+// Alias 电影的一个本地化标题
+type Alias struct {
+	MovieID string
+	Title   string
+}
+
+// ParseAliasesCSV 读取 movieId,标题 两列的CSV，首行不是数字ID时视为表头跳过，空标题的行被忽略
+func ParseAliasesCSV(r io.Reader) ([]Alias, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var aliases []Alias
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return aliases, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析CSV第 %d 行失败: %w", line, err)
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("CSV第 %d 行应为 movieId,标题 两列", line)
+		}
+
+		// 去掉表格软件导出CSV时可能带有的BOM
+		movieID := strings.TrimPrefix(strings.TrimSpace(record[0]), "\ufeff")
+		if _, err := strconv.Atoi(movieID); err != nil {
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("CSV第 %d 行的 movieId 无效: %q", line, record[0])
+		}
+
+		if title := strings.TrimSpace(record[1]); title != "" {
+			aliases = append(aliases, Alias{MovieID: movieID, Title: title})
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package store
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 二级索引表，行键格式为 类别#值#movieId，值统一转为小写
+// 例如 genre#comedy#1、tag#pixar#1、year#1995#1（补齐四位）、rating#392#1（平均分×100，补齐三位）、title#toy#1
+// 按用户查询标签的条目以 userId 为值：tagged#42#1 表示用户42给电影1打过标签（按用户查询评分使用 userratings 表）
+// 另有 movie#movieId 反向行记录该电影当前的全部索引行键（列名即索引行键），用于写入时清理旧条目
+const (
+	IndexTable  = "movieindex"
+	IndexFamily = "idx"
+
+	IndexGenre  = "genre"
+	IndexTag    = "tag"
+	IndexYear   = "year"
+	IndexRating = "rating"
+	IndexTitle  = "title"
+
+	IndexUserTag = "tagged"
+
+	indexSeparator = "#"
+	reversePrefix  = "movie#"
+)
+
+// IndexFamilies 计算索引所需的电影表列族，中文标题（alias 列族）同样拆分为 title 条目
+var IndexFamilies = []string{"movie", AliasFamily, "tag", StatsFamily}
+
+// escapeIndexValue 值转为小写并转义分隔符，避免值中的 # 与行键分隔符混淆
+func escapeIndexValue(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	value = strings.ReplaceAll(value, "%", "%25")
+	return strings.ReplaceAll(value, indexSeparator, "%23")
+}
+
+// IndexKey 构造索引行键
+func IndexKey(kind, value, movieID string) string {
+	return IndexPrefix(kind, value) + movieID
+}
+
+// IndexPrefix 返回某个索引值下所有电影的行键前缀
+func IndexPrefix(kind, value string) string {
+	return kind + indexSeparator + escapeIndexValue(value) + indexSeparator
+}
+
+// IndexMovieID 从索引行键中取出 movieId
+func IndexMovieID(rowKey string) string {
+	return rowKey[strings.LastIndex(rowKey, indexSeparator)+1:]
+}
+
+// PrefixEnd 返回前缀扫描的结束行键（不含），即所有以 prefix 开头的行键都小于它
+func PrefixEnd(prefix string) string {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return string(end[:i+1])
+		}
+	}
+	return ""
+}
+
+// ReverseIndexKey 电影反向索引行的行键
+func ReverseIndexKey(movieID string) string {
+	return reversePrefix + movieID
+}
+
+// ReverseIndexRange 全部反向索引行的行键范围，每部已建立索引的电影一行，可用于统计电影数量
+func ReverseIndexRange() (string, string) {
+	return reversePrefix, PrefixEnd(reversePrefix)
+}
+
+// RatingBucket 平均分对应的索引值，精确到0.01并补齐为三位，保证字典序与数值顺序一致
+func RatingBucket(avg float64) string {
+	return fmt.Sprintf("%03d", int(math.Round(avg*100)))
+}
+
+// RatingRange 返回平均分在 [minRating, maxRating] 内的索引扫描范围 [start, stop)
+func RatingRange(minRating, maxRating float64) (string, string) {
+	start := IndexRating + indexSeparator + RatingBucket(minRating) + indexSeparator
+	stop := PrefixEnd(IndexRating + indexSeparator + RatingBucket(maxRating) + indexSeparator)
+	return start, stop
+}
+
+// YearValue 年份对应的索引值，补齐为四位，保证字典序与数值顺序一致
+func YearValue(year int) string {
+	return fmt.Sprintf("%04d", year)
+}
+
+// YearRange 返回年份在 [yearFrom, yearTo] 内的索引扫描范围 [start, stop)
+func YearRange(yearFrom, yearTo int) (string, string) {
+	start := IndexYear + indexSeparator + YearValue(yearFrom) + indexSeparator
+	stop := PrefixEnd(IndexYear + indexSeparator + YearValue(yearTo) + indexSeparator)
+	return start, stop
+}
+
+// ParseYear 从 "Toy Story (1995)" 格式的标题中提取年份，无法识别时返回0
+func ParseYear(title string) int {
+	title = strings.TrimSpace(title)
+	if !strings.HasSuffix(title, ")") {
+		return 0
+	}
+	start := strings.LastIndex(title, "(")
+	if start < 0 {
+		return 0
+	}
+	year, err := strconv.Atoi(title[start+1 : len(title)-1])
+	if err != nil {
+		return 0
+	}
+	return year
+}
+
+// TitleTokens 将标题拆分为小写单词，忽略结尾的年份
+func TitleTokens(title string) []string {
+	if ParseYear(title) > 0 {
+		title = title[:strings.LastIndex(title, "(")]
+	}
+
+	return strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// MovieIndexKeys 根据电影数据（需包含 IndexFamilies 中的列族）计算该电影应有的全部索引行键，已去重并排序
+// 非电影行没有索引，也不会登记反向行，因此不计入电影总数
+func MovieIndexKeys(movieID string, data map[string]map[string][]byte) []string {
+	if !IsMovieRow(Row{Key: movieID, Data: data}) {
+		return nil
+	}
+
+	keys := make(map[string]bool)
+
+	title := string(data["movie"]["title"])
+	for _, token := range TitleTokens(title) {
+		keys[IndexKey(IndexTitle, token, movieID)] = true
+	}
+	for _, token := range TitleTokens(string(data[AliasFamily][LocaleZh])) {
+		keys[IndexKey(IndexTitle, token, movieID)] = true
+	}
+	if year := ParseYear(title); year > 0 {
+		keys[IndexKey(IndexYear, YearValue(year), movieID)] = true
+	}
+
+	if genres := string(data["movie"]["genres"]); genres != "" {
+		for _, genre := range strings.Split(genres, "|") {
+			if genre = strings.TrimSpace(genre); genre != "" {
+				keys[IndexKey(IndexGenre, genre, movieID)] = true
+			}
+		}
+	}
+
+	for qualifier, value := range data["tag"] {
+		if userID, ok := strings.CutPrefix(qualifier, "tag:"); ok && strings.TrimSpace(string(value)) != "" {
+			keys[IndexKey(IndexTag, string(value), movieID)] = true
+			keys[IndexKey(IndexUserTag, userID, movieID)] = true
+		}
+	}
+
+	if aggregate := ParseRatingAggregate(data[StatsFamily]); aggregate != nil && aggregate.Count > 0 {
+		keys[IndexKey(IndexRating, RatingBucket(aggregate.Avg()), movieID)] = true
+	}
+
+	result := make([]string, 0, len(keys))
+	for key := range keys {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// DiffIndexKeys 比较电影当前与应有的索引行键，返回需要新增和删除的行键
+func DiffIndexKeys(current, desired []string) (added, removed []string) {
+	currentSet := make(map[string]bool, len(current))
+	for _, key := range current {
+		currentSet[key] = true
+	}
+	desiredSet := make(map[string]bool, len(desired))
+	for _, key := range desired {
+		desiredSet[key] = true
+		if !currentSet[key] {
+			added = append(added, key)
+		}
+	}
+	for _, key := range current {
+		if !desiredSet[key] {
+			removed = append(removed, key)
+		}
+	}
+	return added, removed
+}
+
+// IntersectIDs 求多个有序去重ID列表的交集，结果按字典序排列
+func IntersectIDs(lists [][]string) []string {
+	if len(lists) == 0 {
+		return nil
+	}
+
+	counts := make(map[string]int)
+	for _, list := range lists {
+		for _, id := range list {
+			counts[id]++
+		}
+	}
+
+	var result []string
+	for id, count := range counts {
+		if count == len(lists) {
+			result = append(result, id)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// UnionIDs 合并ID列表并去重，结果按字典序排列
+func UnionIDs(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, list := range lists {
+		for _, id := range list {
+			if !seen[id] {
+				seen[id] = true
+				result = append(result, id)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// IndexScanner 扫描索引表 [start, stop) 范围内的行，返回去重后的 movieId
+type IndexScanner func(start, stop string) ([]string, error)
+
+// SearchIndex 通过索引搜索电影：查询中的每个单词都须是标题中某个单词的前缀，或整个查询是某个类型的前缀
+func SearchIndex(query string, scan IndexScanner) ([]string, error) {
+	var titleMatches [][]string
+	for _, token := range TitleTokens(query) {
+		prefix := IndexTitle + indexSeparator + escapeIndexValue(token)
+		ids, err := scan(prefix, PrefixEnd(prefix))
+		if err != nil {
+			return nil, err
+		}
+		titleMatches = append(titleMatches, ids)
+	}
+
+	var genreMatches []string
+	if query = strings.TrimSpace(query); query != "" {
+		prefix := IndexGenre + indexSeparator + escapeIndexValue(query)
+		ids, err := scan(prefix, PrefixEnd(prefix))
+		if err != nil {
+			return nil, err
+		}
+		genreMatches = ids
+	}
+
+	return UnionIDs(IntersectIDs(titleMatches), genreMatches), nil
+}
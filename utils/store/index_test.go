@@ -0,0 +1,238 @@
+package store
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIndexKey(t *testing.T) {
+	tests := []struct {
+		kind, value, movieID string
+		want                 string
+	}{
+		{IndexGenre, "Comedy", "1", "genre#comedy#1"},
+		{IndexTag, "  Pixar ", "1", "tag#pixar#1"},
+		{IndexTag, "c#", "2", "tag#c%23#2"},
+		{IndexTag, "100%", "3", "tag#100%25#3"},
+		{IndexYear, YearValue(995), "4", "year#0995#4"},
+		{IndexRating, RatingBucket(3.92), "5", "rating#392#5"},
+		{IndexRating, RatingBucket(0.5), "6", "rating#050#6"},
+	}
+	for _, tt := range tests {
+		got := IndexKey(tt.kind, tt.value, tt.movieID)
+		if got != tt.want {
+			t.Errorf("IndexKey(%q, %q, %q) = %q, want %q", tt.kind, tt.value, tt.movieID, got, tt.want)
+		}
+		if id := IndexMovieID(got); id != tt.movieID {
+			t.Errorf("IndexMovieID(%q) = %q, want %q", got, id, tt.movieID)
+		}
+	}
+}
+
+func TestPrefixEnd(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{"genre#comedy#", "genre#comedy$"},
+		{"a", "b"},
+		{"a\xff", "b"},
+		{"\xff\xff", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := PrefixEnd(tt.prefix); got != tt.want {
+			t.Errorf("PrefixEnd(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+}
+
+// inRange 判断行键是否在 [start, stop) 范围内，stop 为空表示不设上限
+func inRange(key, start, stop string) bool {
+	return key >= start && (stop == "" || key < stop)
+}
+
+func TestRatingRange(t *testing.T) {
+	tests := []struct {
+		min, max float64
+		avg      float64
+		want     bool
+	}{
+		{3, 4, 3, true},
+		{3, 4, 4, true},
+		{3, 4, 3.5, true},
+		{3, 4, 2.99, false},
+		{3, 4, 4.01, false},
+		{0.5, 5, 5, true},
+		{4.25, 4.25, 4.25, true},
+	}
+	for _, tt := range tests {
+		start, stop := RatingRange(tt.min, tt.max)
+		key := IndexKey(IndexRating, RatingBucket(tt.avg), "42")
+		if got := inRange(key, start, stop); got != tt.want {
+			t.Errorf("RatingRange(%v, %v) contains avg %v = %v, want %v", tt.min, tt.max, tt.avg, got, tt.want)
+		}
+	}
+}
+
+func TestYearRange(t *testing.T) {
+	tests := []struct {
+		from, to int
+		year     int
+		want     bool
+	}{
+		{1990, 1999, 1990, true},
+		{1990, 1999, 1999, true},
+		{1990, 1999, 2000, false},
+		{1990, 1999, 989, false},
+		{0, 9999, 1995, true},
+	}
+	for _, tt := range tests {
+		start, stop := YearRange(tt.from, tt.to)
+		key := IndexKey(IndexYear, YearValue(tt.year), "42")
+		if got := inRange(key, start, stop); got != tt.want {
+			t.Errorf("YearRange(%d, %d) contains %d = %v, want %v", tt.from, tt.to, tt.year, got, tt.want)
+		}
+	}
+}
+
+func TestReverseIndexRange(t *testing.T) {
+	start, stop := ReverseIndexRange()
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{ReverseIndexKey("1"), true},
+		{ReverseIndexKey("193609"), true},
+		{IndexKey(IndexGenre, "comedy", "1"), false},
+		{IndexKey(IndexTitle, "movie", "1"), false},
+	}
+	for _, tt := range tests {
+		if got := inRange(tt.key, start, stop); got != tt.want {
+			t.Errorf("ReverseIndexRange contains %q = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestMovieIndexKeys(t *testing.T) {
+	aggregate := ComputeRatingAggregate([]UserRating{{Rating: 4}, {Rating: 3.5}})
+	tests := []struct {
+		name    string
+		movieID string
+		data    map[string]map[string][]byte
+		want    []string
+	}{
+		{
+			name:    "完整电影",
+			movieID: "1",
+			data: map[string]map[string][]byte{
+				"movie":     {"title": []byte("Toy Story (1995)"), "genres": []byte("Animation|Comedy")},
+				AliasFamily: {LocaleZh: []byte("玩具总动员")},
+				"tag":       {"tag:7": []byte("pixar"), "tag:8": []byte(" ")},
+				StatsFamily: EncodeRatingAggregate(aggregate),
+			},
+			want: []string{
+				"genre#animation#1", "genre#comedy#1", "rating#375#1", "tag#pixar#1", "tagged#7#1",
+				"title#story#1", "title#toy#1", "title#玩具总动员#1", "year#1995#1",
+			},
+		},
+		{
+			name:    "旧版评分行",
+			movieID: "1_7",
+			data:    map[string]map[string][]byte{"rating": {"rating": []byte("4")}},
+			want:    nil,
+		},
+		{
+			name:    "只有统计列的残留行",
+			movieID: "2",
+			data:    map[string]map[string][]byte{StatsFamily: EncodeRatingAggregate(aggregate)},
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MovieIndexKeys(tt.movieID, tt.data); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MovieIndexKeys = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffIndexKeys(t *testing.T) {
+	added, removed := DiffIndexKeys([]string{"a", "b", "c"}, []string{"b", "c", "d"})
+	if !reflect.DeepEqual(added, []string{"d"}) || !reflect.DeepEqual(removed, []string{"a"}) {
+		t.Errorf("DiffIndexKeys = %q, %q, want [d], [a]", added, removed)
+	}
+}
+
+func TestIntersectAndUnionIDs(t *testing.T) {
+	lists := [][]string{{"1", "2", "3"}, {"2", "3", "4"}, {"3", "2"}}
+	if got := IntersectIDs(lists); !reflect.DeepEqual(got, []string{"2", "3"}) {
+		t.Errorf("IntersectIDs = %q, want [2 3]", got)
+	}
+	if got := IntersectIDs(nil); got != nil {
+		t.Errorf("IntersectIDs(nil) = %q, want nil", got)
+	}
+	if got := UnionIDs(lists...); !reflect.DeepEqual(got, []string{"1", "2", "3", "4"}) {
+		t.Errorf("UnionIDs = %q, want [1 2 3 4]", got)
+	}
+}
+
+func TestSearchIndex(t *testing.T) {
+	keys := []string{
+		"genre#comedy#1", "genre#drama#2", "genre#comedy#3",
+		"title#toy#1", "title#story#1", "title#toys#3", "title#stories#3", "title#river#2",
+	}
+	scan := func(start, stop string) ([]string, error) {
+		var ids []string
+		for _, key := range keys {
+			if inRange(key, start, stop) {
+				ids = append(ids, IndexMovieID(key))
+			}
+		}
+		return UnionIDs(ids), nil
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"toy", []string{"1", "3"}},
+		{"toy stor", []string{"1", "3"}},
+		{"toy river", nil},
+		{"com", []string{"1", "3"}},
+		{"dra", []string{"2"}},
+		{"   ", nil},
+	}
+	for _, tt := range tests {
+		got, err := SearchIndex(tt.query, scan)
+		if err != nil {
+			t.Fatalf("SearchIndex(%q) error: %v", tt.query, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SearchIndex(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseYearAndTitleTokens(t *testing.T) {
+	tests := []struct {
+		title  string
+		year   int
+		tokens string
+	}{
+		{"Toy Story (1995)", 1995, "toy story"},
+		{"Schindler's List (1993)", 1993, "schindler s list"},
+		{"Babylon 5", 0, "babylon 5"},
+		{"Movie (abc)", 0, "movie abc"},
+	}
+	for _, tt := range tests {
+		if got := ParseYear(tt.title); got != tt.year {
+			t.Errorf("ParseYear(%q) = %d, want %d", tt.title, got, tt.year)
+		}
+		if got := strings.Join(TitleTokens(tt.title), " "); got != tt.tokens {
+			t.Errorf("TitleTokens(%q) = %q, want %q", tt.title, got, tt.tokens)
+		}
+	}
+}
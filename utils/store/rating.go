@@ -0,0 +1,236 @@
+package store
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// StatsFamily 评分聚合数据所在的列族
+const StatsFamily = "stats"
+
+// HistogramBuckets 半星制评分的直方图桶数（0.5 ~ 5.0）
+const HistogramBuckets = 10
+
+// UserRating 单个用户的评分
+type UserRating struct {
+	UserID    string
+	Rating    float64
+	Timestamp int64
+}
+
+// ParseRatings 解析 rating 列族中 rating:{userId} / timestamp:{userId} 格式的评分，按用户ID排序
+func ParseRatings(ratingData map[string][]byte) []UserRating {
+	ratings := make([]UserRating, 0)
+
+	for column, value := range ratingData {
+		if !strings.HasPrefix(column, "rating:") {
+			continue
+		}
+
+		rating, err := strconv.ParseFloat(string(value), 64)
+		if err != nil {
+			continue
+		}
+
+		userID := strings.TrimPrefix(column, "rating:")
+		var timestamp int64
+		if timestampValue, ok := ratingData["timestamp:"+userID]; ok {
+			timestamp, _ = strconv.ParseInt(string(timestampValue), 10, 64)
+		}
+
+		ratings = append(ratings, UserRating{UserID: userID, Rating: rating, Timestamp: timestamp})
+	}
+
+	sort.Slice(ratings, func(i, j int) bool {
+		return ratings[i].UserID < ratings[j].UserID
+	})
+
+	return ratings
+}
+
+// RatingRowSeparator 旧版按行保存的评分中 movieId 与 userId 的分隔符
+// 旧版评分行的行键形如 1_42（电影1、用户42），评分与时间分别在 rating:rating 和 rating:timestamp 列
+const RatingRowSeparator = "_"
+
+// IsRatingRowKey 判断行键是否为旧版评分行（电影行键不含分隔符）
+func IsRatingRowKey(rowKey string) bool {
+	return strings.Contains(rowKey, RatingRowSeparator)
+}
+
+// RatingRowPrefix 某部电影旧版评分行的行键前缀
+func RatingRowPrefix(movieID string) string {
+	return movieID + RatingRowSeparator
+}
+
+// ParseRatingRow 解析旧版评分行，userId 取自行键；行键不属于该电影或评分无法解析时返回 false
+func ParseRatingRow(movieID string, row Row) (UserRating, bool) {
+	userID := strings.TrimPrefix(row.Key, RatingRowPrefix(movieID))
+	if userID == row.Key || userID == "" {
+		return UserRating{}, false
+	}
+
+	ratingData := row.Data["rating"]
+	rating, err := strconv.ParseFloat(string(ratingData["rating"]), 64)
+	if err != nil {
+		return UserRating{}, false
+	}
+	timestamp, _ := strconv.ParseInt(string(ratingData["timestamp"]), 10, 64)
+
+	return UserRating{UserID: userID, Rating: rating, Timestamp: timestamp}, true
+}
+
+// MergeRatings 合并电影行中的评分与旧版评分行，同一用户以电影行中的评分为准，结果按用户ID排序
+func MergeRatings(columnRatings []UserRating, rows []Row, movieID string) []UserRating {
+	seen := make(map[string]bool, len(columnRatings))
+	ratings := make([]UserRating, 0, len(columnRatings)+len(rows))
+	for _, rating := range columnRatings {
+		seen[rating.UserID] = true
+		ratings = append(ratings, rating)
+	}
+	for _, row := range rows {
+		if rating, ok := ParseRatingRow(movieID, row); ok && !seen[rating.UserID] {
+			seen[rating.UserID] = true
+			ratings = append(ratings, rating)
+		}
+	}
+
+	sort.Slice(ratings, func(i, j int) bool {
+		return ratings[i].UserID < ratings[j].UserID
+	})
+	return ratings
+}
+
+// RatingAggregate 电影评分聚合数据，保存在 stats 列族中
+//
+// 列布局：
+//   - stats:count        评分数量（8字节大端整数，可被 Increment 原子更新）
+//   - stats:sum          评分总和，以半星为单位（评分×2）
+//   - stats:hist:{评分}  各半星档位的评分数量，如 stats:hist:4.5
+//   - stats:min/max      最低/最高评分（字符串，通过 CheckAndPut 更新）
+type RatingAggregate struct {
+	Count     int64
+	Sum       float64
+	Min       float64
+	Max       float64
+	Histogram [HistogramBuckets]int64
+}
+
+// Avg 平均评分
+func (a *RatingAggregate) Avg() float64 {
+	if a == nil || a.Count == 0 {
+		return 0
+	}
+	return a.Sum / float64(a.Count)
+}
+
+// Add 向聚合中加入一个评分
+func (a *RatingAggregate) Add(rating float64) {
+	a.Count++
+	a.Sum += rating
+	if index, ok := HistogramIndex(rating); ok {
+		a.Histogram[index]++
+	}
+	a.Min, a.Max = a.HistogramRange()
+}
+
+// HistogramRange 根据直方图计算最低和最高评分
+func (a *RatingAggregate) HistogramRange() (float64, float64) {
+	var min, max float64
+	for i := 0; i < HistogramBuckets; i++ {
+		if a.Histogram[i] > 0 {
+			min = HistogramValue(i)
+			break
+		}
+	}
+	for i := HistogramBuckets - 1; i >= 0; i-- {
+		if a.Histogram[i] > 0 {
+			max = HistogramValue(i)
+			break
+		}
+	}
+	return min, max
+}
+
+// HistogramIndex 返回评分所在的直方图桶下标
+func HistogramIndex(rating float64) (int, bool) {
+	index := int(math.Round(rating*2)) - 1
+	if index < 0 || index >= HistogramBuckets {
+		return 0, false
+	}
+	return index, true
+}
+
+// HistogramValue 返回直方图桶对应的评分
+func HistogramValue(index int) float64 {
+	return float64(index+1) / 2
+}
+
+// HistogramQualifier 返回评分对应的直方图列名
+func HistogramQualifier(rating float64) string {
+	return "hist:" + strconv.FormatFloat(rating, 'f', 1, 64)
+}
+
+// ComputeRatingAggregate 根据电影的全部评分（含旧版评分行）计算聚合数据
+func ComputeRatingAggregate(ratings []UserRating) *RatingAggregate {
+	aggregate := &RatingAggregate{}
+	for _, rating := range ratings {
+		aggregate.Add(rating.Rating)
+	}
+	return aggregate
+}
+
+// ParseRatingAggregate 从 stats 列族解析聚合数据，列族为空时返回 nil
+func ParseRatingAggregate(statsData map[string][]byte) *RatingAggregate {
+	if len(statsData) == 0 {
+		return nil
+	}
+
+	aggregate := &RatingAggregate{
+		Count: DecodeCounter(statsData["count"]),
+		Sum:   float64(DecodeCounter(statsData["sum"])) / 2,
+	}
+	for i := 0; i < HistogramBuckets; i++ {
+		aggregate.Histogram[i] = DecodeCounter(statsData[HistogramQualifier(HistogramValue(i))])
+	}
+	aggregate.Min, _ = strconv.ParseFloat(string(statsData["min"]), 64)
+	aggregate.Max, _ = strconv.ParseFloat(string(statsData["max"]), 64)
+
+	return aggregate
+}
+
+// EncodeRatingAggregate 将聚合数据编码为 stats 列族的列值
+func EncodeRatingAggregate(aggregate *RatingAggregate) map[string][]byte {
+	values := map[string][]byte{
+		"count": EncodeCounter(aggregate.Count),
+		"sum":   EncodeCounter(int64(math.Round(aggregate.Sum * 2))),
+		"min":   EncodeRatingValue(aggregate.Min),
+		"max":   EncodeRatingValue(aggregate.Max),
+	}
+	for i := 0; i < HistogramBuckets; i++ {
+		values[HistogramQualifier(HistogramValue(i))] = EncodeCounter(aggregate.Histogram[i])
+	}
+	return values
+}
+
+// EncodeRatingValue 将评分编码为一位小数的字符串
+func EncodeRatingValue(rating float64) []byte {
+	return []byte(strconv.FormatFloat(rating, 'f', 1, 64))
+}
+
+// EncodeCounter 将计数编码为HBase Increment使用的8字节大端整数
+func EncodeCounter(value int64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(value))
+	return buf
+}
+
+// DecodeCounter 解码8字节大端整数，长度不符时返回0
+func DecodeCounter(value []byte) int64 {
+	if len(value) != 8 {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(value))
+}
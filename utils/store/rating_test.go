@@ -0,0 +1,160 @@
+package store
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRatings(t *testing.T) {
+	data := map[string][]byte{
+		"rating:2":    []byte("4.5"),
+		"timestamp:2": []byte("1700000000"),
+		"rating:1":    []byte("3"),
+		"rating:3":    []byte("bad"),
+		"other":       []byte("x"),
+	}
+	want := []UserRating{
+		{UserID: "1", Rating: 3},
+		{UserID: "2", Rating: 4.5, Timestamp: 1700000000},
+	}
+	if got := ParseRatings(data); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseRatings = %+v, want %+v", got, want)
+	}
+}
+
+func TestIsRatingRowKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"1", false},
+		{"193609", false},
+		{"1_42", true},
+		{"1_", true},
+	}
+	for _, tt := range tests {
+		if got := IsRatingRowKey(tt.key); got != tt.want {
+			t.Errorf("IsRatingRowKey(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestIsMovieRow(t *testing.T) {
+	tests := []struct {
+		name string
+		row  Row
+		want bool
+	}{
+		{"电影", Row{Key: "1", Data: map[string]map[string][]byte{"movie": {"title": []byte("Toy Story (1995)")}}}, true},
+		{"旧版评分行", Row{Key: "1_42", Data: map[string]map[string][]byte{"rating": {"rating": []byte("4")}}}, false},
+		{"缺少 movie 列族", Row{Key: "2", Data: map[string]map[string][]byte{StatsFamily: {"count": EncodeCounter(1)}}}, false},
+		{"空行", Row{Key: "3"}, false},
+	}
+	for _, tt := range tests {
+		if got := IsMovieRow(tt.row); got != tt.want {
+			t.Errorf("IsMovieRow(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// ratingRow 构造旧版评分行
+func ratingRow(key, rating, timestamp string) Row {
+	return Row{Key: key, Data: map[string]map[string][]byte{
+		"rating": {"rating": []byte(rating), "timestamp": []byte(timestamp)},
+	}}
+}
+
+func TestParseRatingRow(t *testing.T) {
+	tests := []struct {
+		name    string
+		movieID string
+		row     Row
+		want    UserRating
+		ok      bool
+	}{
+		{"正常", "1", ratingRow("1_42", "4", "100"), UserRating{UserID: "42", Rating: 4, Timestamp: 100}, true},
+		{"其他电影", "1", ratingRow("10_42", "4", "100"), UserRating{}, false},
+		{"缺少用户", "1", ratingRow("1_", "4", "100"), UserRating{}, false},
+		{"评分无法解析", "1", ratingRow("1_42", "", "100"), UserRating{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseRatingRow(tt.movieID, tt.row)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseRatingRow(%s) = %+v, %v, want %+v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestMergeRatings(t *testing.T) {
+	columns := []UserRating{{UserID: "2", Rating: 5, Timestamp: 200}}
+	rows := []Row{
+		ratingRow("1_2", "1", "100"), // 同一用户以电影行中的评分为准
+		ratingRow("1_1", "3.5", "50"),
+		ratingRow("10_3", "4", "10"), // 不属于该电影
+	}
+	want := []UserRating{
+		{UserID: "1", Rating: 3.5, Timestamp: 50},
+		{UserID: "2", Rating: 5, Timestamp: 200},
+	}
+	if got := MergeRatings(columns, rows, "1"); !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeRatings = %+v, want %+v", got, want)
+	}
+}
+
+func TestRatingAggregate(t *testing.T) {
+	tests := []struct {
+		name     string
+		ratings  []float64
+		count    int64
+		avg      float64
+		min, max float64
+	}{
+		{"无评分", nil, 0, 0, 0, 0},
+		{"单个评分", []float64{4}, 1, 4, 4, 4},
+		{"多个评分", []float64{0.5, 3, 5, 3.5}, 4, 3, 0.5, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ratings []UserRating
+			for _, rating := range tt.ratings {
+				ratings = append(ratings, UserRating{Rating: rating})
+			}
+			aggregate := ComputeRatingAggregate(ratings)
+			if aggregate.Count != tt.count || aggregate.Avg() != tt.avg || aggregate.Min != tt.min || aggregate.Max != tt.max {
+				t.Errorf("aggregate = count %d avg %v min %v max %v, want %d %v %v %v",
+					aggregate.Count, aggregate.Avg(), aggregate.Min, aggregate.Max, tt.count, tt.avg, tt.min, tt.max)
+			}
+
+			// 编码后再解析应得到相同的聚合
+			if tt.count > 0 {
+				if decoded := ParseRatingAggregate(EncodeRatingAggregate(aggregate)); !reflect.DeepEqual(decoded, aggregate) {
+					t.Errorf("ParseRatingAggregate(EncodeRatingAggregate) = %+v, want %+v", decoded, aggregate)
+				}
+			}
+		})
+	}
+
+	if ParseRatingAggregate(nil) != nil {
+		t.Error("ParseRatingAggregate(nil) should be nil")
+	}
+}
+
+func TestHistogramIndex(t *testing.T) {
+	tests := []struct {
+		rating float64
+		index  int
+		ok     bool
+	}{
+		{0.5, 0, true},
+		{3, 5, true},
+		{5, 9, true},
+		{0, 0, false},
+		{5.5, 0, false},
+	}
+	for _, tt := range tests {
+		index, ok := HistogramIndex(tt.rating)
+		if index != tt.index || ok != tt.ok {
+			t.Errorf("HistogramIndex(%v) = %d, %v, want %d, %v", tt.rating, index, ok, tt.index, tt.ok)
+		}
+	}
+}
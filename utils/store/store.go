@@ -0,0 +1,109 @@
+package store
+
+import (
+	"context"
+)
+
+// Row 一行数据，Data 的结构为 列族 -> 列 -> 值
+type Row struct {
+	Key  string
+	Data map[string]map[string][]byte
+}
+
+// IsMovieRow 判断电影表中的一行是否为电影，旧版评分行和缺少 movie 列族的残留行都不是电影
+func IsMovieRow(row Row) bool {
+	return !IsRatingRowKey(row.Key) && len(row.Data["movie"]) > 0
+}
+
+// MovieStore 电影数据存储接口，屏蔽HBase与内存等不同后端的差异
+type MovieStore interface {
+	// GetMovie 根据ID获取电影信息
+	GetMovie(ctx context.Context, movieID string) (map[string]map[string][]byte, error)
+	// GetMovieWithFamilies 根据ID和指定的列族获取电影信息
+	GetMovieWithFamilies(ctx context.Context, movieID string, families []string) (map[string]map[string][]byte, error)
+	// GetMoviesMultiple 根据多个ID获取电影信息
+	GetMoviesMultiple(ctx context.Context, movieIDs []string) (map[string]map[string]map[string][]byte, error)
+	// GetMoviesMultipleWithFamilies 根据多个ID和指定的列族获取电影信息，已不存在的电影不在结果中
+	GetMoviesMultipleWithFamilies(ctx context.Context, movieIDs []string, families []string) (map[string]map[string]map[string][]byte, error)
+	// GetMovieTags 获取电影标签
+	GetMovieTags(ctx context.Context, movieID string) (map[string]map[string][]byte, error)
+	// GetMovieRatings 获取电影的全部评分（含旧版评分行），按用户ID排序
+	GetMovieRatings(ctx context.Context, movieID string) ([]UserRating, error)
+	// GetMovieRatingStats 获取电影评分统计
+	GetMovieRatingStats(ctx context.Context, movieID string) (map[string]float64, error)
+	// GetUserRating 获取用户对电影的评分
+	GetUserRating(ctx context.Context, movieID string, userID string) (float64, int64, error)
+	// GetUserTaggedMovies 通过二级索引获取用户打过标签的电影ID，按 movieId 字典序
+	GetUserTaggedMovies(ctx context.Context, userID string) ([]string, error)
+
+	// ScanMovies 扫描 [startRow, endRow) 范围内的电影
+	ScanMovies(ctx context.Context, startRow, endRow string, limit int64) ([]Row, error)
+	// ScanMoviesBefore 按行键倒序返回行键小于 beforeRow 的电影，beforeRow 为空时从最后一行开始
+	ScanMoviesBefore(ctx context.Context, beforeRow string, limit int64) ([]Row, error)
+	// ScanMoviesWithFamilies 使用指定列族扫描电影
+	ScanMoviesWithFamilies(ctx context.Context, startRow, endRow string, families []string, limit int64) ([]Row, error)
+	// GetMoviesByGenre 通过二级索引获取指定类型的电影ID，类型不区分大小写，按 movieId 字典序
+	GetMoviesByGenre(ctx context.Context, genre string, limit int64) ([]string, error)
+	// GetMoviesByTag 通过二级索引获取带有指定标签的电影ID，标签不区分大小写，按 movieId 字典序
+	GetMoviesByTag(ctx context.Context, tag string, limit int64) ([]string, error)
+	// SearchMovies 通过二级索引按标题单词前缀或类型前缀搜索电影，返回按 movieId 字典序排列的电影ID，limit 为负数时不限制
+	SearchMovies(ctx context.Context, query string, limit int64) ([]string, error)
+	// GetMoviesByRatingRange 通过二级索引获取平均评分在指定范围内的电影ID，按平均分升序
+	GetMoviesByRatingRange(ctx context.Context, minRating, maxRating float64, limit int64) ([]string, error)
+	// GetMoviesByYearRange 通过二级索引获取年份在 [yearFrom, yearTo] 内的电影ID，按年份升序，无法识别年份的电影不在结果中
+	GetMoviesByYearRange(ctx context.Context, yearFrom, yearTo int, limit int64) ([]string, error)
+	// CountMovies 通过二级索引的反向行统计已建立索引的电影总数
+	CountMovies(ctx context.Context) (int, error)
+	// ForEachMovie 按行键顺序遍历全部电影，families 为空时返回所有列族，fn 返回错误时终止遍历
+	ForEachMovie(ctx context.Context, families []string, fn func(Row) error) error
+
+	// PutRating 仅当 rating:{userId} 列仍为 expected（读取到的原值，未评分时为空）时写入评分及评分时间（Unix秒），返回是否写入
+	PutRating(ctx context.Context, movieID, userID string, expected []byte, rating float64, timestamp int64) (bool, error)
+	// DeleteRating 仅当 rating:{userId} 列仍为 expected 时删除评分，返回是否删除
+	DeleteRating(ctx context.Context, movieID, userID string, expected []byte) (bool, error)
+	// DeleteRatingRow 删除用户对电影的旧版评分行 {movieId}_{userId}，不存在时不报错
+	DeleteRatingRow(ctx context.Context, movieID, userID string) error
+
+	// GetUserRatings 从用户评分表读取用户的全部评分，按 movieId 排序
+	GetUserRatings(ctx context.Context, userID string) ([]MovieRating, error)
+	// PutUserRating 在用户评分表中写入用户对电影的评分及评分时间（Unix秒）
+	PutUserRating(ctx context.Context, userID, movieID string, rating float64, timestamp int64) error
+	// DeleteUserRating 从用户评分表中删除用户对电影的评分
+	DeleteUserRating(ctx context.Context, userID, movieID string) error
+	// ForEachUserRatings 按行键（userId）顺序遍历用户评分表的全部行，fn 返回错误时终止遍历
+	ForEachUserRatings(ctx context.Context, fn func(Row) error) error
+
+	// GetRatingAggregate 读取电影评分聚合数据，尚未建立聚合时返回 nil
+	GetRatingAggregate(ctx context.Context, movieID string) (*RatingAggregate, error)
+	// UpdateRatingAggregate 原子更新评分聚合，oldRating 为0表示新增评分，newRating 为0表示删除评分
+	UpdateRatingAggregate(ctx context.Context, movieID string, oldRating, newRating float64) error
+	// PutRatingAggregate 覆盖写入评分聚合数据，用于重建
+	PutRatingAggregate(ctx context.Context, movieID string, aggregate *RatingAggregate) error
+
+	// GetRatingTimeline 读取电影按天统计的评分数量与总和，按日期升序
+	GetRatingTimeline(ctx context.Context, movieID string) ([]DayStat, error)
+	// UpdateRatingTimeline 原子更新按天统计，old 为变化前的评分（Rating 为0表示新增），updated 为变化后的评分（Rating 为0表示删除）
+	UpdateRatingTimeline(ctx context.Context, movieID string, old, updated UserRating) error
+	// PutRatingTimeline 根据全部原始评分覆盖写入按天统计，用于重建
+	PutRatingTimeline(ctx context.Context, movieID string, ratings []UserRating) error
+
+	// PutAlias 写入电影的本地化标题，locale 为语言代码，如 zh
+	PutAlias(ctx context.Context, movieID, locale, title string) error
+
+	// PutTag 仅当用户还没有给电影打标签时写入标签（CheckAndPut），返回是否写入
+	PutTag(ctx context.Context, movieID, userID, tag string) (bool, error)
+	// DeleteTag 删除用户对电影的标签
+	DeleteTag(ctx context.Context, movieID, userID string) error
+
+	// GetPendingTag 读取用户对电影的待审核标签，不存在时返回 nil
+	GetPendingTag(ctx context.Context, movieID, userID string) (*PendingTag, error)
+	// ListPendingTags 读取最早提交的 limit 个待审核标签，按提交时间升序
+	ListPendingTags(ctx context.Context, limit int64) ([]PendingTag, error)
+	// PutPendingTag 仅当用户对该电影没有待审核标签时加入待审核队列，返回是否加入
+	PutPendingTag(ctx context.Context, pending PendingTag) (bool, error)
+	// DeletePendingTag 删除用户对电影的待审核标签
+	DeletePendingTag(ctx context.Context, movieID, userID string) error
+
+	// UpdateMovieIndex 根据电影当前的标题、类型、标签和评分聚合同步其二级索引条目，电影不存在时删除全部条目
+	UpdateMovieIndex(ctx context.Context, movieID string) error
+}
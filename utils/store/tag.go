@@ -0,0 +1,87 @@
+package store
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TagFamily 用户标签所在的列族，列名为 tag:{userId}，每个用户对每部电影最多一个标签
+const TagFamily = "tag"
+
+// TagQueueTable 待审核标签表，用户新打的标签在此等待管理员审核
+//
+// 每个待审核标签对应两行，列相同（tag:tag 标签，tag:timestamp 提交时间（Unix秒））：
+//   - queue#{timestamp}#{movieId}#{userId}  队列条目，timestamp 补零到10位，按行键扫描即按提交时间升序
+//   - pending#{movieId}#{userId}           按电影和用户查找的条目，与 moviedata 中的 tag:{userId} 一一对应
+const TagQueueTable = "tagqueue"
+
+// TagQueueFamily 待审核标签表的列族
+const TagQueueFamily = "tag"
+
+// 待审核标签表的行键前缀与分隔符
+const (
+	tagQueuePrefix    = "queue#"
+	tagPendingPrefix  = "pending#"
+	tagQueueSeparator = "#"
+)
+
+// TagQueueStartRow / TagQueueStopRow 队列条目的行键范围（$ 紧随 # 之后）
+const (
+	TagQueueStartRow = tagQueuePrefix
+	TagQueueStopRow  = "queue$"
+)
+
+// PendingTag 一个待审核的标签
+type PendingTag struct {
+	MovieID   string
+	UserID    string
+	Tag       string
+	Timestamp int64
+}
+
+// TagColumn 返回用户标签在 tag 列族中的列名
+func TagColumn(userID string) string {
+	return "tag:" + userID
+}
+
+// TagQueueKey 返回待审核标签的队列条目行键
+func TagQueueKey(pending PendingTag) string {
+	return fmt.Sprintf("%s%010d%s%s%s%s", tagQueuePrefix, pending.Timestamp,
+		tagQueueSeparator, pending.MovieID, tagQueueSeparator, pending.UserID)
+}
+
+// TagPendingKey 返回按电影和用户查找待审核标签的行键
+func TagPendingKey(movieID, userID string) string {
+	return tagPendingPrefix + movieID + tagQueueSeparator + userID
+}
+
+// EncodePendingTag 返回待审核标签的列
+func EncodePendingTag(pending PendingTag) map[string][]byte {
+	return map[string][]byte{
+		"tag":       []byte(pending.Tag),
+		"timestamp": []byte(strconv.FormatInt(pending.Timestamp, 10)),
+	}
+}
+
+// ParsePendingTag 解析待审核标签表中的一行（队列条目或查找条目），行键或标签无效时返回 nil
+func ParsePendingTag(rowKey string, data map[string][]byte) *PendingTag {
+	ids, ok := strings.CutPrefix(rowKey, tagPendingPrefix)
+	if !ok {
+		var rest string
+		if rest, ok = strings.CutPrefix(rowKey, tagQueuePrefix); ok {
+			_, ids, ok = strings.Cut(rest, tagQueueSeparator)
+		}
+	}
+	if !ok {
+		return nil
+	}
+
+	movieID, userID, ok := strings.Cut(ids, tagQueueSeparator)
+	if !ok || len(data["tag"]) == 0 {
+		return nil
+	}
+
+	timestamp, _ := strconv.ParseInt(string(data["timestamp"]), 10, 64)
+	return &PendingTag{MovieID: movieID, UserID: userID, Tag: string(data["tag"]), Timestamp: timestamp}
+}
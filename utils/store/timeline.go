@@ -0,0 +1,107 @@
+package store
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TimelineFamily 按天统计评分数量与总和的列族，与 stats 分开存放，避免读取评分聚合时带出大量按天的列
+//
+// 列布局（日期为评分时间的UTC日期）：
+//   - timeline:count:{YYYYMMDD}  当天的评分数量（8字节大端整数，可被 Increment 原子更新）
+//   - timeline:sum:{YYYYMMDD}    当天的评分总和，以半星为单位（评分×2）
+//
+// 评分被修改时从原日期移到新的评分日期，被删除时从原日期扣除，因此统计的是每个用户当前评分的时间分布
+const TimelineFamily = "timeline"
+
+// timelineDayLayout 按天统计的日期格式
+const timelineDayLayout = "20060102"
+
+// DayStat 某一天的评分统计
+type DayStat struct {
+	Day   time.Time // 当天0点（UTC）
+	Count int64
+	Sum   float64
+}
+
+// TimelineDay 返回评分时间（Unix秒）对应的日期键
+func TimelineDay(timestamp int64) string {
+	return time.Unix(timestamp, 0).UTC().Format(timelineDayLayout)
+}
+
+// TimelineDeltas 计算评分变化对按天统计的增量，rating 为0的一方表示不存在（新增或删除），没有时间的评分不计入
+func TimelineDeltas(old, updated UserRating) map[string]int64 {
+	deltas := map[string]int64{}
+	if old.Rating > 0 && old.Timestamp > 0 {
+		day := TimelineDay(old.Timestamp)
+		deltas["count:"+day]--
+		deltas["sum:"+day] -= int64(math.Round(old.Rating * 2))
+	}
+	if updated.Rating > 0 && updated.Timestamp > 0 {
+		day := TimelineDay(updated.Timestamp)
+		deltas["count:"+day]++
+		deltas["sum:"+day] += int64(math.Round(updated.Rating * 2))
+	}
+	for qualifier, amount := range deltas {
+		if amount == 0 {
+			delete(deltas, qualifier)
+		}
+	}
+	return deltas
+}
+
+// ComputeTimeline 根据原始评分计算按天统计的列值，用于重建
+func ComputeTimeline(ratings []UserRating) map[string][]byte {
+	counters := map[string]int64{}
+	for _, rating := range ratings {
+		for qualifier, amount := range TimelineDeltas(UserRating{}, rating) {
+			counters[qualifier] += amount
+		}
+	}
+
+	columns := make(map[string][]byte, len(counters))
+	for qualifier, value := range counters {
+		columns[qualifier] = EncodeCounter(value)
+	}
+	return columns
+}
+
+// ParseTimeline 解析 timeline 列族，按日期升序返回评分数量大于0的日期
+func ParseTimeline(timelineData map[string][]byte) []DayStat {
+	days := make(map[string]*DayStat)
+	for qualifier, value := range timelineData {
+		kind, key, ok := strings.Cut(qualifier, ":")
+		if !ok {
+			continue
+		}
+		day, err := time.Parse(timelineDayLayout, key)
+		if err != nil {
+			continue
+		}
+
+		stat, ok := days[key]
+		if !ok {
+			stat = &DayStat{Day: day}
+			days[key] = stat
+		}
+		switch kind {
+		case "count":
+			stat.Count = DecodeCounter(value)
+		case "sum":
+			stat.Sum = float64(DecodeCounter(value)) / 2
+		}
+	}
+
+	stats := make([]DayStat, 0, len(days))
+	for _, stat := range days {
+		if stat.Count > 0 {
+			stats = append(stats, *stat)
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Day.Before(stats[j].Day)
+	})
+	return stats
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// UserRatingsTable 按用户组织的评分表，与 moviedata 中按电影组织的评分同步写入，用于按用户查询评分
+//
+// 行键为 userId，列布局与 moviedata 的 rating 列族对称（以 movieId 代替 userId）：
+//   - rating:rating:{movieId}     评分
+//   - rating:timestamp:{movieId}  评分时间（Unix秒）
+//
+// moviedata 仍是评分的权威数据，本表可通过 rebuild-user-ratings 命令从中重建
+const UserRatingsTable = "userratings"
+
+// UserRatingsFamily 用户评分表的列族
+const UserRatingsFamily = "rating"
+
+// MovieRating 用户对某部电影的评分
+type MovieRating struct {
+	MovieID   string
+	Rating    float64
+	Timestamp int64
+}
+
+// ParseUserRatings 解析用户评分表中的一行，按 movieId 排序
+func ParseUserRatings(ratingData map[string][]byte) []MovieRating {
+	parsed := ParseRatings(ratingData)
+	ratings := make([]MovieRating, 0, len(parsed))
+	for _, rating := range parsed {
+		ratings = append(ratings, MovieRating{MovieID: rating.UserID, Rating: rating.Rating, Timestamp: rating.Timestamp})
+	}
+	return ratings
+}
+
+// UserRatingMovieIDs 返回用户评分表一行中出现的全部 movieId（评分列或评分时间列），已排序
+func UserRatingMovieIDs(ratingData map[string][]byte) []string {
+	seen := make(map[string]bool)
+	for column := range ratingData {
+		if movieID, ok := strings.CutPrefix(column, "rating:"); ok {
+			seen[movieID] = true
+		} else if movieID, ok := strings.CutPrefix(column, "timestamp:"); ok {
+			seen[movieID] = true
+		}
+	}
+
+	movieIDs := make([]string, 0, len(seen))
+	for movieID := range seen {
+		movieIDs = append(movieIDs, movieID)
+	}
+	sort.Strings(movieIDs)
+	return movieIDs
+}
+
+// UserRatingColumns 返回用户评分表中一条评分的列，rating 为0时返回值为 nil 的列，用于删除
+func UserRatingColumns(movieID string, rating float64, timestamp int64) map[string][]byte {
+	if rating == 0 {
+		return map[string][]byte{"rating:" + movieID: nil, "timestamp:" + movieID: nil}
+	}
+	return map[string][]byte{
+		"rating:" + movieID:    EncodeRatingValue(rating),
+		"timestamp:" + movieID: []byte(strconv.FormatInt(timestamp, 10)),
+	}
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"gohbase/utils/tags"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TagPolicy 对外暴露的标签规范化与屏蔽规则，未初始化时使用默认长度且不屏蔽任何词
+var TagPolicy = tags.NewPolicy(tags.DefaultMaxLength, nil)
+
+// InitTagPolicy 初始化标签规则，blocklistFile 不为空时从中读取屏蔽词（每行一个，# 开头的行为注释）并与 blocklist 合并
+func InitTagPolicy(maxLength int, blocklist []string, blocklistFile string) error {
+	if blocklistFile != "" {
+		words, err := readBlocklist(blocklistFile)
+		if err != nil {
+			return err
+		}
+		blocklist = append(blocklist, words...)
+	}
+
+	TagPolicy = tags.NewPolicy(maxLength, blocklist)
+	logrus.Infof("标签规则初始化成功 [最大长度: %d, 屏蔽词: %d]", TagPolicy.MaxLength, TagPolicy.BlocklistSize())
+	return nil
+}
+
+// readBlocklist 读取屏蔽词文件
+func readBlocklist(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开屏蔽词文件失败: %w", err)
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取屏蔽词文件失败: %w", err)
+	}
+	return words, nil
+}
@@ -0,0 +1,127 @@
+package tags
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultMaxLength 标签的默认最大长度（字符数）
+const DefaultMaxLength = 50
+
+// 标签校验错误
+var (
+	ErrEmpty   = errors.New("标签不能为空")
+	ErrTooLong = errors.New("标签过长")
+	ErrBlocked = errors.New("标签包含被屏蔽的词")
+)
+
+// Policy 标签的规范化与屏蔽规则，创建后只读，可并发使用
+type Policy struct {
+	MaxLength  int
+	blocklist  map[string]bool // 规范化后的屏蔽词
+	phrases    map[string]bool // 屏蔽词按非字母数字拆分后以空格连接的单词序列，如 "f-word" 为 "f word"
+	substrings []string        // 含中日文等不以空格分词文字的屏蔽词，按子串匹配
+}
+
+// unsegmentedScripts 书写时不用空格分词的文字，这类屏蔽词无法按单词匹配
+var unsegmentedScripts = []*unicode.RangeTable{
+	unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Thai, unicode.Lao, unicode.Khmer, unicode.Myanmar,
+}
+
+// NewPolicy 创建标签规则，maxLength 不大于0时使用 DefaultMaxLength，屏蔽词按标签同样的规则规范化，空词被忽略
+func NewPolicy(maxLength int, blocklist []string) *Policy {
+	if maxLength <= 0 {
+		maxLength = DefaultMaxLength
+	}
+
+	policy := &Policy{
+		MaxLength: maxLength,
+		blocklist: make(map[string]bool, len(blocklist)),
+		phrases:   make(map[string]bool, len(blocklist)),
+	}
+	for _, word := range blocklist {
+		if word = Normalize(word); word == "" || policy.blocklist[word] {
+			continue
+		}
+		policy.blocklist[word] = true
+		if phrase := strings.Join(splitWords(word), " "); phrase != "" {
+			policy.phrases[phrase] = true
+		}
+		if strings.IndexFunc(word, isUnsegmented) >= 0 {
+			policy.substrings = append(policy.substrings, word)
+		}
+	}
+	return policy
+}
+
+// Normalize 规范化标签：去掉控制字符，连续空白合并为一个空格并去掉首尾空白，转为小写
+func Normalize(tag string) string {
+	tag = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && !unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, tag)
+	return strings.ToLower(strings.Join(strings.Fields(tag), " "))
+}
+
+// Check 规范化并校验用户提交的标签：不能为空、不超过 MaxLength 个字符，且不含屏蔽词
+func (p *Policy) Check(tag string) (string, error) {
+	tag = Normalize(tag)
+	if tag == "" {
+		return "", ErrEmpty
+	}
+	if utf8.RuneCountInString(tag) > p.MaxLength {
+		return "", fmt.Errorf("%w，最多 %d 个字符", ErrTooLong, p.MaxLength)
+	}
+	if p.Blocked(tag) {
+		return "", ErrBlocked
+	}
+	return tag, nil
+}
+
+// Blocked 判断规范化后的标签是否被屏蔽：整个标签或其中任一单词（按非字母数字拆分）为屏蔽词
+// 屏蔽词按同样的规则拆分为单词，含多个单词（如 "two words"、"f-word"）时按标签中连续的单词匹配；含中日文等文字的屏蔽词出现在标签任意位置即屏蔽
+func (p *Policy) Blocked(tag string) bool {
+	if len(p.blocklist) == 0 {
+		return false
+	}
+	if p.blocklist[tag] {
+		return true
+	}
+	for _, word := range p.substrings {
+		if strings.Contains(tag, word) {
+			return true
+		}
+	}
+
+	words := splitWords(tag)
+	for i := range words {
+		for j := i + 1; j <= len(words); j++ {
+			if p.phrases[strings.Join(words[i:j], " ")] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitWords 按非字母数字拆分单词
+func splitWords(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// isUnsegmented 判断字符是否属于不用空格分词的文字
+func isUnsegmented(r rune) bool {
+	return unicode.IsOneOf(unsegmentedScripts, r)
+}
+
+// BlocklistSize 返回屏蔽词数量
+func (p *Policy) BlocklistSize() int {
+	return len(p.blocklist)
+}
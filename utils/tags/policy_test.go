@@ -0,0 +1,105 @@
+package tags
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		tag, want string
+	}{
+		{"  Pixar  ", "pixar"},
+		{"Time\tTravel\n", "time travel"},
+		{"a\x00b\x7f", "ab"},
+		{"Christopher   NOLAN", "christopher nolan"},
+		{"　经典　", "经典"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.tag); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	policy := NewPolicy(10, []string{"spoiler"})
+	tests := []struct {
+		tag  string
+		want string
+		err  error
+	}{
+		{" Pixar ", "pixar", nil},
+		{"   ", "", ErrEmpty},
+		{"abcdefghij", "abcdefghij", nil},
+		{"abcdefghijk", "", ErrTooLong},
+		{"十个汉字十个汉字十个", "十个汉字十个汉字十个", nil}, // 按字符而非字节计算长度
+		{"SPOILER", "", ErrBlocked},
+	}
+	for _, tt := range tests {
+		got, err := policy.Check(tt.tag)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("Check(%q) = %q, %v, want %q, %v", tt.tag, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestBlocked(t *testing.T) {
+	policy := NewPolicy(0, []string{"Bad", "f-word", "two  words", "坏蛋", "ネタバレ", "!!!", " "})
+	tests := []struct {
+		tag  string
+		want bool
+	}{
+		{"bad", true},
+		{"very bad movie", true},
+		{"bad-ass", true},
+		{"baddie", false},
+		{"f-word", true},
+		{"my f-word tag", true},
+		{"f word", true},
+		{"fword", false},
+		{"two words", true},
+		{"just two words here", true},
+		{"two-words", true},
+		{"two", false},
+		{"words two", false},
+		{"大坏蛋", true},
+		{"ネタバレあり", true},
+		{"!!!", true},
+		{"wow!!!", false},
+		{"pixar", false},
+	}
+	for _, tt := range tests {
+		if got := policy.Blocked(Normalize(tt.tag)); got != tt.want {
+			t.Errorf("Blocked(%q) = %v, want %v", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestNewPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxLength int
+		blocklist []string
+		wantMax   int
+		wantSize  int
+	}{
+		{"默认长度", 0, nil, DefaultMaxLength, 0},
+		{"负数长度", -1, nil, DefaultMaxLength, 0},
+		{"屏蔽词规范化后去重并忽略空词", 20, []string{"Bad", " bad ", "", "  ", "worse"}, 20, 2},
+	}
+	for _, tt := range tests {
+		policy := NewPolicy(tt.maxLength, tt.blocklist)
+		if policy.MaxLength != tt.wantMax || policy.BlocklistSize() != tt.wantSize {
+			t.Errorf("%s: MaxLength = %d, BlocklistSize = %d, want %d, %d",
+				tt.name, policy.MaxLength, policy.BlocklistSize(), tt.wantMax, tt.wantSize)
+		}
+	}
+
+	// 没有屏蔽词时不屏蔽任何标签
+	if NewPolicy(0, nil).Blocked(strings.Repeat("bad ", 3)) {
+		t.Error("empty blocklist should not block")
+	}
+}
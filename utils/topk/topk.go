@@ -0,0 +1,55 @@
+package topk
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// Top 只保留排名最靠前的 k 项，内部为按排名倒序的堆，堆顶为已保留中排名最靠后的一项
+type Top[T any] struct {
+	items  []T
+	k      int
+	before func(a, b T) bool
+}
+
+// New 创建 Top，before(a, b) 判断 a 是否排在 b 之前，需为严格全序以保证结果稳定
+func New[T any](k int, before func(a, b T) bool) *Top[T] {
+	return &Top[T]{items: make([]T, 0, max(k, 0)), k: k, before: before}
+}
+
+// Push 加入一项，已满 k 项时只有排在堆顶之前的项才会替换堆顶
+func (t *Top[T]) Push(item T) {
+	switch {
+	case len(t.items) < t.k:
+		heap.Push((*reversed[T])(t), item)
+	case t.k > 0 && t.before(item, t.items[0]):
+		t.items[0] = item
+		heap.Fix((*reversed[T])(t), 0)
+	}
+}
+
+// Len 返回已保留的项数
+func (t *Top[T]) Len() int {
+	return len(t.items)
+}
+
+// Sorted 按排名顺序返回已保留的项，调用后不应再 Push
+func (t *Top[T]) Sorted() []T {
+	sort.Slice(t.items, func(i, j int) bool {
+		return t.before(t.items[i], t.items[j])
+	})
+	return t.items
+}
+
+// reversed 以 container/heap 接口操作 Top 的堆，Less 取反使堆顶为排名最靠后的一项
+type reversed[T any] Top[T]
+
+func (h *reversed[T]) Len() int           { return len(h.items) }
+func (h *reversed[T]) Less(i, j int) bool { return h.before(h.items[j], h.items[i]) }
+func (h *reversed[T]) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *reversed[T]) Push(x any)         { h.items = append(h.items, x.(T)) }
+func (h *reversed[T]) Pop() any {
+	item := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return item
+}